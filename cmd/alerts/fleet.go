@@ -0,0 +1,311 @@
+package alerts
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	sdk "github.com/openshift-online/ocm-sdk-go"
+	"github.com/openshift/osdctl/cmd/alerts/utils"
+	"github.com/openshift/osdctl/cmd/common"
+	orgutils "github.com/openshift/osdctl/cmd/org"
+	osdctlio "github.com/openshift/osdctl/internal/io"
+	"github.com/openshift/osdctl/pkg/printer"
+	ocmutils "github.com/openshift/osdctl/pkg/utils"
+)
+
+const (
+	outputText = "text"
+	outputJSON = "json"
+
+	// firingState is the Alertmanager state of alerts that are neither silenced nor inhibited.
+	firingState = "active"
+)
+
+// ClusterAlert is an alert firing on a single cluster.
+type ClusterAlert struct {
+	ClusterID string    `json:"clusterId"`
+	State     string    `json:"state"`
+	Summary   string    `json:"summary"`
+	StartsAt  time.Time `json:"startsAt"`
+}
+
+// AlertGroup aggregates the clusters firing the same alert with the same severity.
+type AlertGroup struct {
+	Alertname string         `json:"alertname"`
+	Severity  string         `json:"severity"`
+	Clusters  []ClusterAlert `json:"clusters"`
+}
+
+// FleetAlerts is the result of listing alerts across several clusters.
+type FleetAlerts struct {
+	ClusterCount int          `json:"clusterCount"`
+	Alerts       []AlertGroup `json:"alerts"`
+	// Errors maps the clusters whose alerts could not be listed to the failure.
+	Errors map[string]string `json:"errors,omitempty"`
+}
+
+// clusterAlerts is the outcome of listing the alerts of a single cluster.
+type clusterAlerts struct {
+	clusterID string
+	alerts    []utils.Alert
+	err       error
+}
+
+// isFleet reports whether the alerts of several clusters are requested.
+func (cmd *alertCmd) isFleet() bool {
+	return cmd.organization != "" || cmd.clustersFile != "" || len(cmd.query) > 0
+}
+
+// ListFleetAlerts lists the firing alerts of the clusters of an organization, of a clusters file
+// or matching an OCM search query, and prints them aggregated by alertname and severity.
+func ListFleetAlerts(cmd *alertCmd) error {
+	if cmd.output != outputText && cmd.output != outputJSON {
+		return fmt.Errorf("invalid output format: %s (allowed: text, json)", cmd.output)
+	}
+	if cmd.workers < 1 {
+		return fmt.Errorf("--workers must be at least 1")
+	}
+
+	connection, err := ocmutils.CreateConnection()
+	if err != nil {
+		return err
+	}
+	defer connection.Close()
+
+	clusterIDs, err := cmd.fleetClusterIDs(connection)
+	if err != nil {
+		return err
+	}
+	if len(clusterIDs) == 0 {
+		return fmt.Errorf("no clusters found")
+	}
+	log.Printf("Listing alerts of %d clusters", len(clusterIDs))
+
+	elevationReasons := []string{
+		cmd.reason,
+		"Listing active cluster alerts across clusters",
+	}
+	results := collectAlerts(clusterIDs, cmd.workers, func(clusterID string) ([]utils.Alert, error) {
+		_, kubeconfig, clientset, err := common.GetKubeConfigAndClientWithConn(clusterID, connection, elevationReasons...)
+		if err != nil {
+			return nil, err
+		}
+		client := utils.NewAlertmanagerClient(kubeconfig, clientset)
+		defer client.Close()
+		return client.ListAlerts()
+	})
+
+	fleet := aggregateAlerts(results, cmd.alertLevel)
+	if len(cmd.alertnames) > 0 {
+		fleet.Alerts = filterAlertGroups(fleet.Alerts, cmd.alertnames)
+	}
+
+	if cmd.output == outputJSON {
+		return printFleetAlertsJSON(os.Stdout, fleet)
+	}
+	return printFleetAlerts(os.Stdout, fleet, len(cmd.alertnames) > 0)
+}
+
+// fleetClusterIDs returns the internal IDs of the clusters selected by --org, --clusters-file or --query.
+func (cmd *alertCmd) fleetClusterIDs(connection *sdk.Connection) ([]string, error) {
+	if cmd.organization != "" {
+		subscriptions, err := orgutils.SearchSubscriptions(cmd.organization, orgutils.StatusActive)
+		if err != nil {
+			return nil, err
+		}
+		var clusterIDs []string
+		for _, subscription := range subscriptions {
+			if clusterID := subscription.ClusterID(); clusterID != "" {
+				clusterIDs = append(clusterIDs, clusterID)
+			}
+		}
+		return clusterIDs, nil
+	}
+
+	var filters []string
+	if cmd.clustersFile != "" {
+		identifiers, err := osdctlio.ParseAndValidateClustersFile(cmd.clustersFile)
+		if err != nil {
+			return nil, fmt.Errorf("cannot parse clusters file %s: %w", cmd.clustersFile, err)
+		}
+		if len(identifiers) == 0 {
+			return nil, nil
+		}
+		queries := make([]string, 0, len(identifiers))
+		for _, identifier := range identifiers {
+			queries = append(queries, ocmutils.GenerateQuery(identifier))
+		}
+		filters = append(filters, strings.Join(queries, " or "))
+	}
+	filters = append(filters, cmd.query...)
+
+	clusters, err := ocmutils.ApplyFilters(connection, filters)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search for clusters with provided filters (%v): %w", filters, err)
+	}
+	clusterIDs := make([]string, 0, len(clusters))
+	for _, cluster := range clusters {
+		clusterIDs = append(clusterIDs, cluster.ID())
+	}
+	return clusterIDs, nil
+}
+
+// collectAlerts lists the alerts of the clusters with up to workers concurrent calls to list.
+func collectAlerts(clusterIDs []string, workers int, list func(clusterID string) ([]utils.Alert, error)) []clusterAlerts {
+	results := make([]clusterAlerts, len(clusterIDs))
+	indexes := make(chan int)
+
+	var wg sync.WaitGroup
+	for range min(workers, len(clusterIDs)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				alerts, err := list(clusterIDs[i])
+				results[i] = clusterAlerts{clusterID: clusterIDs[i], alerts: alerts, err: err}
+			}
+		}()
+	}
+	for i := range clusterIDs {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	return results
+}
+
+// aggregateAlerts groups the firing alerts of the clusters by alertname and severity. Groups
+// are sorted by number of clusters, most widespread first. Only alerts of the given severity
+// are kept, unless level is empty or "all".
+func aggregateAlerts(results []clusterAlerts, level string) FleetAlerts {
+	fleet := FleetAlerts{ClusterCount: len(results)}
+	groups := map[string]*AlertGroup{}
+
+	for _, result := range results {
+		if result.err != nil {
+			if fleet.Errors == nil {
+				fleet.Errors = map[string]string{}
+			}
+			fleet.Errors[result.clusterID] = result.err.Error()
+			continue
+		}
+
+		for _, alert := range result.alerts {
+			if alert.Status.State != firingState {
+				continue
+			}
+			if level != "" && level != "all" && alert.Severity() != level {
+				continue
+			}
+
+			key := alert.Name() + "\x00" + alert.Severity()
+			group, ok := groups[key]
+			if !ok {
+				group = &AlertGroup{Alertname: alert.Name(), Severity: alert.Severity()}
+				groups[key] = group
+			}
+			// An alert can fire several times on a cluster, i.e. once per namespace.
+			if n := len(group.Clusters); n > 0 && group.Clusters[n-1].ClusterID == result.clusterID {
+				continue
+			}
+			group.Clusters = append(group.Clusters, ClusterAlert{
+				ClusterID: result.clusterID,
+				State:     alert.Status.State,
+				Summary:   alert.Summary(),
+				StartsAt:  alert.StartsAt,
+			})
+		}
+	}
+
+	fleet.Alerts = make([]AlertGroup, 0, len(groups))
+	for _, group := range groups {
+		sort.SliceStable(group.Clusters, func(i, j int) bool {
+			return group.Clusters[i].ClusterID < group.Clusters[j].ClusterID
+		})
+		fleet.Alerts = append(fleet.Alerts, *group)
+	}
+	sort.Slice(fleet.Alerts, func(i, j int) bool {
+		a, b := fleet.Alerts[i], fleet.Alerts[j]
+		if len(a.Clusters) != len(b.Clusters) {
+			return len(a.Clusters) > len(b.Clusters)
+		}
+		if a.Alertname != b.Alertname {
+			return a.Alertname < b.Alertname
+		}
+		return a.Severity < b.Severity
+	})
+
+	return fleet
+}
+
+// filterAlertGroups keeps the groups of the given alertnames.
+func filterAlertGroups(groups []AlertGroup, alertnames []string) []AlertGroup {
+	var filtered []AlertGroup
+	for _, group := range groups {
+		for _, alertname := range alertnames {
+			if group.Alertname == alertname {
+				filtered = append(filtered, group)
+				break
+			}
+		}
+	}
+	return filtered
+}
+
+func printFleetAlertsJSON(out io.Writer, fleet FleetAlerts) error {
+	data, err := json.MarshalIndent(fleet, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal the alerts: %w", err)
+	}
+	_, err = fmt.Fprintln(out, string(data))
+	return err
+}
+
+// printFleetAlerts prints a table of the alert groups, followed by the clusters of each group
+// when drillDown is set, and the clusters whose alerts could not be listed.
+func printFleetAlerts(out io.Writer, fleet FleetAlerts, drillDown bool) error {
+	table := printer.NewTablePrinter(out, 20, 1, 3, ' ')
+	table.AddRow([]string{"ALERTNAME", "SEVERITY", "CLUSTERS"})
+	for _, group := range fleet.Alerts {
+		table.AddRow([]string{group.Alertname, group.Severity, fmt.Sprintf("%d/%d", len(group.Clusters), fleet.ClusterCount)})
+	}
+	if err := table.Flush(); err != nil {
+		return err
+	}
+
+	if drillDown {
+		for _, group := range fleet.Alerts {
+			fmt.Fprintf(out, "\n%s (%s):\n", group.Alertname, group.Severity)
+			table := printer.NewTablePrinter(out, 20, 1, 3, ' ')
+			table.AddRow([]string{"CLUSTER", "SINCE", "SUMMARY"})
+			for _, cluster := range group.Clusters {
+				table.AddRow([]string{cluster.ClusterID, cluster.StartsAt.Format(time.RFC3339), cluster.Summary})
+			}
+			if err := table.Flush(); err != nil {
+				return err
+			}
+		}
+	}
+
+	if len(fleet.Errors) > 0 {
+		clusterIDs := make([]string, 0, len(fleet.Errors))
+		for clusterID := range fleet.Errors {
+			clusterIDs = append(clusterIDs, clusterID)
+		}
+		sort.Strings(clusterIDs)
+
+		fmt.Fprintf(out, "\nFailed to list the alerts of %d clusters:\n", len(clusterIDs))
+		for _, clusterID := range clusterIDs {
+			fmt.Fprintf(out, "  %s: %s\n", clusterID, fleet.Errors[clusterID])
+		}
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+package alerts
+
+import (
+	"bytes"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/openshift/osdctl/cmd/alerts/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newAlert(name, severity, state string) utils.Alert {
+	return utils.Alert{
+		Labels:      map[string]string{"alertname": name, "severity": severity},
+		Annotations: map[string]string{"summary": name + " is firing"},
+		Status:      utils.AlertStatus{State: state},
+		StartsAt:    time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC),
+	}
+}
+
+func TestAggregateAlerts(t *testing.T) {
+	results := []clusterAlerts{
+		{clusterID: "b", alerts: []utils.Alert{
+			newAlert("KubePodCrashLooping", "warning", "active"),
+			newAlert("KubePodCrashLooping", "warning", "active"),
+			newAlert("etcdMembersDown", "critical", "active"),
+		}},
+		{clusterID: "a", alerts: []utils.Alert{
+			newAlert("KubePodCrashLooping", "warning", "active"),
+			newAlert("etcdMembersDown", "critical", "suppressed"),
+		}},
+		{clusterID: "c", err: errors.New("cluster unreachable")},
+	}
+
+	fleet := aggregateAlerts(results, "all")
+	assert.Equal(t, 3, fleet.ClusterCount)
+	assert.Equal(t, map[string]string{"c": "cluster unreachable"}, fleet.Errors)
+	require.Len(t, fleet.Alerts, 2)
+	assert.Equal(t, "KubePodCrashLooping", fleet.Alerts[0].Alertname)
+	require.Len(t, fleet.Alerts[0].Clusters, 2)
+	assert.Equal(t, "a", fleet.Alerts[0].Clusters[0].ClusterID)
+	assert.Equal(t, "b", fleet.Alerts[0].Clusters[1].ClusterID)
+	assert.Equal(t, "KubePodCrashLooping is firing", fleet.Alerts[0].Clusters[0].Summary)
+	assert.Equal(t, "etcdMembersDown", fleet.Alerts[1].Alertname)
+	assert.Len(t, fleet.Alerts[1].Clusters, 1)
+
+	critical := aggregateAlerts(results, "critical")
+	require.Len(t, critical.Alerts, 1)
+	assert.Equal(t, "etcdMembersDown", critical.Alerts[0].Alertname)
+}
+
+func TestCollectAlerts(t *testing.T) {
+	var calls atomic.Int32
+	results := collectAlerts([]string{"a", "b", "c"}, 2, func(clusterID string) ([]utils.Alert, error) {
+		calls.Add(1)
+		if clusterID == "b" {
+			return nil, errors.New("failed")
+		}
+		return []utils.Alert{newAlert("Foo", "info", "active")}, nil
+	})
+
+	assert.Equal(t, int32(3), calls.Load())
+	require.Len(t, results, 3)
+	assert.Equal(t, "a", results[0].clusterID)
+	assert.Len(t, results[0].alerts, 1)
+	assert.Error(t, results[1].err)
+	assert.Equal(t, "c", results[2].clusterID)
+}
+
+func TestPrintFleetAlerts(t *testing.T) {
+	fleet := aggregateAlerts([]clusterAlerts{
+		{clusterID: "a", alerts: []utils.Alert{newAlert("Foo", "warning", "active")}},
+		{clusterID: "b", err: errors.New("cluster unreachable")},
+	}, "")
+
+	var out bytes.Buffer
+	require.NoError(t, printFleetAlerts(&out, fleet, true))
+	assert.Contains(t, out.String(), "Foo")
+	assert.Contains(t, out.String(), "1/2")
+	assert.Contains(t, out.String(), "Foo is firing")
+	assert.Contains(t, out.String(), "b: cluster unreachable")
+
+	assert.Len(t, filterAlertGroups(fleet.Alerts, []string{"Bar"}), 0)
+	assert.Len(t, filterAlertGroups(fleet.Alerts, []string{"Foo"}), 1)
+}
@@ -1,7 +1,6 @@
 package alerts
 
 import (
-	"encoding/json"
 	"fmt"
 	"log"
 
@@ -15,23 +14,58 @@ type alertCmd struct {
 	clusterID  string
 	alertLevel string
 	reason     string
+
+	// Fleet mode, listing the alerts of several clusters.
+	organization string
+	clustersFile string
+	query        []string
+	alertnames   []string
+	output       string
+	workers      int
 }
 
 // NewCmdListAlerts implements the list alert functionality.
 func NewCmdListAlerts() *cobra.Command {
 	alertCmd := &alertCmd{}
 	newCmd := &cobra.Command{
-		Use:   "list --cluster-id <cluster-id> --level [warning, critical, firing, pending, all]",
+		Use:   "list [--cluster-id <cluster-id> | --org <org-id> | --clusters-file <file> | --query <search>] --level [warning, critical, firing, pending, all]",
 		Short: "List all alerts or based on severity",
-		Long:  `Checks the alerts for the cluster and print the list based on severity`,
+		Long: `Checks the alerts for the cluster and print the list based on severity.
+
+With --org, --clusters-file or --query, the firing alerts of all the selected clusters are
+collected concurrently and aggregated by alertname and severity, showing how many clusters
+fire each alert. Use --alertname to drill down into the clusters firing specific alerts.`,
+		Example: `  # List the alerts of a cluster
+  osdctl alert list --cluster-id ${CLUSTER_ID} --reason OHSS-1234
 
+  # Aggregate the critical alerts firing across the clusters of an organization
+  osdctl alert list --org ${ORG_ID} --level critical --reason OHSS-1234
+
+  # Show the clusters firing an alert, as JSON
+  osdctl alert list --clusters-file clusters.json --alertname KubeAPIErrorBudgetBurn -o json --reason OHSS-1234`,
+		Args:              cobra.NoArgs,
 		DisableAutoGenTag: true,
 		Run: func(cmd *cobra.Command, args []string) {
+			if alertCmd.isFleet() {
+				if err := ListFleetAlerts(alertCmd); err != nil {
+					log.Fatal(err)
+				}
+				return
+			}
 			ListAlerts(alertCmd)
 		},
 	}
 	newCmd.Flags().StringVarP(&alertCmd.clusterID, "cluster-id", "C", "", "Provide the internal ID of the cluster")
-	_ = newCmd.MarkFlagRequired("cluster-id")
+	newCmd.Flags().StringVar(&alertCmd.organization, "org", "", "List the alerts of all the active clusters of the organization")
+	newCmd.Flags().StringVarP(&alertCmd.clustersFile, "clusters-file", "c", "", `List the alerts of the clusters in the file, the format of the file is: {"clusters":["$CLUSTERID"]}`)
+	newCmd.Flags().StringArrayVarP(&alertCmd.query, "query", "q", []string{}, "List the alerts of the clusters matching an OCM search query (eg. -q \"version.id like 'openshift-v4.16%'\")")
+	newCmd.Flags().StringSliceVar(&alertCmd.alertnames, "alertname", []string{}, "Only show these alerts, listing the clusters firing them (comma-separated, fleet mode only)")
+	newCmd.Flags().StringVarP(&alertCmd.output, "output", "o", outputText, "Output format of the fleet mode. One of: text, json")
+	newCmd.Flags().IntVar(&alertCmd.workers, "workers", 10, "Number of clusters to list the alerts of concurrently (fleet mode only)")
+	newCmd.MarkFlagsOneRequired("cluster-id", "org", "clusters-file", "query")
+	newCmd.MarkFlagsMutuallyExclusive("cluster-id", "org", "clusters-file")
+	newCmd.MarkFlagsMutuallyExclusive("cluster-id", "query")
+	newCmd.MarkFlagsMutuallyExclusive("org", "query")
 
 	newCmd.Flags().StringVarP(&alertCmd.alertLevel, "level", "l", "all", "Alert level [warning, critical, firing, pending, all]")
 	newCmd.Flags().StringVar(&alertCmd.reason, "reason", "", "The reason for this command, which requires elevation, to be run (usualy an OHSS or PD ticket)")
@@ -63,10 +97,6 @@ func ListAlerts(cmd *alertCmd) {
 }
 
 func getAlertLevel(clusterID, alertLevel string, elevationReason string) {
-	var alerts []utils.Alert
-
-	listAlertCmd := []string{"amtool", "--alertmanager.url", utils.LocalHostUrl, "alert", "-o", "json"}
-
 	elevationReasons := []string{
 		elevationReason,
 		"Listing active cluster alerts",
@@ -77,31 +107,20 @@ func getAlertLevel(clusterID, alertLevel string, elevationReason string) {
 		log.Fatal(err)
 	}
 
-	output, err := utils.ExecInAlertManagerPod(kubeconfig, clientset, listAlertCmd)
-
-	if err != nil {
-		fmt.Println("Execution with alertmanager-main-0 failed.", err)
-	}
-
-	if err != nil {
-		fmt.Println("Execution with alertmanager-main-1 failed.", err)
-		return
-	}
-
-	outputSlice := []byte(output)
+	client := utils.NewAlertmanagerClient(kubeconfig, clientset)
+	defer client.Close()
 
-	err = json.Unmarshal(outputSlice, &alerts)
+	alerts, err := client.ListAlerts()
 	if err != nil {
-		fmt.Println("Error in unmarshaling the labels", err)
+		fmt.Println("Error listing the alerts:", err)
 		return
 	}
 
 	foundAlert := false
 	fmt.Printf("Alert Information:\n")
 	for _, alert := range alerts {
-		if alertLevel == "" || alertLevel == alert.Labels.Severity || alertLevel == "all" {
-			labels, status, annotations := alert.Labels, alert.Status, alert.Annotations
-			printAlert(labels, annotations, status)
+		if alertLevel == "" || alertLevel == alert.Severity() || alertLevel == "all" {
+			printAlert(alert)
 			foundAlert = true
 		}
 	}
@@ -112,10 +131,10 @@ func getAlertLevel(clusterID, alertLevel string, elevationReason string) {
 
 }
 
-func printAlert(labels utils.AlertLabels, annotations utils.AlertAnnotations, status utils.AlertStatus) {
-	fmt.Printf("  AlertName:  %s\n", labels.Alertname)
-	fmt.Printf("  Severity:   %s\n", labels.Severity)
-	fmt.Printf("  State:      %s\n", status.State)
-	fmt.Printf("  Message:    %s\n", annotations.Summary)
+func printAlert(alert utils.Alert) {
+	fmt.Printf("  AlertName:  %s\n", alert.Name())
+	fmt.Printf("  Severity:   %s\n", alert.Severity())
+	fmt.Printf("  State:      %s\n", alert.Status.State)
+	fmt.Printf("  Message:    %s\n", alert.Summary())
 	fmt.Println()
 }
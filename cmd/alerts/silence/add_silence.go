@@ -1,37 +1,61 @@
 package silence
 
 import (
-	"encoding/json"
 	"fmt"
 	"log"
 	"strings"
+	"time"
 
+	sdk "github.com/openshift-online/ocm-sdk-go"
 	"github.com/openshift/osdctl/cmd/alerts/utils"
 	"github.com/openshift/osdctl/cmd/common"
 	ocmutils "github.com/openshift/osdctl/pkg/utils"
 	"github.com/spf13/cobra"
-	"k8s.io/client-go/kubernetes"
-	"k8s.io/client-go/rest"
 )
 
 type addSilenceCmd struct {
-	clusterID string
-	alertID   []string
-	duration  string
-	comment   string
-	all       bool
-	reason    string
+	clusterID   string
+	alertID     []string
+	matchers    []string
+	template    string
+	duration    string
+	comment     string
+	all         bool
+	reason      string
+	durationSet bool
+	commentSet  bool
 }
 
 func NewCmdAddSilence() *cobra.Command {
 	addSilenceCmd := &addSilenceCmd{}
 	cmd := &cobra.Command{
-		Use:               "add --cluster-id <cluster-identifier> [--all --duration --comment | --alertname --duration --comment]",
-		Short:             "Add new silence for alert",
-		Long:              `add new silence for specfic or all alert with comment and duration of alert`,
+		Use:   "add --cluster-id <cluster-identifier> [--all --duration --comment | --alertname --duration --comment | --matcher --template --duration --comment]",
+		Short: "Add new silence for alert",
+		Long: `add new silence for specfic or all alert with comment and duration of alert
+
+Silences can also match arbitrary labels with --matcher, using the amtool syntax:
+name=value, name!=value, name=~regex or name!~regex. All the matchers of a silence
+must match for an alert to be silenced.
+
+Named silence templates can be defined in the osdctl config under ` + SilenceTemplatesKey + `
+and used with --template. A template expands to its matchers, duration and comment,
+the duration and comment can be overridden on the command line:
+
+  ` + SilenceTemplatesKey + `:
+    upgrade-window:
+      matchers: ['alertname=~"ClusterOperator(Down|Degraded)"', 'severity!=critical']
+      duration: 4h
+      comment: Silencing operator alerts during the upgrade window`,
+		Example: `  # Silence the warning alerts of a namespace for 2 hours
+  osdctl alert silence add --cluster-id ${CLUSTER_ID} --matcher namespace=openshift-logging --matcher severity=warning --duration 2h --reason OHSS-1234
+
+  # Silence the alerts of the upgrade-window template
+  osdctl alert silence add --cluster-id ${CLUSTER_ID} --template upgrade-window --reason OHSS-1234`,
 		Args:              cobra.NoArgs,
 		DisableAutoGenTag: true,
 		Run: func(cmd *cobra.Command, args []string) {
+			addSilenceCmd.durationSet = cmd.Flags().Changed("duration")
+			addSilenceCmd.commentSet = cmd.Flags().Changed("comment")
 			AddSilence(addSilenceCmd)
 
 		},
@@ -42,10 +66,14 @@ func NewCmdAddSilence() *cobra.Command {
 	cmd.Flags().StringVarP(&addSilenceCmd.comment, "comment", "c", "Adding silence using the osdctl alert command", "add comment about silence")
 	cmd.Flags().StringVarP(&addSilenceCmd.duration, "duration", "d", "15d", "Adding duration for silence as 15 days") //default duration set to 15 days
 	cmd.Flags().BoolVarP(&addSilenceCmd.all, "all", "a", false, "Adding silences for all alert")
+	cmd.Flags().StringArrayVarP(&addSilenceCmd.matchers, "matcher", "m", []string{}, "Label matcher of the silence, i.e. severity=warning, namespace=~\"openshift-.*\" or pod!=foo (repeatable)")
+	cmd.Flags().StringVarP(&addSilenceCmd.template, "template", "t", "", "Name of a silence template defined under "+SilenceTemplatesKey+" in the osdctl config")
 	cmd.Flags().StringVar(&addSilenceCmd.reason, "reason", "", "The reason for this command, which requires elevation, to be run (usualy an OHSS or PD ticket)")
 
 	_ = cmd.MarkFlagRequired("cluster-id")
 	_ = cmd.MarkFlagRequired("reason")
+	cmd.MarkFlagsMutuallyExclusive("all", "alertname", "matcher")
+	cmd.MarkFlagsMutuallyExclusive("all", "alertname", "template")
 
 	return cmd
 }
@@ -57,6 +85,15 @@ func AddSilence(cmd *addSilenceCmd) {
 	duration := cmd.duration
 	all := cmd.all
 
+	var silence matcherSilence
+	if len(cmd.matchers) > 0 || cmd.template != "" {
+		var err error
+		silence, err = resolveMatcherSilence(cmd.template, cmd.matchers, duration, comment, cmd.durationSet, cmd.commentSet)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+
 	username, clustername := GetUserAndClusterInfo(clusterID)
 
 	elevationReasons := []string{
@@ -69,88 +106,106 @@ func AddSilence(cmd *addSilenceCmd) {
 		log.Fatal(err)
 	}
 
+	client := utils.NewAlertmanagerClient(kubeconfig, clientset)
+	defer client.Close()
+
 	if all {
-		err := AddAllSilence(clusterID, duration, comment, username, clustername, kubeconfig, clientset)
+		_, err := AddAllSilence(clusterID, duration, comment, username, clustername, client)
 		if err != nil {
 			fmt.Printf("Failed to add silence: %s", err)
 		}
 	} else if len(alertID) > 0 {
-		err := AddAlertNameSilence(alertID, duration, comment, username, kubeconfig, clientset)
+		_, err := AddAlertNameSilence(alertID, duration, comment, username, client)
+		if err != nil {
+			fmt.Printf("Failed to add silence: %s", err)
+		}
+	} else if len(silence.matchers) > 0 {
+		_, err := AddMatcherSilence(silence.matchers, silence.duration, silence.comment, username, client)
 		if err != nil {
 			fmt.Printf("Failed to add silence: %s", err)
 		}
 	} else {
-		fmt.Println("No valid option specified. Use --all or --alertname.")
+		fmt.Println("No valid option specified. Use --all, --alertname, --matcher or --template.")
 	}
 }
 
-func AddAllSilence(clusterID, duration, comment, username, clustername string, kubeconfig *rest.Config, clientset *kubernetes.Clientset) error {
-	alerts := fetchAllAlerts(kubeconfig, clientset)
+// AddAllSilence silences every active alert of the cluster by alertname, once per alertname, and
+// returns the IDs of the created silences, including the ones created before a failure.
+func AddAllSilence(clusterID, duration, comment, username, clustername string, client utils.AlertmanagerClient) ([]string, error) {
+	silenceDuration, err := utils.ParseDuration(duration)
+	if err != nil {
+		return nil, err
+	}
+
+	alerts, err := client.ListAlerts()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the alerts: %w", err)
+	}
+
+	var ids []string
+	silenced := map[string]bool{}
 	for _, alert := range alerts {
-		addCmd := []string{
-			"amtool",
-			"silence",
-			"add",
-			"alertname=" + alert.Labels.Alertname,
-			"--alertmanager.url=" + utils.LocalHostUrl,
-			"--duration=" + duration,
-			"--comment=" + comment,
+		// An alertname firing in several namespaces is covered by a single silence
+		if silenced[alert.Name()] {
+			continue
 		}
+		silenced[alert.Name()] = true
 
-		output, err := utils.ExecInAlertManagerPod(kubeconfig, clientset, addCmd)
+		silence := utils.NewSilence([]utils.SilenceMatchers{utils.AlertnameMatcher(alert.Name())}, silenceDuration, username, comment, time.Now())
+		id, err := client.AddSilence(silence)
 		if err != nil {
-			log.Fatal("Exiting the program")
-			return fmt.Errorf("Failed to exec in AlertManager pod: %w", err)
+			return ids, fmt.Errorf("failed to silence alert %s: %w", alert.Name(), err)
 		}
+		ids = append(ids, id)
 
-		formattedOutput := strings.Replace(output, "\n", "", -1)
-
-		fmt.Printf("Alert %s has been silenced with id \"%s\" for a duration of %s by user \"%s\" \n", alert.Labels.Alertname, formattedOutput, duration, username)
+		fmt.Printf("Alert %s has been silenced with id \"%s\" for a duration of %s by user \"%s\" \n", alert.Name(), id, duration, username)
 	}
 
-	return nil
+	return ids, nil
 }
 
-func fetchAllAlerts(kubeconfig *rest.Config, clientset *kubernetes.Clientset) []utils.Alert {
-	var fetchedAlerts []utils.Alert
-
-	listAlertCmd := []string{"amtool", "--alertmanager.url", utils.LocalHostUrl, "alert", "-o", "json"}
-	output, err := utils.ExecInAlertManagerPod(kubeconfig, clientset, listAlertCmd)
+// AddAlertNameSilence silences the given alertnames and returns the IDs of the created silences,
+// including the ones created before a failure.
+func AddAlertNameSilence(alertID []string, duration, comment, username string, client utils.AlertmanagerClient) ([]string, error) {
+	silenceDuration, err := utils.ParseDuration(duration)
 	if err != nil {
-		log.Fatal(err)
+		return nil, err
 	}
 
-	err = json.Unmarshal([]byte(output), &fetchedAlerts)
-	if err != nil {
-		log.Fatal("Error in unmarshaling the alerts", err)
+	var ids []string
+	for _, alertname := range alertID {
+		silence := utils.NewSilence([]utils.SilenceMatchers{utils.AlertnameMatcher(alertname)}, silenceDuration, username, comment, time.Now())
+		id, err := client.AddSilence(silence)
+		if err != nil {
+			return ids, fmt.Errorf("failed to silence alert %s: %w", alertname, err)
+		}
+		ids = append(ids, id)
+
+		fmt.Printf("Alert %s has been silenced with id \"%s\" for duration of %s by user \"%s\" \n", alertname, id, duration, username)
 	}
 
-	return fetchedAlerts
+	return ids, nil
 }
 
-func AddAlertNameSilence(alertID []string, duration, comment, username string, kubeconfig *rest.Config, clientset *kubernetes.Clientset) error {
-	for _, alertname := range alertID {
-		addCmd := []string{
-			"amtool",
-			"silence",
-			"add",
-			"alertname=" + alertname,
-			"--alertmanager.url=" + utils.LocalHostUrl,
-			"--duration=" + duration,
-			"--comment=" + comment,
-		}
-
-		output, err := utils.ExecInAlertManagerPod(kubeconfig, clientset, addCmd)
-		if err != nil {
-			return fmt.Errorf("failed to exec in AlertManager pod: %w", err)
-		}
+// AddMatcherSilence adds a single silence for the alerts matching all the matchers and returns its ID.
+func AddMatcherSilence(matchers []utils.SilenceMatchers, duration, comment, username string, client utils.AlertmanagerClient) ([]string, error) {
+	silenceDuration, err := utils.ParseDuration(duration)
+	if err != nil {
+		return nil, err
+	}
 
-		formattedOutput := strings.Replace(output, "\n", "", -1)
+	id, err := client.AddSilence(utils.NewSilence(matchers, silenceDuration, username, comment, time.Now()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to add silence: %w", err)
+	}
 
-		fmt.Printf("Alert %s has been silenced with id \"%s\" for duration of %s by user \"%s\" \n", alertname, formattedOutput, duration, username)
+	matcherStrings := make([]string, 0, len(matchers))
+	for _, matcher := range matchers {
+		matcherStrings = append(matcherStrings, matcher.String())
 	}
+	fmt.Printf("Alerts matching {%s} have been silenced with id \"%s\" for duration of %s by user \"%s\" \n", strings.Join(matcherStrings, ", "), id, duration, username)
 
-	return nil
+	return []string{id}, nil
 }
 
 // Get User name and clustername
@@ -181,3 +236,12 @@ func GetUserAndClusterInfo(clusterid string) (string, string) {
 	name, _ := account.Body().GetUsername()
 	return name, clustername
 }
+
+// getCurrentUsername returns the OCM username of the current user.
+func getCurrentUsername(connection *sdk.Connection) (string, error) {
+	account, err := connection.AccountsMgmt().V1().CurrentAccount().Get().Send()
+	if err != nil {
+		return "", fmt.Errorf("failed to retrieve the current account: %w", err)
+	}
+	return account.Body().Username(), nil
+}
@@ -0,0 +1,41 @@
+package silence
+
+import (
+	"testing"
+
+	"github.com/openshift/osdctl/cmd/alerts/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// alertsClient lists alerts and records the alertnames of the silences added.
+type alertsClient struct {
+	utils.AlertmanagerClient
+	alerts   []utils.Alert
+	silenced []string
+}
+
+func (c *alertsClient) ListAlerts() ([]utils.Alert, error) {
+	return c.alerts, nil
+}
+
+func (c *alertsClient) AddSilence(silence utils.PostableSilence) (string, error) {
+	c.silenced = append(c.silenced, silence.Matchers[0].Value)
+	return "id-" + silence.Matchers[0].Value, nil
+}
+
+func TestAddAllSilence(t *testing.T) {
+	alert := func(name, namespace string) utils.Alert {
+		return utils.Alert{Labels: map[string]string{"alertname": name, "namespace": namespace}}
+	}
+	client := &alertsClient{alerts: []utils.Alert{
+		alert("KubePodCrashLooping", "ns-a"),
+		alert("KubePodCrashLooping", "ns-b"),
+		alert("TargetDown", "ns-a"),
+	}}
+
+	ids, err := AddAllSilence("cluster-id", "1h", "comment", "me", "cluster", client)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"KubePodCrashLooping", "TargetDown"}, client.silenced)
+	assert.Equal(t, []string{"id-KubePodCrashLooping", "id-TargetDown"}, ids)
+}
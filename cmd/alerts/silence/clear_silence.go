@@ -3,13 +3,10 @@ package silence
 import (
 	"fmt"
 	"log"
-	"strings"
 
 	"github.com/openshift/osdctl/cmd/alerts/utils"
 	"github.com/openshift/osdctl/cmd/common"
 	"github.com/spf13/cobra"
-	"k8s.io/client-go/kubernetes"
-	"k8s.io/client-go/rest"
 )
 
 type silenceCmd struct {
@@ -59,75 +56,48 @@ func ClearSilence(cmd *silenceCmd) {
 		log.Fatal(err)
 	}
 
+	client := utils.NewAlertmanagerClient(kubeconfig, clientset)
+	defer client.Close()
+
 	if all {
-		ClearAllSilence(kubeconfig, clientset)
+		ClearAllSilence(client)
 	} else if len(silenceIDs) > 0 {
-		ClearSilenceByID(silenceIDs, kubeconfig, clientset)
+		ClearSilenceByID(silenceIDs, client)
 	} else {
 		fmt.Println("No valid option specified. Using a default option to clear all silences")
-		ClearAllSilence(kubeconfig, clientset)
+		ClearAllSilence(client)
 	}
 }
 
-func ClearAllSilence(kubeconfig *rest.Config, clientset *kubernetes.Clientset) {
-	queryCmd := []string{
-		"amtool",
-		"silence",
-		"query",
-		"-q",
-		"--alertmanager.url=" + utils.LocalHostUrl,
-	}
-
-	queryOutput, err := utils.ExecInAlertManagerPod(kubeconfig, clientset, queryCmd)
+func ClearAllSilence(client utils.AlertmanagerClient) {
+	silences, err := client.ListSilences()
 	if err != nil {
 		fmt.Println("Error encountered while expiring all silence:", err)
 		return
 	}
 
-	formattedOutput := strings.Join(strings.Fields(queryOutput), " ")
-	if formattedOutput == " " || formattedOutput == "" {
+	if len(silences) == 0 {
 		fmt.Println("No Silence has been set for alerts, please create new silence")
 		return
 	}
 
-	finalOutput := strings.Fields(formattedOutput)
-	countsilence := len(finalOutput)
-	for _, silence := range finalOutput {
-		clearCmd := []string{
-			"amtool",
-			"silence",
-			"expire",
-			silence,
-			"--alertmanager.url=" + utils.LocalHostUrl,
-		}
-
-		countsilence = countsilence - 1
-		_, err := utils.ExecInAlertManagerPod(kubeconfig, clientset, clearCmd)
+	for _, silence := range silences {
+		err := client.ExpireSilence(silence.ID)
 		if err != nil {
-			log.Printf("Error expiring silence ID \"%s\" : %v\n", silence, err)
+			log.Printf("Error expiring silence ID \"%s\" : %v\n", silence.ID, err)
 			return
 		}
 
-		fmt.Printf("SilenceID \"%s\" expired successfully.\n", silence)
-		if countsilence == 0 {
-			fmt.Println()
-			fmt.Printf("All SilenceID expired successfully.\n")
-			return
-		}
+		fmt.Printf("SilenceID \"%s\" expired successfully.\n", silence.ID)
 	}
+
+	fmt.Println()
+	fmt.Printf("All SilenceID expired successfully.\n")
 }
 
-func ClearSilenceByID(silenceIDs []string, kubeconfig *rest.Config, clientset *kubernetes.Clientset) {
+func ClearSilenceByID(silenceIDs []string, client utils.AlertmanagerClient) {
 	for _, silenceId := range silenceIDs {
-		clearCmd := []string{
-			"amtool",
-			"silence",
-			"expire",
-			silenceId,
-			"--alertmanager.url=" + utils.LocalHostUrl,
-		}
-
-		_, err := utils.ExecInAlertManagerPod(kubeconfig, clientset, clearCmd)
+		err := client.ExpireSilence(silenceId)
 		if err != nil {
 			log.Printf("Error expiring silence ID \"%s\" %v\n", silenceId, err)
 			continue
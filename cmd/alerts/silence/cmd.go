@@ -17,6 +17,8 @@ func NewCmdSilence() *cobra.Command {
 	silenceCmd.AddCommand(NewCmdClearSilence())
 	silenceCmd.AddCommand(NewCmdListSilence())
 	silenceCmd.AddCommand(NewCmdAddOrgSilence())
+	silenceCmd.AddCommand(NewCmdOrgExpireSilence())
+	silenceCmd.AddCommand(NewCmdOrgListSilence())
 
 	return silenceCmd
 }
@@ -1,9 +1,9 @@
 package silence
 
 import (
-	"encoding/json"
 	"fmt"
 	"log"
+	"time"
 
 	"github.com/openshift/osdctl/cmd/alerts/utils"
 	"github.com/openshift/osdctl/cmd/common"
@@ -35,8 +35,6 @@ func NewCmdListSilence() *cobra.Command {
 }
 
 func ListSilence(cmd *listSilenceCmd) {
-	var silences []utils.Silence
-	silenceCmd := []string{"amtool", "silence", "--alertmanager.url", utils.LocalHostUrl, "-o", "json"}
 	elevationReasons := []string{
 		cmd.reason,
 		"Clear alertmanager silence for a cluster via osdctl",
@@ -47,18 +45,15 @@ func ListSilence(cmd *listSilenceCmd) {
 		log.Fatal(err)
 	}
 
-	op, err := utils.ExecInAlertManagerPod(kubeconfig, clientset, silenceCmd)
+	client := utils.NewAlertmanagerClient(kubeconfig, clientset)
+	defer client.Close()
+
+	silences, err := client.ListSilences()
 	if err != nil {
 		fmt.Println("Error encountered while listing the silences:", err)
 		return
 	}
 
-	opSlice := []byte(op)
-	err = json.Unmarshal(opSlice, &silences)
-	if err != nil {
-		fmt.Println("Error in unmarshaling the data", err)
-	}
-
 	fmt.Printf("Silence Information:\n")
 	if len(silences) > 0 {
 		for _, silence := range silences {
@@ -75,12 +70,12 @@ func printSilence(silence utils.Silence) {
 	fmt.Printf("SilenceID: %s\n", id)
 	fmt.Printf("Status: %s\n", status.State)
 	fmt.Printf("Created By: %s\n", created)
-	fmt.Printf("Starts At: %s\n", starts)
-	fmt.Printf("Ends At: %s\n", end)
+	fmt.Printf("Starts At: %s\n", starts.Format(time.RFC3339))
+	fmt.Printf("Ends At: %s\n", end.Format(time.RFC3339))
 	fmt.Printf("Comment: %s\n", comment)
 	fmt.Println("Matchers:")
 	for _, matcher := range matchers {
-		fmt.Printf("  %s: %s\n", matcher.Name, matcher.Value)
+		fmt.Printf("  %s\n", matcher.String())
 	}
 	fmt.Println("-------------------------------------------")
 }
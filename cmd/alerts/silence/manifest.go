@@ -0,0 +1,116 @@
+package silence
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// SilenceManifest records the silences created on each cluster by an org-wide silence,
+// so that they can be expired with 'osdctl alert silence org-expire'.
+type SilenceManifest struct {
+	Organization string    `json:"organization"`
+	CreatedAt    time.Time `json:"createdAt"`
+	CreatedBy    string    `json:"createdBy"`
+	Comment      string    `json:"comment"`
+	// Clusters maps the internal cluster IDs to the IDs of the silences created on them.
+	Clusters map[string][]string `json:"clusters"`
+
+	path string
+}
+
+// newSilenceManifest returns an empty manifest saved at path.
+func newSilenceManifest(path, organization, createdBy, comment string, now time.Time) *SilenceManifest {
+	return &SilenceManifest{
+		Organization: organization,
+		CreatedAt:    now.UTC(),
+		CreatedBy:    createdBy,
+		Comment:      comment,
+		Clusters:     map[string][]string{},
+		path:         path,
+	}
+}
+
+// defaultManifestPath returns a new manifest path in the osdctl cache directory.
+func defaultManifestPath(organization string, now time.Time) (string, error) {
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(cacheDir, "osdctl", "alert-silences", fmt.Sprintf("org-%s-%s.json", organization, now.UTC().Format("20060102T150405Z"))), nil
+}
+
+// ReadSilenceManifest reads the manifest at path.
+func ReadSilenceManifest(path string) (*SilenceManifest, error) {
+	data, err := os.ReadFile(path) //#nosec G304 -- path is provided by the user
+	if err != nil {
+		return nil, fmt.Errorf("cannot read silence manifest %s: %w", path, err)
+	}
+
+	manifest := &SilenceManifest{}
+	if err := json.Unmarshal(data, manifest); err != nil {
+		return nil, fmt.Errorf("invalid silence manifest %s: %w", path, err)
+	}
+	if manifest.Clusters == nil {
+		manifest.Clusters = map[string][]string{}
+	}
+	manifest.path = path
+	return manifest, nil
+}
+
+// Path returns the location of the manifest.
+func (m *SilenceManifest) Path() string {
+	return m.path
+}
+
+// Add records silences created on a cluster.
+func (m *SilenceManifest) Add(clusterID string, silenceIDs ...string) {
+	if len(silenceIDs) == 0 {
+		return
+	}
+	m.Clusters[clusterID] = append(m.Clusters[clusterID], silenceIDs...)
+}
+
+// Remove forgets a silence of a cluster, i.e. once it has been expired.
+func (m *SilenceManifest) Remove(clusterID, silenceID string) {
+	ids := m.Clusters[clusterID]
+	for i, id := range ids {
+		if id == silenceID {
+			ids = append(ids[:i], ids[i+1:]...)
+			break
+		}
+	}
+	if len(ids) == 0 {
+		delete(m.Clusters, clusterID)
+		return
+	}
+	m.Clusters[clusterID] = ids
+}
+
+// SilenceCount returns the number of silences in the manifest.
+func (m *SilenceManifest) SilenceCount() int {
+	count := 0
+	for _, ids := range m.Clusters {
+		count += len(ids)
+	}
+	return count
+}
+
+// Save writes the manifest to its path, replacing the previous version atomically.
+func (m *SilenceManifest) Save() error {
+	if err := os.MkdirAll(filepath.Dir(m.path), 0750); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp := m.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("cannot write silence manifest %s: %w", m.path, err)
+	}
+	return os.Rename(tmp, m.path)
+}
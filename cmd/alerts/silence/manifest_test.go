@@ -0,0 +1,80 @@
+package silence
+
+import (
+	"bytes"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/openshift/osdctl/cmd/alerts/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeClient expires the silences not listed in failing.
+type fakeClient struct {
+	utils.AlertmanagerClient
+	failing map[string]bool
+	expired []string
+}
+
+func (c *fakeClient) ExpireSilence(id string) error {
+	if c.failing[id] {
+		return errors.New("expire failed")
+	}
+	c.expired = append(c.expired, id)
+	return nil
+}
+
+func TestSilenceManifest(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "manifest.json")
+	now := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+
+	manifest := newSilenceManifest(path, "org-id", "me", "OHSS-1234", now)
+	manifest.Add("cluster-a", "s1", "s2")
+	manifest.Add("cluster-b", "s3")
+	manifest.Add("cluster-c")
+	require.NoError(t, manifest.Save())
+
+	read, err := ReadSilenceManifest(path)
+	require.NoError(t, err)
+	assert.Equal(t, "org-id", read.Organization)
+	assert.Equal(t, now, read.CreatedAt)
+	assert.Equal(t, map[string][]string{"cluster-a": {"s1", "s2"}, "cluster-b": {"s3"}}, read.Clusters)
+	assert.Equal(t, 3, read.SilenceCount())
+
+	client := &fakeClient{failing: map[string]bool{"s2": true}}
+	expireManifestSilences(read, "cluster-a", client)
+	expireManifestSilences(read, "cluster-b", client)
+	assert.Equal(t, []string{"s1", "s3"}, client.expired)
+	assert.Equal(t, map[string][]string{"cluster-a": {"s2"}}, read.Clusters)
+
+	_, err = ReadSilenceManifest(filepath.Join(t.TempDir(), "missing.json"))
+	assert.Error(t, err)
+}
+
+func TestFilterOsdctlSilences(t *testing.T) {
+	endsAt := time.Date(2024, 5, 16, 10, 0, 0, 0, time.UTC)
+	silences := []utils.Silence{
+		{ID: "mine", CreatedBy: "me", Comment: "OHSS-1234", Status: utils.SilenceStatus{State: utils.SilenceStateActive}, EndsAt: endsAt,
+			Matchers: []utils.SilenceMatchers{utils.AlertnameMatcher("Foo")}},
+		{ID: "default-comment", CreatedBy: "someone", Comment: "Adding silence using the osdctl alert command", Status: utils.SilenceStatus{State: utils.SilenceStatePending}},
+		{ID: "expired", CreatedBy: "me", Status: utils.SilenceStatus{State: utils.SilenceStateExpired}},
+		{ID: "other", CreatedBy: "someone", Comment: "manual", Status: utils.SilenceStatus{State: utils.SilenceStateActive}},
+	}
+
+	filtered := filterOsdctlSilences("cluster-a", silences, "me", osdctlCommentMarker)
+	require.Len(t, filtered, 2)
+	assert.Equal(t, "mine", filtered[0].ID)
+	assert.Equal(t, []string{`alertname="Foo"`}, filtered[0].Matchers)
+	assert.Equal(t, "cluster-a", filtered[0].ClusterID)
+	assert.Equal(t, "default-comment", filtered[1].ID)
+
+	assert.Len(t, filterOsdctlSilences("cluster-a", silences, "", "OHSS-1234"), 1)
+
+	var out bytes.Buffer
+	require.NoError(t, printOrgSilences(&out, filtered, map[string]string{"cluster-b": "unreachable"}, endsAt.Add(-2*time.Hour)))
+	assert.Contains(t, out.String(), "2h0m0s")
+	assert.Contains(t, out.String(), "cluster-b: unreachable")
+}
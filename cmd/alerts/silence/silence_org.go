@@ -3,7 +3,9 @@ package silence
 import (
 	"fmt"
 	"log"
+	"time"
 
+	"github.com/openshift/osdctl/cmd/alerts/utils"
 	"github.com/openshift/osdctl/cmd/common"
 	orgutils "github.com/openshift/osdctl/cmd/org"
 	ocmutils "github.com/openshift/osdctl/pkg/utils"
@@ -13,21 +15,29 @@ import (
 type AddOrgSilenceCmd struct {
 	organization string
 	alertID      []string
+	matchers     []string
+	template     string
 	duration     string
 	comment      string
 	all          bool
+	manifest     string
+	durationSet  bool
 }
 
 func NewCmdAddOrgSilence() *cobra.Command {
 	AddOrgSilenceCmd := &AddOrgSilenceCmd{}
 	cmd := &cobra.Command{
-		Use:               "org <org-id> [--all --duration --comment | --alertname --duration --comment]",
-		Short:             "Add new silence for alert for org",
-		Long:              `add new silence for specfic or all alerts with comment and duration of alert for an organization. OHSS required for org-wide silence`,
+		Use:   "org <org-id> [--all --duration --comment | --alertname --duration --comment | --matcher --template --duration --comment]",
+		Short: "Add new silence for alert for org",
+		Long: `add new silence for specfic or all alerts with comment and duration of alert for an organization. OHSS required for org-wide silence
+
+The IDs of the created silences are recorded per cluster in a manifest, which can be
+passed to 'osdctl alert silence org-expire' to expire them.`,
 		Args:              cobra.ExactArgs(1),
 		DisableAutoGenTag: true,
 		Run: func(cmd *cobra.Command, args []string) {
 			AddOrgSilenceCmd.organization = args[0]
+			AddOrgSilenceCmd.durationSet = cmd.Flags().Changed("duration")
 			AddOrgSilence(AddOrgSilenceCmd)
 		},
 	}
@@ -36,7 +46,12 @@ func NewCmdAddOrgSilence() *cobra.Command {
 	cmd.Flags().StringVarP(&AddOrgSilenceCmd.comment, "comment", "c", "", "add comment about silence. OHSS required for org-wide silence")
 	cmd.Flags().StringVarP(&AddOrgSilenceCmd.duration, "duration", "d", "15d", "add duration for silence") //default duration set to 15 days
 	cmd.Flags().BoolVarP(&AddOrgSilenceCmd.all, "all", "a", false, "add silences for all alert")
+	cmd.Flags().StringArrayVarP(&AddOrgSilenceCmd.matchers, "matcher", "m", []string{}, "Label matcher of the silence, i.e. severity=warning, namespace=~\"openshift-.*\" or pod!=foo (repeatable)")
+	cmd.Flags().StringVarP(&AddOrgSilenceCmd.template, "template", "t", "", "Name of a silence template defined under "+SilenceTemplatesKey+" in the osdctl config")
+	cmd.Flags().StringVar(&AddOrgSilenceCmd.manifest, "manifest", "", "Path of the manifest recording the created silences (default: a new file in the osdctl cache directory)")
 	cmd.MarkFlagRequired("comment")
+	cmd.MarkFlagsMutuallyExclusive("all", "alertname", "matcher")
+	cmd.MarkFlagsMutuallyExclusive("all", "alertname", "template")
 
 	return cmd
 }
@@ -49,6 +64,15 @@ func AddOrgSilence(cmd *AddOrgSilenceCmd) {
 	all := cmd.all
 	organizationID := cmd.organization
 
+	var silence matcherSilence
+	if len(cmd.matchers) > 0 || cmd.template != "" {
+		var err error
+		silence, err = resolveMatcherSilence(cmd.template, cmd.matchers, duration, comment, cmd.durationSet, true)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+
 	subscriptions, err := orgutils.SearchSubscriptions(organizationID, orgutils.StatusActive)
 	if err != nil {
 		log.Fatal(err)
@@ -67,7 +91,26 @@ func AddOrgSilence(cmd *AddOrgSilenceCmd) {
 	}
 
 	log.Printf("Are you sure you want silence alerts for %d clusters for this organization: %s", len(subscriptions), organization.Name())
-	ocmutils.ConfirmPrompt()
+	if !ocmutils.ConfirmPrompt() {
+		log.Printf("Silencing alerts for organization %s cancelled", organization.Name())
+		return
+	}
+
+	manifestPath := cmd.manifest
+	if manifestPath == "" {
+		manifestPath, err = defaultManifestPath(organizationID, time.Now())
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+	username, err := getCurrentUsername(connection)
+	if err != nil {
+		log.Fatal(err)
+	}
+	manifest := newSilenceManifest(manifestPath, organizationID, username, comment, time.Now())
+	if err := manifest.Save(); err != nil {
+		log.Fatal(err)
+	}
 
 	for _, subscription := range subscriptions {
 		clusterID := subscription.ClusterID()
@@ -78,7 +121,7 @@ func AddOrgSilence(cmd *AddOrgSilenceCmd) {
 			log.Printf("Silencing alert(s) on cluster: %s", clusterID)
 		}
 
-		username, clustername := GetUserAndClusterInfo(clusterID)
+		_, clustername := GetUserAndClusterInfo(clusterID)
 
 		_, kubeconfig, clientset, err := common.GetKubeConfigAndClient(clusterID)
 		if err != nil {
@@ -86,18 +129,34 @@ func AddOrgSilence(cmd *AddOrgSilenceCmd) {
 			continue //Skip if cluster is not in supported state
 		}
 
+		client := utils.NewAlertmanagerClient(kubeconfig, clientset)
+		var silenceIDs []string
 		if all {
-			err := AddAllSilence(clusterID, duration, comment, username, clustername, kubeconfig, clientset)
+			silenceIDs, err = AddAllSilence(clusterID, duration, comment, username, clustername, client)
 			if err != nil {
 				log.Print(err)
 			}
 		} else if len(alertID) > 0 {
-			err := AddAlertNameSilence(alertID, duration, comment, username, kubeconfig, clientset)
+			silenceIDs, err = AddAlertNameSilence(alertID, duration, comment, username, client)
+			if err != nil {
+				log.Print(err)
+			}
+		} else if len(silence.matchers) > 0 {
+			silenceIDs, err = AddMatcherSilence(silence.matchers, silence.duration, silence.comment, username, client)
 			if err != nil {
 				log.Print(err)
 			}
 		} else {
-			fmt.Println("No valid option specified. Use --all or --alertname.")
+			fmt.Println("No valid option specified. Use --all, --alertname, --matcher or --template.")
+		}
+		client.Close()
+
+		manifest.Add(clusterID, silenceIDs...)
+		if err := manifest.Save(); err != nil {
+			log.Printf("Failed to record the silences of cluster %s in the manifest: %v", clusterID, err)
 		}
 	}
+
+	log.Printf("Created %d silences on %d clusters, recorded in %s", manifest.SilenceCount(), len(manifest.Clusters), manifest.Path())
+	log.Printf("To expire them, run: osdctl alert silence org-expire --manifest %s", manifest.Path())
 }
@@ -0,0 +1,102 @@
+package silence
+
+import (
+	"fmt"
+	"log"
+	"sort"
+
+	"github.com/openshift/osdctl/cmd/alerts/utils"
+	"github.com/openshift/osdctl/cmd/common"
+	ocmutils "github.com/openshift/osdctl/pkg/utils"
+	"github.com/spf13/cobra"
+)
+
+type orgExpireSilenceCmd struct {
+	manifest string
+	yes      bool
+}
+
+func NewCmdOrgExpireSilence() *cobra.Command {
+	orgExpireSilenceCmd := &orgExpireSilenceCmd{}
+	cmd := &cobra.Command{
+		Use:   "org-expire --manifest <manifest>",
+		Short: "Expire the silences created by an org-wide silence",
+		Long: `expire the silences recorded in the manifest written by 'osdctl alert silence org'.
+
+Expired silences are removed from the manifest, so that the command can be run again
+with the same manifest to retry the clusters that failed.`,
+		Example:           `  osdctl alert silence org-expire --manifest ~/.cache/osdctl/alert-silences/org-${ORG_ID}-20240501T100000Z.json`,
+		Args:              cobra.NoArgs,
+		DisableAutoGenTag: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return ExpireOrgSilences(orgExpireSilenceCmd)
+		},
+	}
+
+	cmd.Flags().StringVar(&orgExpireSilenceCmd.manifest, "manifest", "", "Manifest written by 'osdctl alert silence org'")
+	cmd.Flags().BoolVarP(&orgExpireSilenceCmd.yes, "yes", "y", false, "Skip the confirmation prompt")
+	_ = cmd.MarkFlagRequired("manifest")
+
+	return cmd
+}
+
+// ExpireOrgSilences expires the silences recorded in the manifest of an org-wide silence.
+func ExpireOrgSilences(cmd *orgExpireSilenceCmd) error {
+	manifest, err := ReadSilenceManifest(cmd.manifest)
+	if err != nil {
+		return err
+	}
+
+	total := manifest.SilenceCount()
+	if total == 0 {
+		fmt.Printf("No silences left to expire in %s\n", manifest.Path())
+		return nil
+	}
+
+	log.Printf("Are you sure you want to expire %d silences on %d clusters of organization %s", total, len(manifest.Clusters), manifest.Organization)
+	if !cmd.yes && !ocmutils.ConfirmPrompt() {
+		return nil
+	}
+
+	clusterIDs := make([]string, 0, len(manifest.Clusters))
+	for clusterID := range manifest.Clusters {
+		clusterIDs = append(clusterIDs, clusterID)
+	}
+	sort.Strings(clusterIDs)
+
+	for _, clusterID := range clusterIDs {
+		log.Printf("Expiring silence(s) on cluster: %s", clusterID)
+
+		_, kubeconfig, clientset, err := common.GetKubeConfigAndClient(clusterID)
+		if err != nil {
+			log.Print(err)
+			continue
+		}
+
+		client := utils.NewAlertmanagerClient(kubeconfig, clientset)
+		expireManifestSilences(manifest, clusterID, client)
+		client.Close()
+
+		if err := manifest.Save(); err != nil {
+			return err
+		}
+	}
+
+	if remaining := manifest.SilenceCount(); remaining > 0 {
+		return fmt.Errorf("%d of %d silences could not be expired, run the command again with --manifest %s to retry", remaining, total, manifest.Path())
+	}
+	fmt.Printf("All %d silences expired successfully.\n", total)
+	return nil
+}
+
+// expireManifestSilences expires the silences of the cluster and removes them from the manifest.
+func expireManifestSilences(manifest *SilenceManifest, clusterID string, client utils.AlertmanagerClient) {
+	for _, silenceID := range append([]string{}, manifest.Clusters[clusterID]...) {
+		if err := client.ExpireSilence(silenceID); err != nil {
+			log.Printf("Error expiring silence ID \"%s\" on cluster %s: %v", silenceID, clusterID, err)
+			continue
+		}
+		manifest.Remove(clusterID, silenceID)
+		fmt.Printf("SilenceID \"%s\" expired successfully on cluster %s.\n", silenceID, clusterID)
+	}
+}
@@ -0,0 +1,215 @@
+package silence
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/openshift/osdctl/cmd/alerts/utils"
+	"github.com/openshift/osdctl/cmd/common"
+	orgutils "github.com/openshift/osdctl/cmd/org"
+	"github.com/openshift/osdctl/pkg/printer"
+	ocmutils "github.com/openshift/osdctl/pkg/utils"
+	"github.com/spf13/cobra"
+)
+
+const (
+	outputText = "text"
+	outputJSON = "json"
+
+	// osdctlCommentMarker is contained in the default comment of the silences added by osdctl.
+	osdctlCommentMarker = "osdctl"
+)
+
+type orgListSilenceCmd struct {
+	organization    string
+	createdBy       string
+	commentContains string
+	output          string
+}
+
+// OrgSilence is a silence of a cluster of an organization.
+type OrgSilence struct {
+	ClusterID string    `json:"clusterId"`
+	ID        string    `json:"id"`
+	State     string    `json:"state"`
+	Matchers  []string  `json:"matchers"`
+	CreatedBy string    `json:"createdBy"`
+	Comment   string    `json:"comment"`
+	EndsAt    time.Time `json:"endsAt"`
+}
+
+func NewCmdOrgListSilence() *cobra.Command {
+	orgListSilenceCmd := &orgListSilenceCmd{}
+	cmd := &cobra.Command{
+		Use:   "org-list <org-id>",
+		Short: "List the silences created by osdctl on the clusters of an org",
+		Long: `list, for every active cluster of the organization, the active and pending silences
+created by osdctl and when they expire.
+
+A silence is considered created by osdctl when it was created by the current OCM user
+(or --created-by), or when its comment contains --comment-contains.`,
+		Example: `  osdctl alert silence org-list ${ORG_ID}
+
+  # Silences created by anyone for a given ticket
+  osdctl alert silence org-list ${ORG_ID} --created-by "" --comment-contains OHSS-1234 -o json`,
+		Args:              cobra.ExactArgs(1),
+		DisableAutoGenTag: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			orgListSilenceCmd.organization = args[0]
+			if !cmd.Flags().Changed("created-by") {
+				orgListSilenceCmd.createdBy = currentUsernameOrEmpty()
+			}
+			return ListOrgSilences(orgListSilenceCmd)
+		},
+	}
+
+	cmd.Flags().StringVar(&orgListSilenceCmd.createdBy, "created-by", "", "Creator of the silences (default: the current OCM user)")
+	cmd.Flags().StringVar(&orgListSilenceCmd.commentContains, "comment-contains", osdctlCommentMarker, "Text contained in the comment of the silences")
+	cmd.Flags().StringVarP(&orgListSilenceCmd.output, "output", "o", outputText, "Output format. One of: text, json")
+
+	return cmd
+}
+
+// ListOrgSilences lists the silences created by osdctl on the active clusters of the organization.
+func ListOrgSilences(cmd *orgListSilenceCmd) error {
+	if cmd.output != outputText && cmd.output != outputJSON {
+		return fmt.Errorf("invalid output format: %s (allowed: text, json)", cmd.output)
+	}
+	if cmd.createdBy == "" && cmd.commentContains == "" {
+		return fmt.Errorf("at least one of --created-by and --comment-contains must be set")
+	}
+
+	subscriptions, err := orgutils.SearchSubscriptions(cmd.organization, orgutils.StatusActive)
+	if err != nil {
+		return err
+	} else if len(subscriptions) == 0 {
+		return fmt.Errorf("no subscriptions found with that organization ID")
+	}
+
+	silences := []OrgSilence{}
+	failed := map[string]string{}
+	for _, subscription := range subscriptions {
+		clusterID := subscription.ClusterID()
+		if clusterID == "" {
+			continue
+		}
+		log.Printf("Listing silences of cluster: %s", clusterID)
+
+		_, kubeconfig, clientset, err := common.GetKubeConfigAndClient(clusterID)
+		if err != nil {
+			failed[clusterID] = err.Error()
+			continue
+		}
+
+		client := utils.NewAlertmanagerClient(kubeconfig, clientset)
+		clusterSilences, err := client.ListSilences()
+		client.Close()
+		if err != nil {
+			failed[clusterID] = err.Error()
+			continue
+		}
+		silences = append(silences, filterOsdctlSilences(clusterID, clusterSilences, cmd.createdBy, cmd.commentContains)...)
+	}
+
+	if cmd.output == outputJSON {
+		data, err := json.MarshalIndent(map[string]any{"silences": silences, "errors": failed}, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+	return printOrgSilences(os.Stdout, silences, failed, time.Now())
+}
+
+// filterOsdctlSilences returns the unexpired silences created by createdBy or whose comment
+// contains commentContains. Empty criteria are ignored.
+func filterOsdctlSilences(clusterID string, silences []utils.Silence, createdBy, commentContains string) []OrgSilence {
+	var result []OrgSilence
+	for _, silence := range silences {
+		if silence.Status.State == utils.SilenceStateExpired {
+			continue
+		}
+		byCreator := createdBy != "" && silence.CreatedBy == createdBy
+		byComment := commentContains != "" && strings.Contains(silence.Comment, commentContains)
+		if !byCreator && !byComment {
+			continue
+		}
+
+		matchers := make([]string, 0, len(silence.Matchers))
+		for _, matcher := range silence.Matchers {
+			matchers = append(matchers, matcher.String())
+		}
+		result = append(result, OrgSilence{
+			ClusterID: clusterID,
+			ID:        silence.ID,
+			State:     silence.Status.State,
+			Matchers:  matchers,
+			CreatedBy: silence.CreatedBy,
+			Comment:   silence.Comment,
+			EndsAt:    silence.EndsAt,
+		})
+	}
+	return result
+}
+
+func printOrgSilences(out io.Writer, silences []OrgSilence, failed map[string]string, now time.Time) error {
+	if len(silences) == 0 {
+		fmt.Fprintln(out, "No silences created by osdctl found.")
+	} else {
+		table := printer.NewTablePrinter(out, 20, 1, 3, ' ')
+		table.AddRow([]string{"CLUSTER", "SILENCE ID", "STATE", "MATCHERS", "CREATED BY", "ENDS AT", "EXPIRES IN"})
+		for _, silence := range silences {
+			table.AddRow([]string{
+				silence.ClusterID,
+				silence.ID,
+				silence.State,
+				strings.Join(silence.Matchers, ","),
+				silence.CreatedBy,
+				silence.EndsAt.Format(time.RFC3339),
+				silence.EndsAt.Sub(now).Round(time.Minute).String(),
+			})
+		}
+		if err := table.Flush(); err != nil {
+			return err
+		}
+	}
+
+	if len(failed) > 0 {
+		clusterIDs := make([]string, 0, len(failed))
+		for clusterID := range failed {
+			clusterIDs = append(clusterIDs, clusterID)
+		}
+		sort.Strings(clusterIDs)
+
+		fmt.Fprintf(out, "\nFailed to list the silences of %d clusters:\n", len(failed))
+		for _, clusterID := range clusterIDs {
+			fmt.Fprintf(out, "  %s: %s\n", clusterID, failed[clusterID])
+		}
+	}
+	return nil
+}
+
+// currentUsernameOrEmpty returns the OCM username of the current user, or an empty string
+// if it cannot be retrieved.
+func currentUsernameOrEmpty() string {
+	connection, err := ocmutils.CreateConnection()
+	if err != nil {
+		log.Printf("Cannot retrieve the current OCM user: %v", err)
+		return ""
+	}
+	defer connection.Close()
+
+	username, err := getCurrentUsername(connection)
+	if err != nil {
+		log.Printf("Cannot retrieve the current OCM user: %v", err)
+		return ""
+	}
+	return username
+}
@@ -0,0 +1,86 @@
+package silence
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/openshift/osdctl/cmd/alerts/utils"
+	"github.com/spf13/viper"
+)
+
+// SilenceTemplatesKey is the osdctl config key holding the named silence templates, i.e.
+//
+//	alert_silence_templates:
+//	  upgrade-window:
+//	    matchers: ['alertname=~"ClusterOperator(Down|Degraded)"', 'severity!=critical']
+//	    duration: 4h
+//	    comment: Silencing operator alerts during the upgrade window
+const SilenceTemplatesKey = "alert_silence_templates"
+
+// SilenceTemplate is a named set of matchers and duration defined in the osdctl config.
+type SilenceTemplate struct {
+	Matchers []string `mapstructure:"matchers"`
+	Duration string   `mapstructure:"duration"`
+	Comment  string   `mapstructure:"comment"`
+}
+
+// loadSilenceTemplate returns the silence template with the given name from the osdctl config.
+func loadSilenceTemplate(name string) (SilenceTemplate, error) {
+	templates := map[string]SilenceTemplate{}
+	if err := viper.UnmarshalKey(SilenceTemplatesKey, &templates); err != nil {
+		return SilenceTemplate{}, fmt.Errorf("invalid %s in the osdctl config: %w", SilenceTemplatesKey, err)
+	}
+
+	template, ok := templates[name]
+	if !ok {
+		names := make([]string, 0, len(templates))
+		for templateName := range templates {
+			names = append(names, templateName)
+		}
+		sort.Strings(names)
+		return SilenceTemplate{}, fmt.Errorf("silence template %q not found in %s of the osdctl config (available: %s)", name, SilenceTemplatesKey, strings.Join(names, ", "))
+	}
+	if len(template.Matchers) == 0 {
+		return SilenceTemplate{}, fmt.Errorf("silence template %q has no matchers", name)
+	}
+	return template, nil
+}
+
+// matcherSilence is a silence defined by --matcher flags and/or a silence template.
+type matcherSilence struct {
+	matchers []utils.SilenceMatchers
+	duration string
+	comment  string
+}
+
+// resolveMatcherSilence combines the matchers of the template, if any, with the given matchers.
+// The duration and comment of the template apply unless they were set on the command line.
+func resolveMatcherSilence(templateName string, matchers []string, duration, comment string, durationSet, commentSet bool) (matcherSilence, error) {
+	silence := matcherSilence{duration: duration, comment: comment}
+
+	if templateName != "" {
+		template, err := loadSilenceTemplate(templateName)
+		if err != nil {
+			return matcherSilence{}, err
+		}
+		matchers = append(append([]string{}, template.Matchers...), matchers...)
+		if template.Duration != "" && !durationSet {
+			silence.duration = template.Duration
+		}
+		if template.Comment != "" && !commentSet {
+			silence.comment = template.Comment
+		}
+	}
+
+	parsed, err := utils.ParseMatchers(matchers)
+	if err != nil {
+		return matcherSilence{}, err
+	}
+	silence.matchers = parsed
+
+	if _, err := utils.ParseDuration(silence.duration); err != nil {
+		return matcherSilence{}, err
+	}
+	return silence, nil
+}
@@ -0,0 +1,67 @@
+package silence
+
+import (
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setSilenceTemplates(t *testing.T) {
+	t.Helper()
+	viper.Set(SilenceTemplatesKey, map[string]any{
+		"upgrade-window": map[string]any{
+			"matchers": []string{`alertname=~"ClusterOperator(Down|Degraded)"`, "severity!=critical"},
+			"duration": "4h",
+			"comment":  "upgrade window",
+		},
+		"empty": map[string]any{"duration": "1h"},
+	})
+	t.Cleanup(func() { viper.Set(SilenceTemplatesKey, nil) })
+}
+
+func TestResolveMatcherSilence(t *testing.T) {
+	setSilenceTemplates(t)
+
+	t.Run("matchers only", func(t *testing.T) {
+		silence, err := resolveMatcherSilence("", []string{"namespace=openshift-logging"}, "15d", "comment", false, false)
+		require.NoError(t, err)
+		require.Len(t, silence.matchers, 1)
+		assert.Equal(t, "namespace", silence.matchers[0].Name)
+		assert.Equal(t, "15d", silence.duration)
+		assert.Equal(t, "comment", silence.comment)
+	})
+
+	t.Run("template defaults", func(t *testing.T) {
+		silence, err := resolveMatcherSilence("upgrade-window", []string{"namespace=foo"}, "15d", "default comment", false, false)
+		require.NoError(t, err)
+		require.Len(t, silence.matchers, 3)
+		assert.Equal(t, "alertname", silence.matchers[0].Name)
+		assert.Equal(t, "namespace", silence.matchers[2].Name)
+		assert.Equal(t, "4h", silence.duration)
+		assert.Equal(t, "upgrade window", silence.comment)
+	})
+
+	t.Run("template overridden on the command line", func(t *testing.T) {
+		silence, err := resolveMatcherSilence("upgrade-window", nil, "1h", "OHSS-1234", true, true)
+		require.NoError(t, err)
+		assert.Equal(t, "1h", silence.duration)
+		assert.Equal(t, "OHSS-1234", silence.comment)
+	})
+
+	t.Run("unknown template", func(t *testing.T) {
+		_, err := resolveMatcherSilence("unknown", nil, "15d", "", false, false)
+		assert.ErrorContains(t, err, "available: empty, upgrade-window")
+	})
+
+	t.Run("template without matchers", func(t *testing.T) {
+		_, err := resolveMatcherSilence("empty", nil, "15d", "", false, false)
+		assert.ErrorContains(t, err, "has no matchers")
+	})
+
+	t.Run("invalid duration", func(t *testing.T) {
+		_, err := resolveMatcherSilence("", []string{"severity=info"}, "forever", "", true, false)
+		assert.Error(t, err)
+	})
+}
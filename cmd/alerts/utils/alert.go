@@ -1,24 +1,52 @@
 package utils
 
-// Labels represents a set of labels associated with an alert.
-type AlertLabels struct {
-	Alertname string `json:"alertname"`
-	Severity  string `json:"severity"`
-}
+import "time"
+
+// AlertStateActive is the state of alerts that are neither silenced nor inhibited.
+const AlertStateActive = "active"
 
-// Status represents a set of state associated with an alert.
+// AlertStatus represents the state of an alert and what is suppressing it.
 type AlertStatus struct {
-	State string `json:"state"`
+	State       string   `json:"state"`
+	SilencedBy  []string `json:"silencedBy"`
+	InhibitedBy []string `json:"inhibitedBy"`
 }
 
-// Annotations represents a set of summary/description associated with an alert.
-type AlertAnnotations struct {
-	Summary string `json:"summary"`
+// AlertReceiver is a receiver an alert is routed to.
+type AlertReceiver struct {
+	Name string `json:"name"`
 }
 
-// Alert represents a set of above declared struct Labels,Status and annoataions
+// Alert represents an alert as returned by the Alertmanager v2 API (and 'amtool alert -o json').
 type Alert struct {
-	Labels      AlertLabels      `json:"labels"`
-	Status      AlertStatus      `json:"status"`
-	Annotations AlertAnnotations `json:"annotations"`
+	Labels       map[string]string `json:"labels"`
+	Annotations  map[string]string `json:"annotations"`
+	Status       AlertStatus       `json:"status"`
+	Receivers    []AlertReceiver   `json:"receivers"`
+	Fingerprint  string            `json:"fingerprint"`
+	GeneratorURL string            `json:"generatorURL"`
+	StartsAt     time.Time         `json:"startsAt"`
+	EndsAt       time.Time         `json:"endsAt"`
+	UpdatedAt    time.Time         `json:"updatedAt"`
+}
+
+// Name returns the alertname label of the alert.
+func (a Alert) Name() string {
+	return a.Labels["alertname"]
+}
+
+// Severity returns the severity label of the alert.
+func (a Alert) Severity() string {
+	return a.Labels["severity"]
+}
+
+// Summary returns the summary annotation of the alert, falling back to the message
+// and description annotations used by older alerting rules.
+func (a Alert) Summary() string {
+	for _, annotation := range []string{"summary", "message", "description"} {
+		if value := a.Annotations[annotation]; value != "" {
+			return value
+		}
+	}
+	return ""
 }
@@ -0,0 +1,298 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/prometheus/common/model"
+	"github.com/spf13/viper"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/portforward"
+	"k8s.io/client-go/transport/spdy"
+)
+
+const (
+	// AlertmanagerPort is the port Alertmanager listens on inside its pod.
+	AlertmanagerPort = 9093
+	// UseAmtoolKey is the osdctl config key forcing the use of amtool in the Alertmanager pod
+	// instead of the Alertmanager API.
+	UseAmtoolKey = "alertmanager_use_amtool"
+
+	portForwardTimeout = 30 * time.Second
+)
+
+// AlertmanagerClient lists alerts and manages silences of a cluster's Alertmanager.
+type AlertmanagerClient interface {
+	// ListAlerts returns the alerts of the cluster.
+	ListAlerts() ([]Alert, error)
+	// ListSilences returns the active and pending silences of the cluster.
+	ListSilences() ([]Silence, error)
+	// AddSilence creates a silence and returns its ID.
+	AddSilence(silence PostableSilence) (string, error)
+	// ExpireSilence expires the silence with the given ID.
+	ExpireSilence(id string) error
+	// Close releases the resources of the client.
+	Close()
+}
+
+// NewAlertmanagerClient returns a client of the Alertmanager v2 API, port-forwarded through
+// backplane to the primary or secondary Alertmanager pod. If neither can be reached, or if
+// UseAmtoolKey is set in the osdctl config, a client executing amtool in the pods is returned.
+func NewAlertmanagerClient(kubeconfig *rest.Config, clientset *kubernetes.Clientset) AlertmanagerClient {
+	if viper.GetBool(UseAmtoolKey) {
+		return NewExecClient(kubeconfig, clientset)
+	}
+
+	for _, pod := range []string{PrimaryPod, SecondaryPod} {
+		client, err := newPortForwardedAPIClient(kubeconfig, clientset, pod)
+		if err == nil {
+			return client
+		}
+		log.Printf("Unable to reach the Alertmanager API of %s, %v", pod, err)
+	}
+
+	log.Printf("Falling back to executing amtool in the Alertmanager pods")
+	return NewExecClient(kubeconfig, clientset)
+}
+
+// ParseDuration parses a silence duration, supporting the day and week units of amtool, i.e. 15d.
+func ParseDuration(duration string) (time.Duration, error) {
+	d, err := model.ParseDuration(duration)
+	if err != nil {
+		return 0, fmt.Errorf("invalid duration %q: %w", duration, err)
+	}
+	return time.Duration(d), nil
+}
+
+// APIClient is a client of the Alertmanager v2 HTTP API.
+type APIClient struct {
+	baseURL    string
+	httpClient *http.Client
+	stop       func()
+}
+
+// NewAPIClient returns a client of the Alertmanager v2 API served at baseURL, i.e. http://localhost:9093.
+func NewAPIClient(baseURL string, httpClient *http.Client) *APIClient {
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: 30 * time.Second}
+	}
+	return &APIClient{baseURL: strings.TrimSuffix(baseURL, "/"), httpClient: httpClient, stop: func() {}}
+}
+
+// newPortForwardedAPIClient port-forwards a local port to the Alertmanager port of the pod
+// and returns a client of the API served through it.
+func newPortForwardedAPIClient(kubeconfig *rest.Config, clientset *kubernetes.Clientset, pod string) (*APIClient, error) {
+	transport, upgrader, err := spdy.RoundTripperFor(kubeconfig)
+	if err != nil {
+		return nil, err
+	}
+	portForwardURL := clientset.CoreV1().RESTClient().Post().
+		Resource("pods").Namespace(AccountNamespace).Name(pod).SubResource("portforward").URL()
+	dialer := spdy.NewDialer(upgrader, &http.Client{Transport: transport}, http.MethodPost, portForwardURL)
+
+	stopChan, readyChan := make(chan struct{}), make(chan struct{})
+	forwarder, err := portforward.New(dialer, []string{fmt.Sprintf("0:%d", AlertmanagerPort)}, stopChan, readyChan, io.Discard, io.Discard)
+	if err != nil {
+		return nil, err
+	}
+
+	errChan := make(chan error, 1)
+	go func() {
+		errChan <- forwarder.ForwardPorts()
+	}()
+
+	select {
+	case <-readyChan:
+	case err := <-errChan:
+		return nil, fmt.Errorf("port-forward failed: %w", err)
+	case <-time.After(portForwardTimeout):
+		close(stopChan)
+		return nil, fmt.Errorf("port-forward timed out after %v", portForwardTimeout)
+	}
+
+	ports, err := forwarder.GetPorts()
+	if err != nil || len(ports) == 0 {
+		close(stopChan)
+		return nil, fmt.Errorf("port-forward has no local port: %v", err)
+	}
+
+	client := NewAPIClient(fmt.Sprintf("http://localhost:%d", ports[0].Local), nil)
+	client.stop = func() { close(stopChan) }
+	if err := client.do(http.MethodGet, "/api/v2/status", nil, nil); err != nil {
+		client.Close()
+		return nil, err
+	}
+	return client, nil
+}
+
+// ListAlerts returns the active alerts, leaving out the silenced and inhibited ones like 'amtool alert'.
+func (c *APIClient) ListAlerts() ([]Alert, error) {
+	var alerts []Alert
+	if err := c.do(http.MethodGet, "/api/v2/alerts?active=true&silenced=false&inhibited=false", nil, &alerts); err != nil {
+		return nil, err
+	}
+
+	active := make([]Alert, 0, len(alerts))
+	for _, alert := range alerts {
+		if alert.Status.State == AlertStateActive {
+			active = append(active, alert)
+		}
+	}
+	return active, nil
+}
+
+// ListSilences returns the active and pending silences.
+func (c *APIClient) ListSilences() ([]Silence, error) {
+	var silences []Silence
+	if err := c.do(http.MethodGet, "/api/v2/silences", nil, &silences); err != nil {
+		return nil, err
+	}
+
+	unexpired := make([]Silence, 0, len(silences))
+	for _, silence := range silences {
+		if silence.Status.State != SilenceStateExpired {
+			unexpired = append(unexpired, silence)
+		}
+	}
+	return unexpired, nil
+}
+
+// AddSilence creates a silence and returns its ID.
+func (c *APIClient) AddSilence(silence PostableSilence) (string, error) {
+	var id SilenceID
+	if err := c.do(http.MethodPost, "/api/v2/silences", silence, &id); err != nil {
+		return "", err
+	}
+	return id.ID, nil
+}
+
+// ExpireSilence expires the silence with the given ID.
+func (c *APIClient) ExpireSilence(id string) error {
+	return c.do(http.MethodDelete, "/api/v2/silence/"+url.PathEscape(id), nil, nil)
+}
+
+// Close stops the port-forward of the client, if any.
+func (c *APIClient) Close() {
+	c.stop()
+}
+
+// do sends a request to the API, encoding body and decoding the response into out if not nil.
+func (c *APIClient) do(method, path string, body any, out any) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequest(method, c.baseURL+path, reader)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("alertmanager request %s %s failed: %w", method, path, err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("alertmanager request %s %s failed with HTTP %d: %s", method, path, resp.StatusCode, strings.TrimSpace(string(data)))
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("cannot decode the alertmanager response of %s %s: %w", method, path, err)
+	}
+	return nil
+}
+
+// ExecClient manages alerts and silences by executing amtool in the Alertmanager pods.
+type ExecClient struct {
+	exec func(cmd []string) (string, error)
+}
+
+// NewExecClient returns a client executing amtool in the primary, then secondary, Alertmanager pod.
+func NewExecClient(kubeconfig *rest.Config, clientset *kubernetes.Clientset) *ExecClient {
+	return &ExecClient{exec: func(cmd []string) (string, error) {
+		return ExecInAlertManagerPod(kubeconfig, clientset, cmd)
+	}}
+}
+
+// ListAlerts returns the alerts reported by 'amtool alert'.
+func (c *ExecClient) ListAlerts() ([]Alert, error) {
+	output, err := c.exec([]string{"amtool", "--alertmanager.url", LocalHostUrl, "alert", "-o", "json"})
+	if err != nil {
+		return nil, err
+	}
+
+	var alerts []Alert
+	if err := json.Unmarshal([]byte(output), &alerts); err != nil {
+		return nil, fmt.Errorf("cannot decode the alerts: %w", err)
+	}
+	return alerts, nil
+}
+
+// ListSilences returns the silences reported by 'amtool silence'.
+func (c *ExecClient) ListSilences() ([]Silence, error) {
+	output, err := c.exec([]string{"amtool", "silence", "--alertmanager.url", LocalHostUrl, "-o", "json"})
+	if err != nil {
+		return nil, err
+	}
+
+	var silences []Silence
+	if err := json.Unmarshal([]byte(output), &silences); err != nil {
+		return nil, fmt.Errorf("cannot decode the silences: %w", err)
+	}
+	return silences, nil
+}
+
+// AddSilence creates a silence with 'amtool silence add' and returns its ID.
+func (c *ExecClient) AddSilence(silence PostableSilence) (string, error) {
+	cmd := []string{"amtool", "silence", "add"}
+	for _, matcher := range silence.Matchers {
+		cmd = append(cmd, matcher.String())
+	}
+	cmd = append(cmd,
+		"--alertmanager.url="+LocalHostUrl,
+		"--start="+silence.StartsAt.Format(time.RFC3339),
+		"--end="+silence.EndsAt.Format(time.RFC3339),
+		"--comment="+silence.Comment,
+	)
+	if silence.CreatedBy != "" {
+		cmd = append(cmd, "--author="+silence.CreatedBy)
+	}
+
+	output, err := c.exec(cmd)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(output), nil
+}
+
+// ExpireSilence expires a silence with 'amtool silence expire'.
+func (c *ExecClient) ExpireSilence(id string) error {
+	_, err := c.exec([]string{"amtool", "silence", "expire", id, "--alertmanager.url=" + LocalHostUrl})
+	return err
+}
+
+// Close is a no-op, amtool is executed in the pods.
+func (c *ExecClient) Close() {}
@@ -0,0 +1,151 @@
+package utils
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const alertsResponse = `[
+  {
+    "labels": {"alertname": "KubePodCrashLooping", "severity": "warning", "namespace": "openshift-monitoring"},
+    "annotations": {"description": "Pod is crash looping."},
+    "status": {"state": "active", "silencedBy": [], "inhibitedBy": []},
+    "receivers": [{"name": "pagerduty"}],
+    "fingerprint": "0123456789abcdef",
+    "startsAt": "2024-05-01T10:00:00.000Z",
+    "endsAt": "2024-05-01T10:05:00.000Z",
+    "updatedAt": "2024-05-01T10:01:00.000Z"
+  },
+  {
+    "labels": {"alertname": "Watchdog", "severity": "none"},
+    "annotations": {},
+    "status": {"state": "suppressed", "silencedBy": ["active-id"], "inhibitedBy": []},
+    "receivers": [{"name": "null"}],
+    "fingerprint": "fedcba9876543210",
+    "startsAt": "2024-05-01T10:00:00.000Z",
+    "endsAt": "2024-05-01T10:05:00.000Z",
+    "updatedAt": "2024-05-01T10:01:00.000Z"
+  }
+]`
+
+const silencesResponse = `[
+  {"id": "active-id", "matchers": [{"name": "alertname", "value": "Foo", "isRegex": false, "isEqual": true}], "status": {"state": "active"},
+   "comment": "c", "createdBy": "me", "startsAt": "2024-05-01T10:00:00Z", "endsAt": "2024-05-16T10:00:00Z", "updatedAt": "2024-05-01T10:00:00Z"},
+  {"id": "expired-id", "matchers": [], "status": {"state": "expired"},
+   "comment": "c", "createdBy": "me", "startsAt": "2024-04-01T10:00:00Z", "endsAt": "2024-04-02T10:00:00Z", "updatedAt": "2024-04-02T10:00:00Z"}
+]`
+
+func TestAPIClient(t *testing.T) {
+	var posted PostableSilence
+	var expired string
+	var query url.Values
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/api/v2/alerts":
+			query = r.URL.Query()
+			_, _ = w.Write([]byte(alertsResponse))
+		case r.Method == http.MethodGet && r.URL.Path == "/api/v2/silences":
+			_, _ = w.Write([]byte(silencesResponse))
+		case r.Method == http.MethodPost && r.URL.Path == "/api/v2/silences":
+			if err := json.NewDecoder(r.Body).Decode(&posted); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			_, _ = w.Write([]byte(`{"silenceID": "new-id"}`))
+		case r.Method == http.MethodDelete && strings.HasPrefix(r.URL.Path, "/api/v2/silence/"):
+			expired = strings.TrimPrefix(r.URL.Path, "/api/v2/silence/")
+			if expired != "active-id" {
+				http.Error(w, "silence not found", http.StatusNotFound)
+			}
+		default:
+			http.Error(w, "not found", http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := NewAPIClient(server.URL, server.Client())
+	defer client.Close()
+
+	alerts, err := client.ListAlerts()
+	require.NoError(t, err)
+	require.Len(t, alerts, 1)
+	assert.Equal(t, "false", query.Get("silenced"))
+	assert.Equal(t, "false", query.Get("inhibited"))
+	assert.Equal(t, "KubePodCrashLooping", alerts[0].Name())
+	assert.Equal(t, "warning", alerts[0].Severity())
+	assert.Equal(t, "Pod is crash looping.", alerts[0].Summary())
+	assert.Equal(t, "active", alerts[0].Status.State)
+	assert.Equal(t, time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC), alerts[0].StartsAt)
+
+	silences, err := client.ListSilences()
+	require.NoError(t, err)
+	require.Len(t, silences, 1)
+	assert.Equal(t, "active-id", silences[0].ID)
+
+	now := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	id, err := client.AddSilence(NewSilence([]SilenceMatchers{AlertnameMatcher("Foo")}, time.Hour, "me", "comment", now))
+	require.NoError(t, err)
+	assert.Equal(t, "new-id", id)
+	assert.Equal(t, []SilenceMatchers{{Name: "alertname", Value: "Foo"}}, posted.Matchers)
+	assert.Equal(t, now.Add(time.Hour), posted.EndsAt)
+
+	require.NoError(t, client.ExpireSilence("active-id"))
+	assert.Equal(t, "active-id", expired)
+
+	err = client.ExpireSilence("unknown-id")
+	assert.ErrorContains(t, err, "HTTP 404")
+}
+
+func TestExecClientAddSilence(t *testing.T) {
+	var executed []string
+	client := &ExecClient{exec: func(cmd []string) (string, error) {
+		executed = cmd
+		return "new-id\n", nil
+	}}
+
+	now := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	id, err := client.AddSilence(NewSilence([]SilenceMatchers{AlertnameMatcher("Foo")}, time.Hour, "me", "comment", now))
+	require.NoError(t, err)
+	assert.Equal(t, "new-id", id)
+	assert.Equal(t, []string{
+		"amtool", "silence", "add", `alertname="Foo"`,
+		"--alertmanager.url=" + LocalHostUrl,
+		"--start=2024-05-01T10:00:00Z",
+		"--end=2024-05-01T11:00:00Z",
+		"--comment=comment",
+		"--author=me",
+	}, executed)
+}
+
+func TestSilenceMatchersString(t *testing.T) {
+	notEqual := false
+	tests := []struct {
+		matcher SilenceMatchers
+		want    string
+	}{
+		{SilenceMatchers{Name: "alertname", Value: "Foo"}, `alertname="Foo"`},
+		{SilenceMatchers{Name: "alertname", Value: "Kube.*", IsRegex: true}, `alertname=~"Kube.*"`},
+		{SilenceMatchers{Name: "severity", Value: "info", IsEqual: &notEqual}, `severity!="info"`},
+		{SilenceMatchers{Name: "namespace", Value: "openshift-.*", IsRegex: true, IsEqual: &notEqual}, `namespace!~"openshift-.*"`},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, tt.matcher.String())
+	}
+}
+
+func TestParseDuration(t *testing.T) {
+	d, err := ParseDuration("15d")
+	require.NoError(t, err)
+	assert.Equal(t, 15*24*time.Hour, d)
+
+	_, err = ParseDuration("soon")
+	assert.Error(t, err)
+}
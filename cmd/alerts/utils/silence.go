@@ -1,24 +1,134 @@
 package utils
 
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// SilenceStateActive is the state of silences that currently mute alerts.
+	SilenceStateActive = "active"
+	// SilenceStatePending is the state of silences that start in the future.
+	SilenceStatePending = "pending"
+	// SilenceStateExpired is the state of silences that ended.
+	SilenceStateExpired = "expired"
+)
+
 type SilenceID struct {
-	ID string `json:"id"`
+	ID string `json:"silenceID"`
 }
 
+// SilenceMatchers matches the label Name of alerts against Value.
 type SilenceMatchers struct {
-	Name  string `json:"name"`
-	Value string `json:"value"`
+	Name    string `json:"name"`
+	Value   string `json:"value"`
+	IsRegex bool   `json:"isRegex"`
+	// IsEqual is false for negative matchers, nil is equivalent to true.
+	IsEqual *bool `json:"isEqual,omitempty"`
+}
+
+// Equal reports whether the matcher is a positive (= or =~) matcher.
+func (m SilenceMatchers) Equal() bool {
+	return m.IsEqual == nil || *m.IsEqual
+}
+
+// String returns the matcher in the amtool syntax, i.e. alertname=~"Kube.*".
+func (m SilenceMatchers) String() string {
+	op := "="
+	if !m.Equal() {
+		op = "!="
+	}
+	if m.IsRegex {
+		op = map[string]string{"=": "=~", "!=": "!~"}[op]
+	}
+	return m.Name + op + `"` + m.Value + `"`
 }
 
 type SilenceStatus struct {
 	State string `json:"state"`
 }
 
+// Silence represents a silence as returned by the Alertmanager v2 API (and 'amtool silence -o json').
 type Silence struct {
 	ID        string            `json:"id"`
 	Matchers  []SilenceMatchers `json:"matchers"`
 	Status    SilenceStatus     `json:"status"`
 	Comment   string            `json:"comment"`
 	CreatedBy string            `json:"createdBy"`
-	EndsAt    string            `json:"endsAt"`
-	StartsAt  string            `json:"startsAt"`
+	EndsAt    time.Time         `json:"endsAt"`
+	StartsAt  time.Time         `json:"startsAt"`
+	UpdatedAt time.Time         `json:"updatedAt"`
+}
+
+// PostableSilence is a silence to create through the Alertmanager v2 API.
+type PostableSilence struct {
+	Matchers  []SilenceMatchers `json:"matchers"`
+	Comment   string            `json:"comment"`
+	CreatedBy string            `json:"createdBy"`
+	StartsAt  time.Time         `json:"startsAt"`
+	EndsAt    time.Time         `json:"endsAt"`
+}
+
+// NewSilence returns a silence starting now for the given duration.
+func NewSilence(matchers []SilenceMatchers, duration time.Duration, createdBy, comment string, now time.Time) PostableSilence {
+	return PostableSilence{
+		Matchers:  matchers,
+		Comment:   comment,
+		CreatedBy: createdBy,
+		StartsAt:  now.UTC(),
+		EndsAt:    now.UTC().Add(duration),
+	}
+}
+
+// AlertnameMatcher returns a matcher selecting the alerts named alertname.
+func AlertnameMatcher(alertname string) SilenceMatchers {
+	return SilenceMatchers{Name: "alertname", Value: alertname}
+}
+
+var matcherRegexp = regexp.MustCompile(`^\s*([a-zA-Z_][a-zA-Z0-9_]*)\s*(=~|!~|!=|=)\s*(.*?)\s*$`)
+
+// ParseMatcher parses a matcher in the amtool syntax: name=value, name!=value, name=~regex
+// or name!~regex. The value may be double-quoted.
+func ParseMatcher(matcher string) (SilenceMatchers, error) {
+	parts := matcherRegexp.FindStringSubmatch(matcher)
+	if parts == nil {
+		return SilenceMatchers{}, fmt.Errorf("invalid matcher %q, expected name=value, name!=value, name=~regex or name!~regex", matcher)
+	}
+	name, op, value := parts[1], parts[2], parts[3]
+
+	if strings.HasPrefix(value, `"`) {
+		unquoted, err := strconv.Unquote(value)
+		if err != nil {
+			return SilenceMatchers{}, fmt.Errorf("invalid matcher %q: badly quoted value", matcher)
+		}
+		value = unquoted
+	}
+	if value == "" {
+		return SilenceMatchers{}, fmt.Errorf("invalid matcher %q: empty value", matcher)
+	}
+
+	isEqual := !strings.HasPrefix(op, "!")
+	result := SilenceMatchers{Name: name, Value: value, IsRegex: strings.HasSuffix(op, "~"), IsEqual: &isEqual}
+	if result.IsRegex {
+		if _, err := regexp.Compile("^(?:" + value + ")$"); err != nil {
+			return SilenceMatchers{}, fmt.Errorf("invalid matcher %q: %w", matcher, err)
+		}
+	}
+	return result, nil
+}
+
+// ParseMatchers parses matchers in the amtool syntax, see ParseMatcher.
+func ParseMatchers(matchers []string) ([]SilenceMatchers, error) {
+	result := make([]SilenceMatchers, 0, len(matchers))
+	for _, matcher := range matchers {
+		parsed, err := ParseMatcher(matcher)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, parsed)
+	}
+	return result, nil
 }
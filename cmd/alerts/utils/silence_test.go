@@ -0,0 +1,51 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMatcher(t *testing.T) {
+	tests := []struct {
+		matcher   string
+		want      string
+		wantRegex bool
+		wantEqual bool
+		wantErr   bool
+	}{
+		{matcher: "severity=warning", want: "warning", wantEqual: true},
+		{matcher: `namespace=~"openshift-.*"`, want: "openshift-.*", wantRegex: true, wantEqual: true},
+		{matcher: "pod!=foo", want: "foo"},
+		{matcher: "alertname !~ Kube.*", want: "Kube.*", wantRegex: true},
+		{matcher: `summary="a \"quoted\" value"`, want: `a "quoted" value`, wantEqual: true},
+		{matcher: "severity", wantErr: true},
+		{matcher: "severity=", wantErr: true},
+		{matcher: "1severity=warning", wantErr: true},
+		{matcher: "alertname=~(", wantErr: true},
+		{matcher: `alertname="unterminated`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.matcher, func(t *testing.T) {
+			matcher, err := ParseMatcher(tt.matcher)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, matcher.Value)
+			assert.Equal(t, tt.wantRegex, matcher.IsRegex)
+			assert.Equal(t, tt.wantEqual, matcher.Equal())
+		})
+	}
+}
+
+func TestParseMatchersRoundTrip(t *testing.T) {
+	matchers, err := ParseMatchers([]string{`alertname=~"Kube.*"`, `severity!="info"`})
+	require.NoError(t, err)
+	require.Len(t, matchers, 2)
+	assert.Equal(t, `alertname=~"Kube.*"`, matchers[0].String())
+	assert.Equal(t, `severity!="info"`, matchers[1].String())
+}
@@ -1,93 +1,171 @@
 package cloudtrail
 
 import (
+	"compress/gzip"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
 	"sort"
+	"strings"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/service/cloudtrail/types"
 	"github.com/sirupsen/logrus"
 )
 
+const (
+	cacheIndexFile    = "index.json"
+	cacheBucketSuffix = ".json.gz"
+	cacheBucketLayout = "2006-01-02"
+)
+
 // Cache struct stores CloudTrail periods and their corresponding events,
+// Period is the union of all cached periods, Regions holds the periods
+// that have been retrieved for each region.
+//
+// A cluster cache is a directory holding an index file and one gzip compressed
+// bucket per UTC day of events, so that only the buckets of a requested period
+// have to be read.
 type Cache struct {
-	log      *logrus.Logger
-	filename string
-	Period   []Period
-	Event    []types.Event
+	log     *logrus.Logger
+	dir     string
+	Period  []Period
+	Regions map[string][]Period `json:",omitempty"`
+	// Buckets holds the number of events stored in each daily bucket.
+	Buckets map[string]int `json:",omitempty"`
+	// Event is only used to pass new events to Save, events are stored in the buckets.
+	Event []CachedEvent `json:"-"`
 }
 
-func NewCache(log *logrus.Logger, clusterID string) (*Cache, error) {
+// CachedEvent is a CloudTrail event together with the region it was retrieved from.
+type CachedEvent struct {
+	Region string `json:",omitempty"`
+	types.Event
+}
+
+// legacyCache is the single file <clusterID>.json layout used by previous versions.
+type legacyCache struct {
+	Period []Period
+	Event  []CachedEvent
+}
+
+// CacheDir returns the directory holding the write-events cache of all clusters.
+func CacheDir() (string, error) {
 	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(cacheDir, "osdctl", "cloudtrail", "write-events"), nil
+}
+
+func NewCache(log *logrus.Logger, clusterID string) (*Cache, error) {
+	cacheDir, err := CacheDir()
 	if err != nil {
 		return nil, err
 	}
-	cacheDir = filepath.Join(cacheDir, "osdctl", "cloudtrail", "write-events")
-	filename := filepath.Join(cacheDir, clusterID+".json")
+	return newCacheInDir(log, filepath.Join(cacheDir, clusterID)), nil
+}
 
+func newCacheInDir(log *logrus.Logger, dir string) *Cache {
 	return &Cache{
-		log:      log,
-		filename: filename,
-		Period:   []Period{},
-		Event:    []types.Event{},
-	}, nil
+		log:     log,
+		dir:     dir,
+		Period:  []Period{},
+		Regions: map[string][]Period{},
+		Buckets: map[string]int{},
+		Event:   []CachedEvent{},
+	}
 }
 
-func (c *Cache) EnsureFilenameExist() error {
-	cacheDir := filepath.Dir(c.filename)
+// ClusterID returns the ID of the cluster the cache belongs to.
+func (c *Cache) ClusterID() string {
+	return filepath.Base(c.dir)
+}
 
-	if err := os.MkdirAll(cacheDir, 0755); err != nil {
+// EnsureFilenameExist creates the cache directory and index of the cluster if they
+// do not exist yet. A cache file of the legacy layout is migrated to the new layout.
+func (c *Cache) EnsureFilenameExist() error {
+	if err := os.MkdirAll(c.dir, 0755); err != nil {
 		c.log.Errorf("failed to create cache directory: %v", err)
 		return err
 	}
 
-	if _, err := os.Stat(c.filename); os.IsNotExist(err) {
-		emptyCache := Cache{
-			log:      c.log,
-			filename: c.filename,
-			Period:   []Period{},
-			Event:    []types.Event{},
-		}
+	indexFile := filepath.Join(c.dir, cacheIndexFile)
+	if _, err := os.Stat(indexFile); err == nil {
+		c.log.Debugf("Cache already exists: %s", c.dir)
+		return nil
+	} else if !os.IsNotExist(err) {
+		c.log.Errorf("error checking cache index: %v", err)
+		return err
+	}
 
-		data, err := json.MarshalIndent(emptyCache, "", "  ")
-		if err != nil {
-			c.log.Errorf("failed to marshal empty cache: %v", err)
-			return err
+	if err := c.writeIndex(); err != nil {
+		return err
+	}
+	c.log.Debugf("Created new cache: %s", c.dir)
+
+	return c.migrateLegacy()
+}
+
+// migrateLegacy moves the events of a <clusterID>.json cache file into the buckets.
+// Cache files written before periods were tracked per region cannot tell
+// which regions their periods cover, so only their events are kept.
+func (c *Cache) migrateLegacy() error {
+	legacyFilename := c.dir + ".json"
+	data, err := os.ReadFile(legacyFilename)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var legacy legacyCache
+	if err := json.Unmarshal(data, &legacy); err != nil {
+		c.log.Warnf("Ignoring unreadable legacy cache file %s: %v", legacyFilename, err)
+	} else {
+		for i := range legacy.Event {
+			if legacy.Event[i].Region != "" {
+				continue
+			}
+			rawEventDetails, err := ExtractUserDetails(legacy.Event[i].CloudTrailEvent)
+			if err != nil {
+				continue
+			}
+			legacy.Event[i].Region = rawEventDetails.EventRegion
 		}
-		if err := os.WriteFile(c.filename, data, 0600); err != nil {
-			c.log.Errorf("failed to create cache file: %v", err)
+		c.log.Debugf("Migrating %d events from legacy cache file %s", len(legacy.Event), legacyFilename)
+		if err := c.Save(Cache{Event: legacy.Event}); err != nil {
 			return err
 		}
-		c.log.Debugf("Created new cache file: %s", c.filename)
-		return nil
-
-	} else if err != nil {
-		c.log.Errorf("error checking cache file: %v", err)
-		return err
 	}
 
-	c.log.Debugf("Cache file already exists: %s", c.filename)
-	return nil
+	return os.Remove(legacyFilename)
 }
 
-// CacheInit initializes the cache directory.
-// Creates the directory/cache file if it doesn't exist
+// Read loads the cache index. Events are only read from their buckets by FilterByPeriod.
 func (c *Cache) Read() error {
-	data, err := os.ReadFile(c.filename)
+	data, err := os.ReadFile(filepath.Join(c.dir, cacheIndexFile))
 	if err != nil {
-		c.log.Errorf("failed to read cache file: %v", err)
+		c.log.Errorf("failed to read cache index: %v", err)
 		return err
 	}
 
 	if err := json.Unmarshal(data, &c); err != nil {
-		c.log.Errorf("failed to unmarshal get events: %v", err)
+		c.log.Errorf("failed to unmarshal cache index: %v", err)
 		return err
 	}
 
-	if len(c.Event) == 0 {
-		c.log.Debugf("Cache file is empty")
+	if c.Regions == nil {
+		c.Regions = map[string][]Period{}
+	}
+	if c.Buckets == nil {
+		c.Buckets = map[string]int{}
+	}
+	if len(c.Buckets) == 0 {
+		c.log.Debugf("Cache is empty")
 	}
 
 	return nil
@@ -97,52 +175,279 @@ func (c *Cache) Read() error {
 // Adding new time periods and events to the cache.
 // Merging new data with existing overlapping data.
 func (c *Cache) Save(newCacheEvents Cache) error {
-	if c == nil {
-		c = &Cache{
-			Period: []Period{},
-			Event:  []types.Event{},
+	regions := map[string][]Period{}
+	for region, periods := range c.Regions {
+		regions[region] = append(regions[region], periods...)
+	}
+	for region, periods := range newCacheEvents.Regions {
+		regions[region] = append(regions[region], periods...)
+	}
+	for region, periods := range regions {
+		sort.Sort(Periods(periods))
+		regions[region] = Merge(periods)
+	}
+	c.Regions = regions
+	c.Period = unionPeriods(regions)
+
+	if c.Buckets == nil {
+		c.Buckets = map[string]int{}
+	}
+
+	newEvents := map[string][]CachedEvent{}
+	for _, event := range newCacheEvents.Event {
+		if event.EventTime == nil {
+			continue
 		}
+		day := bucketName(*event.EventTime)
+		newEvents[day] = append(newEvents[day], event)
 	}
 
-	var allPeriods []Period
-	allPeriods = append(allPeriods, c.Period...)
-	allPeriods = append(allPeriods, newCacheEvents.Period...)
-	sort.Sort(Periods(allPeriods))
-	newPeriods := Merge(allPeriods)
+	for day, events := range newEvents {
+		existing, err := c.readBucket(day)
+		if err != nil {
+			return err
+		}
+		merged := mergeCachedEvents(append(existing, events...))
+		if err := c.writeBucket(day, merged); err != nil {
+			return err
+		}
+		c.Buckets[day] = len(merged)
+	}
 
-	var allEvents []types.Event
-	allEvents = append(allEvents, c.Event...)
-	allEvents = append(allEvents, newCacheEvents.Event...)
-	sort.Slice(allEvents, func(i, j int) bool {
-		if allEvents[i].EventTime == nil {
-			return false
+	return c.writeIndex()
+}
+
+// Size returns the size on disk of the cache in bytes.
+func (c *Cache) Size() (int64, error) {
+	var size int64
+	entries, err := os.ReadDir(c.dir)
+	if err != nil {
+		return 0, err
+	}
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil {
+			return 0, err
 		}
-		if allEvents[j].EventTime == nil {
-			return true
+		size += info.Size()
+	}
+	return size, nil
+}
+
+// BucketSize returns the size on disk of a daily bucket in bytes.
+func (c *Cache) BucketSize(day string) int64 {
+	info, err := os.Stat(c.bucketFilename(day))
+	if err != nil {
+		return 0
+	}
+	return info.Size()
+}
+
+// Days returns the days that have a bucket in the cache, oldest first.
+func (c *Cache) Days() []string {
+	days := make([]string, 0, len(c.Buckets))
+	for day := range c.Buckets {
+		days = append(days, day)
+	}
+	sort.Strings(days)
+	return days
+}
+
+// Clear removes the cache of the cluster.
+func (c *Cache) Clear() error {
+	c.Period = []Period{}
+	c.Regions = map[string][]Period{}
+	c.Buckets = map[string]int{}
+	return os.RemoveAll(c.dir)
+}
+
+// EvictBefore removes the buckets of all days before the given time, and shrinks the
+// cached periods accordingly so that the evicted events are retrieved again when needed.
+// It returns the number of evicted events.
+func (c *Cache) EvictBefore(before time.Time) (int, error) {
+	cutoff := before.UTC().Truncate(24 * time.Hour)
+	cutoffDay := bucketName(cutoff)
+
+	evicted := 0
+	for _, day := range c.Days() {
+		if day >= cutoffDay {
+			break
+		}
+		if err := os.Remove(c.bucketFilename(day)); err != nil && !os.IsNotExist(err) {
+			return evicted, err
+		}
+		evicted += c.Buckets[day]
+		delete(c.Buckets, day)
+	}
+
+	for region, periods := range c.Regions {
+		clipped := clipPeriods(periods, cutoff)
+		if len(clipped) == 0 {
+			delete(c.Regions, region)
+			continue
+		}
+		c.Regions[region] = clipped
+	}
+	c.Period = unionPeriods(c.Regions)
+
+	return evicted, c.writeIndex()
+}
+
+// ListCaches returns the caches of all clusters found in the cache directory. Caches of the legacy
+// layout are migrated if migrate is set. Otherwise nothing is written and the cluster IDs of the
+// legacy caches are returned instead.
+func ListCaches(log *logrus.Logger, migrate bool) ([]*Cache, []string, error) {
+	cacheDir, err := CacheDir()
+	if err != nil {
+		return nil, nil, err
+	}
+	entries, err := os.ReadDir(cacheDir)
+	if os.IsNotExist(err) {
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var caches []*Cache
+	var legacyIDs []string
+	for _, entry := range entries {
+		var dir string
+		switch {
+		case entry.IsDir():
+			dir = filepath.Join(cacheDir, entry.Name())
+		case strings.HasSuffix(entry.Name(), ".json"):
+			dir = filepath.Join(cacheDir, strings.TrimSuffix(entry.Name(), ".json"))
+			if _, err := os.Stat(dir); err == nil {
+				continue
+			}
+		default:
+			continue
 		}
-		return allEvents[j].EventTime.Before(*allEvents[i].EventTime)
-	})
 
-	cache := Cache{
-		log:      c.log,
-		filename: c.filename,
-		Period:   newPeriods,
-		Event:    allEvents,
+		cache := newCacheInDir(log, dir)
+		if migrate {
+			if err := cache.EnsureFilenameExist(); err != nil {
+				return nil, nil, err
+			}
+		} else if _, err := os.Stat(filepath.Join(dir, cacheIndexFile)); os.IsNotExist(err) {
+			if _, err := os.Stat(dir + ".json"); err == nil {
+				legacyIDs = append(legacyIDs, cache.ClusterID())
+			}
+			continue
+		}
+		if err := cache.Read(); err != nil {
+			return nil, nil, err
+		}
+		caches = append(caches, cache)
 	}
+	return caches, legacyIDs, nil
+}
 
-	data, err := json.MarshalIndent(cache, "", "  ")
+func (c *Cache) writeIndex() error {
+	data, err := json.MarshalIndent(c, "", "  ")
 	if err != nil {
-		c.log.Errorf("failed to marshal cache: %v", err)
+		c.log.Errorf("failed to marshal cache index: %v", err)
 		return err
 	}
-	if err := os.WriteFile(c.filename, data, 0600); err != nil {
-		c.log.Errorf("failed to write to cache file: %v", err)
+	if err := os.WriteFile(filepath.Join(c.dir, cacheIndexFile), data, 0600); err != nil {
+		c.log.Errorf("failed to write cache index: %v", err)
 		return err
 	}
-
 	return nil
 }
 
+func (c *Cache) bucketFilename(day string) string {
+	return filepath.Join(c.dir, day+cacheBucketSuffix)
+}
+
+func (c *Cache) readBucket(day string) ([]CachedEvent, error) {
+	file, err := os.Open(c.bucketFilename(day))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	reader, err := gzip.NewReader(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cache bucket %s: %w", day, err)
+	}
+	defer reader.Close()
+
+	var events []CachedEvent
+	if err := json.NewDecoder(reader).Decode(&events); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal cache bucket %s: %w", day, err)
+	}
+	return events, nil
+}
+
+func (c *Cache) writeBucket(day string, events []CachedEvent) error {
+	tmpFilename := c.bucketFilename(day) + ".tmp"
+	file, err := os.OpenFile(tmpFilename, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+
+	writer := gzip.NewWriter(file)
+	err = errors.Join(json.NewEncoder(writer).Encode(events), writer.Close(), file.Close())
+	if err != nil {
+		_ = os.Remove(tmpFilename)
+		return fmt.Errorf("failed to write cache bucket %s: %w", day, err)
+	}
+	return os.Rename(tmpFilename, c.bucketFilename(day))
+}
+
+func bucketName(t time.Time) string {
+	return t.UTC().Format(cacheBucketLayout)
+}
+
+// mergeCachedEvents removes duplicated events and sorts them by event time, newest first.
+func mergeCachedEvents(events []CachedEvent) []CachedEvent {
+	var merged []CachedEvent
+	seen := map[string]struct{}{}
+	for _, event := range events {
+		if event.EventId != nil {
+			if _, ok := seen[*event.EventId]; ok {
+				continue
+			}
+			seen[*event.EventId] = struct{}{}
+		}
+		merged = append(merged, event)
+	}
+	sort.SliceStable(merged, func(i, j int) bool {
+		return merged[j].EventTime.Before(*merged[i].EventTime)
+	})
+	return merged
+}
+
+// unionPeriods merges the periods of all regions.
+func unionPeriods(regions map[string][]Period) []Period {
+	allPeriods := []Period{}
+	for _, periods := range regions {
+		allPeriods = append(allPeriods, periods...)
+	}
+	sort.Sort(Periods(allPeriods))
+	return Merge(allPeriods)
+}
+
+// clipPeriods drops the parts of the periods before start.
+func clipPeriods(periods []Period, start time.Time) []Period {
+	var clipped []Period
+	for _, period := range periods {
+		if period.EndTime.Before(start) {
+			continue
+		}
+		if period.StartTime.Before(start) {
+			period.StartTime = start
+		}
+		clipped = append(clipped, period)
+	}
+	return clipped
+}
+
 // DiffMultiple takes the requested time range and compares it to the time period in the cache.
 // If it overlaps, it will be added to the list and returned to the user.
 func (p Period) DiffMultiple(c []Period) ([]Period, bool) {
@@ -195,16 +500,26 @@ func (p Period) periodOverlap(missingPeriods []Period) bool {
 	return true
 }
 
-func (c *Cache) FilterByPeriod(requestedPeriod Period) []types.Event {
-	var eventsInCache []types.Event
-	for _, period := range c.Period {
-		if period.Overlap(requestedPeriod) {
-			for _, event := range c.Event {
-				if event.EventTime != nil {
-					eventTime := *event.EventTime
-					if !eventTime.Before(requestedPeriod.StartTime) && !eventTime.After(requestedPeriod.EndTime) {
-						eventsInCache = append(eventsInCache, event)
-					}
+// FilterByPeriod returns the cached events of all regions that occurred within the requested period.
+// Only the buckets of the days overlapping the period are read.
+func (c *Cache) FilterByPeriod(requestedPeriod Period) []CachedEvent {
+	var eventsInCache []CachedEvent
+	firstDay := bucketName(requestedPeriod.StartTime)
+	lastDay := bucketName(requestedPeriod.EndTime)
+	for _, day := range c.Days() {
+		if day < firstDay || day > lastDay {
+			continue
+		}
+		events, err := c.readBucket(day)
+		if err != nil {
+			c.log.Errorf("%v", err)
+			continue
+		}
+		for _, event := range events {
+			if event.EventTime != nil {
+				eventTime := *event.EventTime
+				if !eventTime.Before(requestedPeriod.StartTime) && !eventTime.After(requestedPeriod.EndTime) {
+					eventsInCache = append(eventsInCache, event)
 				}
 			}
 		}
@@ -212,15 +527,12 @@ func (c *Cache) FilterByPeriod(requestedPeriod Period) []types.Event {
 	return eventsInCache
 }
 
-func FilterByRegion(region string, events []types.Event) []types.Event {
+// FilterByRegion returns the events that were retrieved from the given region.
+func FilterByRegion(region string, events []CachedEvent) []types.Event {
 	var filtered []types.Event
 	for _, event := range events {
-		rawEventDetails, err := ExtractUserDetails(event.CloudTrailEvent)
-		if err != nil {
-			continue
-		}
-		if rawEventDetails.EventRegion == region {
-			filtered = append(filtered, event)
+		if event.Region == region {
+			filtered = append(filtered, event.Event)
 		}
 	}
 	return filtered
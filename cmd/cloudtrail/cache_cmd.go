@@ -0,0 +1,378 @@
+package cloudtrail
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/openshift/osdctl/pkg/printer"
+	"github.com/openshift/osdctl/pkg/utils"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+const (
+	// CacheMaxAgeKey is the osdctl config key of the maximum age of cached events, i.e. "720h".
+	CacheMaxAgeKey = "cloudtrail_cache_max_age"
+	// CacheMaxSizeKey is the osdctl config key of the maximum size of the cache of all clusters, i.e. "500Mi".
+	CacheMaxSizeKey = "cloudtrail_cache_max_size"
+)
+
+const cloudtrailCacheExample = `
+    # List the cached clusters
+    $ osdctl cloudtrail cache list
+
+    # Show the cached periods and daily buckets of a cluster
+    $ osdctl cloudtrail cache show -C cluster-id
+
+    # Evict events older than 30 days and keep the cache below 500MiB
+    $ osdctl cloudtrail cache prune --older-than 720h --max-size 500Mi
+
+    # Remove the cache of a cluster
+    $ osdctl cloudtrail cache clear -C cluster-id`
+
+type cacheOptions struct {
+	clusterID string
+	olderThan string
+	maxSize   string
+	all       bool
+
+	log *logrus.Logger
+}
+
+func newCmdCache() *cobra.Command {
+	ops := &cacheOptions{log: logrus.New()}
+
+	cacheCmd := &cobra.Command{
+		Use:   "cache",
+		Short: "Manage the local cache of cloudtrail write-events",
+		Long: fmt.Sprintf(`Manage the local cache of cloudtrail write-events.
+
+Events are cached per cluster in daily compressed buckets. Eviction policies can be
+applied with 'prune', or automatically after each write-events run by setting
+'%s' and/or '%s' in the osdctl config file.`, CacheMaxAgeKey, CacheMaxSizeKey),
+		Example: cloudtrailCacheExample,
+		Args:    cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			cmd.Help()
+		},
+	}
+
+	listCmd := &cobra.Command{
+		Use:   "list",
+		Short: "List the cached clusters",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return ops.list()
+		},
+	}
+
+	showCmd := &cobra.Command{
+		Use:   "show",
+		Short: "Show the cached periods and daily buckets of a cluster",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return ops.show()
+		},
+	}
+	showCmd.Flags().StringVarP(&ops.clusterID, "cluster-id", "C", "", "Cluster ID")
+	_ = showCmd.MarkFlagRequired("cluster-id")
+
+	pruneCmd := &cobra.Command{
+		Use:   "prune",
+		Short: "Evict cached events by age and/or total cache size",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return ops.prune()
+		},
+	}
+	pruneCmd.Flags().StringVarP(&ops.clusterID, "cluster-id", "C", "", "Only prune the cache of this cluster")
+	pruneCmd.Flags().StringVar(&ops.olderThan, "older-than", "", fmt.Sprintf("Evict events older than this duration, i.e. 720h. Defaults to '%s' from the osdctl config", CacheMaxAgeKey))
+	pruneCmd.Flags().StringVar(&ops.maxSize, "max-size", "", fmt.Sprintf("Evict the oldest events until the cache is below this size, i.e. 500Mi. Defaults to '%s' from the osdctl config", CacheMaxSizeKey))
+
+	clearCmd := &cobra.Command{
+		Use:   "clear",
+		Short: "Remove the cache of a cluster or of all clusters",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return ops.clear()
+		},
+	}
+	clearCmd.Flags().StringVarP(&ops.clusterID, "cluster-id", "C", "", "Cluster ID")
+	clearCmd.Flags().BoolVar(&ops.all, "all", false, "Remove the cache of all clusters")
+	clearCmd.MarkFlagsMutuallyExclusive("cluster-id", "all")
+	clearCmd.MarkFlagsOneRequired("cluster-id", "all")
+
+	cacheCmd.AddCommand(listCmd, showCmd, pruneCmd, clearCmd)
+
+	return cacheCmd
+}
+
+func (o *cacheOptions) list() error {
+	caches, legacyIDs, err := ListCaches(o.log, false)
+	if err != nil {
+		return err
+	}
+
+	table := printer.NewTablePrinter(os.Stdout, 20, 1, 3, ' ')
+	table.AddRow([]string{"CLUSTER ID", "EVENTS", "SIZE", "OLDEST DAY", "NEWEST DAY", "REGIONS"})
+	for _, cache := range caches {
+		size, err := cache.Size()
+		if err != nil {
+			return err
+		}
+		events := 0
+		for _, count := range cache.Buckets {
+			events += count
+		}
+		var oldest, newest string
+		if days := cache.Days(); len(days) > 0 {
+			oldest, newest = days[0], days[len(days)-1]
+		}
+		regions := make([]string, 0, len(cache.Regions))
+		for region := range cache.Regions {
+			regions = append(regions, region)
+		}
+		sort.Strings(regions)
+
+		table.AddRow([]string{cache.ClusterID(), strconv.Itoa(events), utils.FormatBytes(size), oldest, newest, strings.Join(regions, ",")})
+	}
+	if err := table.Flush(); err != nil {
+		return err
+	}
+
+	if len(legacyIDs) > 0 {
+		fmt.Printf("\nCaches of a previous osdctl version, migrated when next used: %s\n", strings.Join(legacyIDs, ", "))
+	}
+	return nil
+}
+
+func (o *cacheOptions) show() error {
+	cache, err := o.clusterCache()
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("Cached periods:")
+	table := printer.NewTablePrinter(os.Stdout, 20, 1, 3, ' ')
+	table.AddRow([]string{"REGION", "START", "END"})
+	regions := make([]string, 0, len(cache.Regions))
+	for region := range cache.Regions {
+		regions = append(regions, region)
+	}
+	sort.Strings(regions)
+	for _, region := range regions {
+		for _, period := range cache.Regions[region] {
+			table.AddRow([]string{region, period.StartTime.Format(time.RFC3339), period.EndTime.Format(time.RFC3339)})
+		}
+	}
+	if err := table.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Println("\nDaily buckets:")
+	table = printer.NewTablePrinter(os.Stdout, 20, 1, 3, ' ')
+	table.AddRow([]string{"DAY", "EVENTS", "SIZE"})
+	for _, day := range cache.Days() {
+		table.AddRow([]string{day, strconv.Itoa(cache.Buckets[day]), utils.FormatBytes(cache.BucketSize(day))})
+	}
+	return table.Flush()
+}
+
+func (o *cacheOptions) prune() error {
+	maxAge, maxSize, err := parseCacheLimits(o.olderThan, o.maxSize)
+	if err != nil {
+		return err
+	}
+	if maxAge == 0 && maxSize == 0 {
+		return fmt.Errorf("no eviction policy, set --older-than and/or --max-size")
+	}
+
+	var caches []*Cache
+	if o.clusterID != "" {
+		cache, err := o.clusterCache()
+		if err != nil {
+			return err
+		}
+		caches = []*Cache{cache}
+	} else {
+		caches, _, err = ListCaches(o.log, true)
+		if err != nil {
+			return err
+		}
+	}
+
+	evicted, err := PruneCaches(caches, maxAge, maxSize, time.Now().UTC())
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Evicted %d cached events\n", evicted)
+	return nil
+}
+
+func (o *cacheOptions) clear() error {
+	if !o.all {
+		cache, err := o.clusterCache()
+		if err != nil {
+			return err
+		}
+		if err := cache.Clear(); err != nil {
+			return err
+		}
+		fmt.Printf("Removed the cache of cluster %s\n", o.clusterID)
+		return nil
+	}
+
+	cacheDir, err := CacheDir()
+	if err != nil {
+		return err
+	}
+	fmt.Printf("This will remove the cloudtrail cache of all clusters in %s\n", cacheDir)
+	if !utils.ConfirmPrompt() {
+		return nil
+	}
+	return os.RemoveAll(cacheDir)
+}
+
+// clusterCache returns the existing cache of the cluster given by --cluster-id.
+func (o *cacheOptions) clusterCache() (*Cache, error) {
+	cache, err := NewCache(o.log, o.clusterID)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat(cache.dir); os.IsNotExist(err) {
+		if _, err := os.Stat(cache.dir + ".json"); os.IsNotExist(err) {
+			return nil, fmt.Errorf("no cache found for cluster %s", o.clusterID)
+		}
+	}
+	if err := cache.EnsureFilenameExist(); err != nil {
+		return nil, err
+	}
+	if err := cache.Read(); err != nil {
+		return nil, err
+	}
+	return cache, nil
+}
+
+// parseCacheLimits parses the eviction policies, falling back to the osdctl config
+// for the ones that are not set. A zero value disables the policy.
+func parseCacheLimits(olderThan, maxSize string) (time.Duration, int64, error) {
+	if olderThan == "" {
+		olderThan = viper.GetString(CacheMaxAgeKey)
+	}
+	if maxSize == "" {
+		maxSize = viper.GetString(CacheMaxSizeKey)
+	}
+
+	var maxAge time.Duration
+	if olderThan != "" {
+		var err error
+		maxAge, err = time.ParseDuration(olderThan)
+		if err != nil {
+			return 0, 0, fmt.Errorf("invalid maximum cache age %q: %w", olderThan, err)
+		}
+	}
+
+	var maxBytes int64
+	if maxSize != "" {
+		quantity, err := resource.ParseQuantity(maxSize)
+		if err != nil {
+			return 0, 0, fmt.Errorf("invalid maximum cache size %q: %w", maxSize, err)
+		}
+		maxBytes = quantity.Value()
+	}
+
+	return maxAge, maxBytes, nil
+}
+
+// PruneCaches applies the eviction policies to the given caches. Events older than
+// maxAge are evicted first, then the oldest daily buckets of all caches are evicted
+// until their total size is below maxSize. A zero value disables the policy.
+// It returns the number of evicted events.
+func PruneCaches(caches []*Cache, maxAge time.Duration, maxSize int64, now time.Time) (int, error) {
+	evicted := 0
+
+	if maxAge > 0 {
+		for _, cache := range caches {
+			n, err := cache.EvictBefore(now.Add(-maxAge))
+			if err != nil {
+				return evicted, err
+			}
+			evicted += n
+		}
+	}
+
+	if maxSize <= 0 {
+		return evicted, nil
+	}
+
+	sizes := make([]int64, len(caches))
+	var total int64
+	for i, cache := range caches {
+		size, err := cache.Size()
+		if err != nil {
+			return evicted, err
+		}
+		sizes[i] = size
+		total += size
+	}
+
+	for total > maxSize {
+		oldest := -1
+		var oldestDay string
+		for i, cache := range caches {
+			if days := cache.Days(); len(days) > 0 && (oldest == -1 || days[0] < oldestDay) {
+				oldest, oldestDay = i, days[0]
+			}
+		}
+		if oldest == -1 {
+			break
+		}
+
+		day, err := time.Parse(cacheBucketLayout, oldestDay)
+		if err != nil {
+			return evicted, err
+		}
+		n, err := caches[oldest].EvictBefore(day.Add(24 * time.Hour))
+		if err != nil {
+			return evicted, err
+		}
+		evicted += n
+
+		size, err := caches[oldest].Size()
+		if err != nil {
+			return evicted, err
+		}
+		total -= sizes[oldest] - size
+		sizes[oldest] = size
+	}
+
+	return evicted, nil
+}
+
+// enforceCacheLimits applies the eviction policies configured in the osdctl config, if any.
+func enforceCacheLimits(log *logrus.Logger) error {
+	maxAge, maxSize, err := parseCacheLimits("", "")
+	if err != nil {
+		return err
+	}
+	if maxAge == 0 && maxSize == 0 {
+		return nil
+	}
+
+	caches, _, err := ListCaches(log, true)
+	if err != nil {
+		return err
+	}
+	evicted, err := PruneCaches(caches, maxAge, maxSize, time.Now().UTC())
+	if err != nil {
+		return err
+	}
+	log.Debugf("Evicted %d cached events", evicted)
+	return nil
+}
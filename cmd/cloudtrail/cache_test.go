@@ -0,0 +1,158 @@
+package cloudtrail
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestCache(t *testing.T, dir string) *Cache {
+	cache := newCacheInDir(logrus.New(), dir)
+	assert.NoError(t, cache.EnsureFilenameExist())
+	assert.NoError(t, cache.Read())
+	return cache
+}
+
+func TestCacheSaveBucketsByDay(t *testing.T) {
+	cache := newTestCache(t, filepath.Join(t.TempDir(), "cluster"))
+	day1 := time.Date(2025, 7, 14, 23, 0, 0, 0, time.UTC)
+	day2 := time.Date(2025, 7, 15, 1, 0, 0, 0, time.UTC)
+
+	assert.NoError(t, cache.Save(Cache{
+		Regions: map[string][]Period{"us-east-1": {{StartTime: day1.Add(-time.Hour), EndTime: day2.Add(time.Hour)}}},
+		Event: []CachedEvent{
+			{Region: "us-east-1", Event: eventAt("a", "us-east-1", day1)},
+			{Region: "us-east-1", Event: eventAt("b", "us-east-1", day2)},
+			{Region: "us-east-1", Event: eventAt("b", "us-east-1", day2)},
+		},
+	}))
+
+	assert.Equal(t, []string{"2025-07-14", "2025-07-15"}, cache.Days())
+	assert.Equal(t, 1, cache.Buckets["2025-07-15"])
+	assert.FileExists(t, filepath.Join(cache.dir, "2025-07-15"+cacheBucketSuffix))
+
+	// Only the bucket of the requested day is needed to serve the period.
+	assert.NoError(t, os.Remove(filepath.Join(cache.dir, "2025-07-14"+cacheBucketSuffix)))
+	events := cache.FilterByPeriod(Period{StartTime: day2.Add(-time.Minute), EndTime: day2.Add(time.Minute)})
+	assert.Len(t, events, 1)
+	assert.Equal(t, "b", *events[0].EventId)
+}
+
+func TestCacheMigratesLegacyFile(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "cluster")
+	legacy := `{"Period":[{"StartTime":"2025-07-15T10:00:00Z","EndTime":"2025-07-15T12:00:00Z"}],` +
+		`"Event":[{"EventId":"a","EventTime":"2025-07-15T11:00:00Z","CloudTrailEvent":"{\"eventVersion\":\"1.08\",\"awsRegion\":\"us-east-2\"}"}]}`
+	assert.NoError(t, os.WriteFile(dir+".json", []byte(legacy), 0600))
+
+	cache := newTestCache(t, dir)
+	assert.NoFileExists(t, dir+".json")
+	assert.Empty(t, cache.Period)
+
+	events := cache.FilterByPeriod(Period{StartTime: time.Date(2025, 7, 15, 0, 0, 0, 0, time.UTC), EndTime: time.Date(2025, 7, 16, 0, 0, 0, 0, time.UTC)})
+	assert.Len(t, events, 1)
+	assert.Equal(t, "us-east-2", events[0].Region)
+}
+
+func TestListCachesReadOnly(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	cacheDir, err := CacheDir()
+	assert.NoError(t, err)
+	newTestCache(t, filepath.Join(cacheDir, "current"))
+	legacy := `{"Period":[],"Event":[]}`
+	assert.NoError(t, os.WriteFile(filepath.Join(cacheDir, "legacy.json"), []byte(legacy), 0600))
+
+	caches, legacyIDs, err := ListCaches(logrus.New(), false)
+	assert.NoError(t, err)
+	assert.Len(t, caches, 1)
+	assert.Equal(t, "current", caches[0].ClusterID())
+	assert.Equal(t, []string{"legacy"}, legacyIDs)
+	assert.FileExists(t, filepath.Join(cacheDir, "legacy.json"))
+	assert.NoDirExists(t, filepath.Join(cacheDir, "legacy"))
+
+	caches, legacyIDs, err = ListCaches(logrus.New(), true)
+	assert.NoError(t, err)
+	assert.Len(t, caches, 2)
+	assert.Empty(t, legacyIDs)
+	assert.NoFileExists(t, filepath.Join(cacheDir, "legacy.json"))
+}
+
+func TestCacheEvictBefore(t *testing.T) {
+	cache := newTestCache(t, filepath.Join(t.TempDir(), "cluster"))
+	start := time.Date(2025, 7, 10, 12, 0, 0, 0, time.UTC)
+	end := time.Date(2025, 7, 15, 12, 0, 0, 0, time.UTC)
+
+	assert.NoError(t, cache.Save(Cache{
+		Regions: map[string][]Period{"us-east-1": {{StartTime: start, EndTime: end}}},
+		Event: []CachedEvent{
+			{Region: "us-east-1", Event: eventAt("old", "us-east-1", start.Add(time.Hour))},
+			{Region: "us-east-1", Event: eventAt("new", "us-east-1", end.Add(-time.Hour))},
+		},
+	}))
+
+	evicted, err := cache.EvictBefore(time.Date(2025, 7, 12, 8, 0, 0, 0, time.UTC))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, evicted)
+	assert.Equal(t, []string{"2025-07-15"}, cache.Days())
+	assert.Equal(t, []Period{{StartTime: time.Date(2025, 7, 12, 0, 0, 0, 0, time.UTC), EndTime: end}}, cache.Regions["us-east-1"])
+
+	reloaded := newTestCache(t, cache.dir)
+	assert.Equal(t, cache.Regions, reloaded.Regions)
+}
+
+func TestPruneCachesBySize(t *testing.T) {
+	root := t.TempDir()
+	first := newTestCache(t, filepath.Join(root, "first"))
+	second := newTestCache(t, filepath.Join(root, "second"))
+	day := time.Date(2025, 7, 10, 12, 0, 0, 0, time.UTC)
+
+	assert.NoError(t, first.Save(Cache{Event: []CachedEvent{
+		{Region: "us-east-1", Event: eventAt("a", "us-east-1", day)},
+		{Region: "us-east-1", Event: eventAt("c", "us-east-1", day.Add(48*time.Hour))},
+	}}))
+	assert.NoError(t, second.Save(Cache{Event: []CachedEvent{
+		{Region: "us-east-1", Event: eventAt("b", "us-east-1", day.Add(24*time.Hour))},
+	}}))
+
+	firstSize, err := first.Size()
+	assert.NoError(t, err)
+	secondSize, err := second.Size()
+	assert.NoError(t, err)
+
+	// Evicting the single oldest bucket is enough to get below the limit.
+	evicted, err := PruneCaches([]*Cache{first, second}, 0, firstSize+secondSize-1, day)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, evicted)
+	assert.Equal(t, []string{"2025-07-12"}, first.Days())
+	assert.Equal(t, []string{"2025-07-11"}, second.Days())
+}
+
+func TestPruneCachesByAge(t *testing.T) {
+	cache := newTestCache(t, filepath.Join(t.TempDir(), "cluster"))
+	now := time.Date(2025, 7, 20, 12, 0, 0, 0, time.UTC)
+
+	assert.NoError(t, cache.Save(Cache{Event: []CachedEvent{
+		{Region: "us-east-1", Event: eventAt("old", "us-east-1", now.Add(-72*time.Hour))},
+		{Region: "us-east-1", Event: eventAt("new", "us-east-1", now.Add(-time.Hour))},
+	}}))
+
+	evicted, err := PruneCaches([]*Cache{cache}, 48*time.Hour, 0, now)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, evicted)
+	assert.Equal(t, []string{"2025-07-20"}, cache.Days())
+}
+
+func TestParseCacheLimits(t *testing.T) {
+	maxAge, maxSize, err := parseCacheLimits("720h", "500Mi")
+	assert.NoError(t, err)
+	assert.Equal(t, 720*time.Hour, maxAge)
+	assert.Equal(t, int64(500*1024*1024), maxSize)
+
+	_, _, err = parseCacheLimits("a month", "")
+	assert.Error(t, err)
+	_, _, err = parseCacheLimits("", "lots")
+	assert.Error(t, err)
+}
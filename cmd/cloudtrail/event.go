@@ -23,9 +23,10 @@ type RawEventDetails struct {
 			} `json:"sessionIssuer"`
 		} `json:"sessionContext"`
 	} `json:"userIdentity"`
-	EventRegion string `json:"awsRegion"`
-	EventId     string `json:"eventID"`
-	ErrorCode   string `json:"errorCode"`
+	EventRegion     string `json:"awsRegion"`
+	EventId         string `json:"eventID"`
+	ErrorCode       string `json:"errorCode"`
+	SourceIPAddress string `json:"sourceIPAddress"`
 }
 
 type EventResult struct {
@@ -33,9 +34,16 @@ type EventResult struct {
 	errors   error
 }
 
+// EventGetter retrieves the CloudTrail events of a single region for a period.
+type EventGetter interface {
+	GetEvents(clusterID string, missing Period) <-chan EventResult
+	Region() string
+}
+
 type EventAPI struct {
 	client    *cloudtrail.Client
 	writeOnly bool
+	region    string
 }
 
 func NewEventAPI(cfg aws.Config, writeOnly bool, region string) *EventAPI {
@@ -51,12 +59,22 @@ func NewEventAPI(cfg aws.Config, writeOnly bool, region string) *EventAPI {
 		client = cloudtrail.NewFromConfig(cfg)
 	}
 
+	if region == "" {
+		region = cfg.Region
+	}
+
 	return &EventAPI{
 		client:    client,
 		writeOnly: writeOnly,
+		region:    region,
 	}
 }
 
+// Region returns the region the EventAPI looks up events in.
+func (a *EventAPI) Region() string {
+	return a.region
+}
+
 func (a *EventAPI) GetEvents(_ string, missing Period) <-chan EventResult {
 	var alllookupEvents []types.Event
 
@@ -85,6 +103,7 @@ func (a *EventAPI) GetEvents(_ string, missing Period) <-chan EventResult {
 					AWSEvent: nil,
 					errors:   err,
 				}
+				return
 			}
 			alllookupEvents = append(alllookupEvents, lookupOutput.Events...)
 
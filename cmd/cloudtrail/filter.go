@@ -17,8 +17,9 @@ type Filter func(types.Event) (bool, error)
 
 // WriteEventFilters defines the structure for filters used in write-events.go
 type WriteEventFilters struct {
-	Include []string
-	Exclude []string
+	Include    []string
+	Exclude    []string
+	Expression string
 }
 
 // ApplyFilters takes the filteredEvents slice and applies an additional filter function.
@@ -0,0 +1,415 @@
+package cloudtrail
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/aws/aws-sdk-go-v2/service/cloudtrail/types"
+)
+
+// Filter expressions combine field comparisons with boolean operators, i.e.
+//
+//	event like "Delete*" and resource-type == AWS::EC2::Instance and not username =~ ".*-Installer-Role"
+//
+// Comparisons are "<field> <operator> <value>" where the operator is one of
+// == (or =), !=, =~ (regular expression), !~ and like (glob with * and ?).
+// Comparisons can be combined with and (&&), or (||), not (!) and parentheses.
+// Values containing spaces or operator characters must be quoted.
+//
+// Fields are either one of the well known fields below, or a dotted path into the
+// raw CloudTrailEvent JSON such as requestParameters.instanceId. A comparison on a
+// field with multiple values (i.e. resources or JSON arrays) matches if any value matches.
+var expressionFields = map[string]func(event types.Event) []string{
+	"event": func(event types.Event) []string {
+		return stringValues(event.EventName)
+	},
+	"username": func(event types.Event) []string {
+		return stringValues(event.Username)
+	},
+	"event-source": func(event types.Event) []string {
+		return stringValues(event.EventSource)
+	},
+	"resource-name": func(event types.Event) []string {
+		var values []string
+		for _, resource := range event.Resources {
+			values = append(values, stringValues(resource.ResourceName)...)
+		}
+		return values
+	},
+	"resource-type": func(event types.Event) []string {
+		var values []string
+		for _, resource := range event.Resources {
+			values = append(values, stringValues(resource.ResourceType)...)
+		}
+		return values
+	},
+	"arn": func(event types.Event) []string {
+		return rawDetailValues(event, func(raw *RawEventDetails) string {
+			return raw.UserIdentity.SessionContext.SessionIssuer.UserName
+		})
+	},
+	"region": func(event types.Event) []string {
+		return rawDetailValues(event, func(raw *RawEventDetails) string { return raw.EventRegion })
+	},
+	"error-code": func(event types.Event) []string {
+		return rawDetailValues(event, func(raw *RawEventDetails) string { return raw.ErrorCode })
+	},
+	"source-ip": func(event types.Event) []string {
+		return rawDetailValues(event, func(raw *RawEventDetails) string { return raw.SourceIPAddress })
+	},
+}
+
+// ParseFilterExpression parses a filter expression into a Filter that can be used with ApplyFilters.
+func ParseFilterExpression(expression string) (Filter, error) {
+	tokens, err := tokenizeExpression(expression)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("empty filter expression")
+	}
+
+	p := &expressionParser{tokens: tokens}
+	node, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if !p.done() {
+		return nil, fmt.Errorf("unexpected %q in filter expression", p.peek().value)
+	}
+
+	return func(event types.Event) (bool, error) {
+		return node.eval(event), nil
+	}, nil
+}
+
+type tokenKind int
+
+const (
+	tokenWord tokenKind = iota
+	tokenString
+	tokenOperator
+	tokenLParen
+	tokenRParen
+)
+
+type token struct {
+	kind  tokenKind
+	value string
+}
+
+func tokenizeExpression(expression string) ([]token, error) {
+	var tokens []token
+	runes := []rune(expression)
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(':
+			tokens = append(tokens, token{kind: tokenLParen, value: "("})
+			i++
+		case r == ')':
+			tokens = append(tokens, token{kind: tokenRParen, value: ")"})
+			i++
+		case r == '"' || r == '\'':
+			var sb strings.Builder
+			j := i + 1
+			for ; j < len(runes) && runes[j] != r; j++ {
+				if runes[j] == '\\' && j+1 < len(runes) && (runes[j+1] == r || runes[j+1] == '\\') {
+					j++
+				}
+				sb.WriteRune(runes[j])
+			}
+			if j >= len(runes) {
+				return nil, fmt.Errorf("unterminated string in filter expression")
+			}
+			tokens = append(tokens, token{kind: tokenString, value: sb.String()})
+			i = j + 1
+		case strings.ContainsRune("=!&|", r):
+			op := string(r)
+			if i+1 < len(runes) {
+				if two := string(runes[i : i+2]); two == "==" || two == "!=" || two == "=~" || two == "!~" || two == "&&" || two == "||" {
+					op = two
+				}
+			}
+			if op == "&" || op == "|" {
+				return nil, fmt.Errorf("unexpected %q in filter expression, did you mean %q?", op, op+op)
+			}
+			tokens = append(tokens, token{kind: tokenOperator, value: op})
+			i += len(op)
+		default:
+			j := i
+			for ; j < len(runes) && !unicode.IsSpace(runes[j]) && !strings.ContainsRune("()=!&|\"'", runes[j]); j++ {
+			}
+			tokens = append(tokens, token{kind: tokenWord, value: string(runes[i:j])})
+			i = j
+		}
+	}
+
+	return tokens, nil
+}
+
+// expressionNode is a node of a parsed filter expression.
+type expressionNode interface {
+	eval(event types.Event) bool
+}
+
+type andNode struct{ left, right expressionNode }
+
+func (n andNode) eval(event types.Event) bool { return n.left.eval(event) && n.right.eval(event) }
+
+type orNode struct{ left, right expressionNode }
+
+func (n orNode) eval(event types.Event) bool { return n.left.eval(event) || n.right.eval(event) }
+
+type notNode struct{ node expressionNode }
+
+func (n notNode) eval(event types.Event) bool { return !n.node.eval(event) }
+
+type comparisonNode struct {
+	field  string
+	negate bool
+	match  func(value string) bool
+}
+
+func (n comparisonNode) eval(event types.Event) bool {
+	matched := false
+	for _, value := range fieldValues(event, n.field) {
+		if n.match(value) {
+			matched = true
+			break
+		}
+	}
+	return matched != n.negate
+}
+
+type expressionParser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *expressionParser) done() bool {
+	return p.pos >= len(p.tokens)
+}
+
+func (p *expressionParser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *expressionParser) next() token {
+	t := p.tokens[p.pos]
+	p.pos++
+	return t
+}
+
+// isKeyword reports whether the next token is one of the given operators or keywords.
+func (p *expressionParser) isKeyword(keywords ...string) bool {
+	if p.done() {
+		return false
+	}
+	t := p.peek()
+	if t.kind != tokenWord && t.kind != tokenOperator {
+		return false
+	}
+	for _, keyword := range keywords {
+		if strings.EqualFold(t.value, keyword) {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *expressionParser) parseOr() (expressionNode, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.isKeyword("or", "||") {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = orNode{left, right}
+	}
+	return left, nil
+}
+
+func (p *expressionParser) parseAnd() (expressionNode, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.isKeyword("and", "&&") {
+		p.next()
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = andNode{left, right}
+	}
+	return left, nil
+}
+
+func (p *expressionParser) parseUnary() (expressionNode, error) {
+	if p.done() {
+		return nil, fmt.Errorf("unexpected end of filter expression")
+	}
+	if p.isKeyword("not", "!") {
+		p.next()
+		node, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return notNode{node}, nil
+	}
+	if p.peek().kind == tokenLParen {
+		p.next()
+		node, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.done() || p.peek().kind != tokenRParen {
+			return nil, fmt.Errorf("missing closing parenthesis in filter expression")
+		}
+		p.next()
+		return node, nil
+	}
+	return p.parseComparison()
+}
+
+func (p *expressionParser) parseComparison() (expressionNode, error) {
+	field := p.next()
+	if field.kind != tokenWord {
+		return nil, fmt.Errorf("expected a field name in filter expression, got %q", field.value)
+	}
+	if _, ok := expressionFields[field.value]; !ok && !isRawField(field.value) {
+		return nil, fmt.Errorf("unknown filter field %q", field.value)
+	}
+
+	if !p.isKeyword("==", "=", "!=", "=~", "!~", "like") {
+		return nil, fmt.Errorf("expected an operator after %q in filter expression", field.value)
+	}
+	op := strings.ToLower(p.next().value)
+
+	if p.done() || (p.peek().kind != tokenWord && p.peek().kind != tokenString) {
+		return nil, fmt.Errorf("expected a value after %q %s in filter expression", field.value, op)
+	}
+	value := p.next().value
+
+	node := comparisonNode{field: field.value}
+	switch op {
+	case "==", "=", "!=":
+		node.negate = op == "!="
+		node.match = func(v string) bool { return v == value }
+	case "=~", "!~":
+		re, err := regexp.Compile(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid regular expression %q: %w", value, err)
+		}
+		node.negate = op == "!~"
+		node.match = re.MatchString
+	case "like":
+		re := globToRegexp(value)
+		node.match = re.MatchString
+	}
+	return node, nil
+}
+
+// rawEventFields are the top level fields of a CloudTrail record, see
+// https://docs.aws.amazon.com/awscloudtrail/latest/userguide/cloudtrail-event-reference-record-contents.html
+var rawEventFields = map[string]struct{}{
+	"eventTime": {}, "eventVersion": {}, "userIdentity": {}, "eventSource": {}, "eventName": {},
+	"awsRegion": {}, "sourceIPAddress": {}, "userAgent": {}, "errorCode": {}, "errorMessage": {},
+	"requestParameters": {}, "responseElements": {}, "additionalEventData": {}, "requestID": {},
+	"eventID": {}, "eventType": {}, "apiVersion": {}, "managementEvent": {}, "readOnly": {},
+	"resources": {}, "recipientAccountId": {}, "serviceEventDetails": {}, "sharedEventID": {},
+	"vpcEndpointId": {}, "eventCategory": {}, "addendum": {}, "sessionCredentialFromConsole": {},
+	"edgeDeviceDetails": {}, "tlsDetails": {},
+}
+
+// isRawField reports whether name is a path into the raw CloudTrail event.
+func isRawField(name string) bool {
+	_, ok := rawEventFields[strings.SplitN(name, ".", 2)[0]]
+	return ok
+}
+
+// globToRegexp converts a glob pattern with * and ? wildcards into an anchored regular expression.
+func globToRegexp(glob string) *regexp.Regexp {
+	pattern := regexp.QuoteMeta(glob)
+	pattern = strings.ReplaceAll(pattern, `\*`, ".*")
+	pattern = strings.ReplaceAll(pattern, `\?`, ".")
+	return regexp.MustCompile("^" + pattern + "$")
+}
+
+// fieldValues returns the values of a well known field, or of a dotted path in the raw event.
+func fieldValues(event types.Event, field string) []string {
+	if fn, ok := expressionFields[field]; ok {
+		return fn(event)
+	}
+
+	if event.CloudTrailEvent == nil {
+		return nil
+	}
+	var raw any
+	if err := json.Unmarshal([]byte(*event.CloudTrailEvent), &raw); err != nil {
+		return nil
+	}
+	return jsonPathValues(raw, strings.Split(field, "."))
+}
+
+// jsonPathValues walks path in a decoded JSON document. Arrays are traversed element wise.
+func jsonPathValues(node any, path []string) []string {
+	switch v := node.(type) {
+	case []any:
+		var values []string
+		for _, item := range v {
+			values = append(values, jsonPathValues(item, path)...)
+		}
+		return values
+	case map[string]any:
+		if len(path) == 0 {
+			data, _ := json.Marshal(v)
+			return []string{string(data)}
+		}
+		child, ok := v[path[0]]
+		if !ok {
+			return nil
+		}
+		return jsonPathValues(child, path[1:])
+	case nil:
+		return nil
+	default:
+		if len(path) > 0 {
+			return nil
+		}
+		if s, ok := v.(string); ok {
+			return []string{s}
+		}
+		data, _ := json.Marshal(v)
+		return []string{string(data)}
+	}
+}
+
+func stringValues(s *string) []string {
+	if s == nil {
+		return nil
+	}
+	return []string{*s}
+}
+
+func rawDetailValues(event types.Event, get func(raw *RawEventDetails) string) []string {
+	raw, err := ExtractUserDetails(event.CloudTrailEvent)
+	if err != nil {
+		return nil
+	}
+	if value := get(raw); value != "" {
+		return []string{value}
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+package cloudtrail
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudtrail/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func expressionTestEvent() types.Event {
+	eventTime := time.Date(2025, 7, 15, 9, 0, 0, 0, time.UTC)
+	raw := `{"eventVersion": "1.08",
+		"userIdentity": {"sessionContext": {"sessionIssuer": {"userName": "ManagedOpenShift-Installer-Role"}}},
+		"awsRegion": "us-east-2",
+		"requestParameters": {"instancesSet": {"items": [{"instanceId": "i-123"}, {"instanceId": "i-456"}]}, "force": true}}`
+	return types.Event{
+		EventName:       aws.String("TerminateInstances"),
+		EventTime:       &eventTime,
+		Username:        aws.String("john.doe"),
+		CloudTrailEvent: aws.String(raw),
+		Resources: []types.Resource{
+			{ResourceName: aws.String("i-123"), ResourceType: aws.String("AWS::EC2::Instance")},
+		},
+	}
+}
+
+func TestParseFilterExpression(t *testing.T) {
+	tests := []struct {
+		expression string
+		expected   bool
+	}{
+		{expression: `event == TerminateInstances`, expected: true},
+		{expression: `event = TerminateInstances`, expected: true},
+		{expression: `event != TerminateInstances`, expected: false},
+		{expression: `event like "Terminate*"`, expected: true},
+		{expression: `event like "Delete*"`, expected: false},
+		{expression: `username =~ "^john\\."`, expected: true},
+		{expression: `username !~ "^john"`, expected: false},
+		{expression: `resource-type == AWS::EC2::Instance and resource-name == i-123`, expected: true},
+		{expression: `arn like "*-Installer-Role" && region == us-east-2`, expected: true},
+		{expression: `not arn like "*-Installer-Role"`, expected: false},
+		{expression: `!(event == RunInstances || event == StopInstances)`, expected: true},
+		{expression: `event == RunInstances or (username == john.doe and region == us-east-2)`, expected: true},
+		{expression: `event == RunInstances or username == john.doe and region == eu-west-1`, expected: false},
+		{expression: `requestParameters.instancesSet.items.instanceId == i-456`, expected: true},
+		{expression: `requestParameters.force == true`, expected: true},
+		{expression: `requestParameters.missing == x`, expected: false},
+		{expression: `requestParameters.missing != x`, expected: true},
+		{expression: `awsRegion == 'us-east-2'`, expected: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expression, func(t *testing.T) {
+			filter, err := ParseFilterExpression(tt.expression)
+			assert.NoError(t, err)
+
+			keep, err := filter(expressionTestEvent())
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, keep)
+		})
+	}
+}
+
+func TestParseFilterExpressionErrors(t *testing.T) {
+	for _, expression := range []string{
+		``,
+		`event`,
+		`event ==`,
+		`unknown == x`,
+		`event == x and`,
+		`(event == x`,
+		`event == x)`,
+		`event == "x`,
+		`event =~ "("`,
+		`event == x & username == y`,
+	} {
+		t.Run(expression, func(t *testing.T) {
+			_, err := ParseFilterExpression(expression)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestFilterExpressionComposesWithApplyFilters(t *testing.T) {
+	other := expressionTestEvent()
+	other.EventName = aws.String("RunInstances")
+
+	filter, err := ParseFilterExpression(`event like "Terminate*"`)
+	assert.NoError(t, err)
+
+	filtered, err := ApplyFilters([]types.Event{expressionTestEvent(), other}, filter)
+	assert.NoError(t, err)
+	assert.Len(t, filtered, 1)
+	assert.Equal(t, "TerminateInstances", *filtered[0].EventName)
+}
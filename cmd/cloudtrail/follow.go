@@ -0,0 +1,127 @@
+package cloudtrail
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/cloudtrail/types"
+	"github.com/sirupsen/logrus"
+)
+
+// followLookback is how far back each poll looks for events. CloudTrail delivers
+// events to the lookup API with a delay of several minutes, so an event can show
+// up after a poll covering its event time already returned.
+const followLookback = 15 * time.Minute
+
+// seenEvents tracks the IDs of the events that were already retrieved in follow mode.
+type seenEvents map[string]time.Time
+
+// markNew records the given events and returns the ones that were not seen before,
+// oldest first.
+func (s seenEvents) markNew(events []types.Event) []types.Event {
+	var unseen []types.Event
+	for _, event := range events {
+		if event.EventId == nil {
+			continue
+		}
+		if _, ok := s[*event.EventId]; ok {
+			continue
+		}
+		var eventTime time.Time
+		if event.EventTime != nil {
+			eventTime = *event.EventTime
+		}
+		s[*event.EventId] = eventTime
+		unseen = append(unseen, event)
+	}
+	slices.SortStableFunc(unseen, func(a, b types.Event) int {
+		return eventTimeOf(a).Compare(eventTimeOf(b))
+	})
+	return unseen
+}
+
+// prune forgets the events older than before, they are outside of any future poll window.
+func (s seenEvents) prune(before time.Time) {
+	for id, eventTime := range s {
+		if eventTime.Before(before) {
+			delete(s, id)
+		}
+	}
+}
+
+// follower polls the lookup API for new events until its context is cancelled.
+type follower struct {
+	log      *logrus.Logger
+	apis     []EventGetter
+	cache    *Cache
+	interval time.Duration
+	seen     seenEvents
+	now      func() time.Time
+
+	// handle receives the new events of each poll, oldest first.
+	handle func(events []types.Event) error
+}
+
+// poll retrieves the events of the lookback window ending now and passes the
+// ones that were not seen before to handle. New events are saved into the cache.
+func (f *follower) poll() error {
+	end := f.now().UTC()
+	period := Period{StartTime: end.Add(-max(followLookback, f.interval)), EndTime: end}
+
+	events, newCacheData, err := LookupRegions(f.log, nil, "", f.apis, period)
+	if err != nil {
+		// Retried at the next poll, the lookback window covers the missed events
+		f.log.Warnf("%v", err)
+		return nil
+	}
+	unseen := f.seen.markNew(events)
+	f.seen.prune(period.StartTime)
+
+	if f.cache != nil && len(newCacheData.Regions) > 0 {
+		if err := f.cache.Save(newCacheData); err != nil {
+			f.log.Warnf("Failed to save events into the cache: %v", err)
+		}
+	}
+
+	if len(unseen) == 0 {
+		return nil
+	}
+	return f.handle(unseen)
+}
+
+// run polls every interval until ctx is cancelled.
+func (f *follower) run(ctx context.Context) error {
+	ticker := time.NewTicker(f.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			if err := f.poll(); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// validateFollow checks that the options can be combined with --follow.
+func validateFollow(output string, interval time.Duration) error {
+	if output == OutputJSON || output == OutputTable {
+		return fmt.Errorf("--follow cannot be used with --output %s, use text, jsonl or csv", output)
+	}
+	if interval <= 0 {
+		return fmt.Errorf("--interval must be positive")
+	}
+	return nil
+}
+
+func eventTimeOf(event types.Event) time.Time {
+	if event.EventTime == nil {
+		return time.Time{}
+	}
+	return *event.EventTime
+}
@@ -0,0 +1,68 @@
+package cloudtrail
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/cloudtrail/types"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFollowerPrintsOnlyNewEvents(t *testing.T) {
+	log := logrus.New()
+	now := time.Date(2025, 7, 15, 12, 0, 0, 0, time.UTC)
+
+	east := &fakeEventGetter{region: "us-east-1", events: []types.Event{eventAt("a", "us-east-1", now.Add(-5*time.Minute))}}
+	west := &fakeEventGetter{region: "eu-west-1"}
+
+	var printed []string
+	f := &follower{
+		log:      log,
+		apis:     []EventGetter{east, west},
+		cache:    newTestCache(t, filepath.Join(t.TempDir(), "cluster")),
+		interval: 30 * time.Second,
+		seen:     seenEvents{},
+		now:      func() time.Time { return now },
+		handle: func(events []types.Event) error {
+			for _, event := range events {
+				printed = append(printed, *event.EventId)
+			}
+			return nil
+		},
+	}
+	f.seen.markNew(east.events)
+
+	// A late delivered event and a new one show up, oldest first.
+	now = now.Add(time.Minute)
+	east.events = append(east.events, eventAt("c", "us-east-1", now.Add(-10*time.Second)))
+	west.events = append(west.events, eventAt("b", "eu-west-1", now.Add(-8*time.Minute)))
+	assert.NoError(t, f.poll())
+	assert.Equal(t, []string{"b", "c"}, printed)
+
+	// Nothing new, nothing printed.
+	now = now.Add(time.Minute)
+	assert.NoError(t, f.poll())
+	assert.Equal(t, []string{"b", "c"}, printed)
+
+	assert.Len(t, f.cache.FilterByPeriod(Period{StartTime: now.Add(-time.Hour), EndTime: now}), 3)
+	assert.Contains(t, f.cache.Regions, "eu-west-1")
+}
+
+func TestSeenEventsPrune(t *testing.T) {
+	now := time.Date(2025, 7, 15, 12, 0, 0, 0, time.UTC)
+	seen := seenEvents{}
+	seen.markNew([]types.Event{eventAt("old", "us-east-1", now.Add(-time.Hour)), eventAt("new", "us-east-1", now)})
+
+	seen.prune(now.Add(-followLookback))
+	assert.NotContains(t, seen, "old")
+	assert.Contains(t, seen, "new")
+}
+
+func TestValidateFollow(t *testing.T) {
+	assert.NoError(t, validateFollow(OutputJSONL, time.Minute))
+	assert.Error(t, validateFollow(OutputJSON, time.Minute))
+	assert.Error(t, validateFollow(OutputTable, time.Minute))
+	assert.Error(t, validateFollow(OutputText, 0))
+}
@@ -2,6 +2,7 @@ package cloudtrail
 
 import (
 	"fmt"
+	"os"
 	"regexp"
 	"strings"
 	"time"
@@ -18,6 +19,8 @@ type permissionDeniedEventsOptions struct {
 	StartTime string
 	PrintUrl  bool
 	PrintRaw  bool
+	Output    string
+	Analyze   bool
 }
 
 func newCmdPermissionDenied() *cobra.Command {
@@ -26,6 +29,18 @@ func newCmdPermissionDenied() *cobra.Command {
 	permissionDeniedCmd := &cobra.Command{
 		Use:   "permission-denied-events",
 		Short: "Prints cloudtrail permission-denied events to console.",
+		Long: `Prints cloudtrail permission-denied events to console.
+
+With --analyze, the denied events are aggregated by principal and action and each action
+is cross-referenced with the policies of the CredentialsRequests of the cluster's release,
+to report which operator role should have been granted the permission.
+Extracting the CredentialsRequests requires 'oc' and access to the release image.`,
+		Example: `
+    # List the permission-denied events of the last hour
+    $ osdctl cloudtrail permission-denied-events -C cluster-id --since 1h
+
+    # Report which operator roles are missing the denied permissions
+    $ osdctl cloudtrail permission-denied-events -C cluster-id --since 1h --analyze`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return opts.run()
 		},
@@ -34,6 +49,8 @@ func newCmdPermissionDenied() *cobra.Command {
 	permissionDeniedCmd.Flags().StringVarP(&opts.StartTime, "since", "", "5m", "Specifies that only events that occur within the specified time are returned.Defaults to 5m. Valid time units are \"ns\", \"us\" (or \"µs\"), \"ms\", \"s\", \"m\", \"h\".")
 	permissionDeniedCmd.Flags().BoolVarP(&opts.PrintUrl, "url", "u", false, "Generates Url link to cloud console cloudtrail event")
 	permissionDeniedCmd.Flags().BoolVarP(&opts.PrintRaw, "raw-event", "r", false, "Prints the cloudtrail events to the console in raw json format")
+	permissionDeniedCmd.Flags().StringVarP(&opts.Output, "output", "o", OutputText, "Output format. One of: text, json, jsonl, csv, table")
+	permissionDeniedCmd.Flags().BoolVar(&opts.Analyze, "analyze", false, "Aggregate the denied events by principal and action and report the operator role that should have the permission")
 	permissionDeniedCmd.MarkFlagRequired("cluster-id")
 	return permissionDeniedCmd
 }
@@ -62,6 +79,9 @@ func (p *permissionDeniedEventsOptions) run() error {
 	if err != nil {
 		return err
 	}
+	if err := ValidateOutput(p.Output); err != nil {
+		return err
+	}
 
 	connection, err := utils.CreateConnection()
 	if err != nil {
@@ -94,12 +114,13 @@ func (p *permissionDeniedEventsOptions) run() error {
 	}
 
 	awsAPI := NewEventAPI(cfg, false, cfg.Region)
-	printer := NewPrinter(p.PrintUrl, p.PrintRaw)
+	printer := NewPrinter(p.PrintUrl, p.PrintRaw, p.Output)
 	requestTime := Period{StartTime: startTime, EndTime: time.Now().UTC()}
 	generator := awsAPI.GetEvents(p.ClusterID, requestTime)
+	var deniedEvents []types.Event
 
-	fmt.Printf("[INFO] Checking Permission Denied History since %v for AWS Account %v as %v \n", startTime, accountId, arn)
-	fmt.Printf("[INFO] Fetching %v Event History...", cfg.Region)
+	fmt.Fprintf(os.Stderr, "[INFO] Checking Permission Denied History since %v for AWS Account %v as %v \n", startTime, accountId, arn)
+	fmt.Fprintf(os.Stderr, "[INFO] Fetching %v Event History...", cfg.Region)
 
 	for page := range generator {
 		filteredEvents, err := ApplyFilters(page.AWSEvent,
@@ -111,14 +132,14 @@ func (p *permissionDeniedEventsOptions) run() error {
 			return err
 		}
 		if len(filteredEvents) > 0 {
-			printer.PrintEvents(filteredEvents, defaultFields)
+			deniedEvents = append(deniedEvents, filteredEvents...)
 		}
 	}
 
 	if DEFAULT_REGION != cfg.Region {
 		defaultAwsAPI := NewEventAPI(cfg, true, DEFAULT_REGION)
 
-		fmt.Printf("[INFO] Fetching Cloudtrail Global Permission Denied Event History from %v Region...", DEFAULT_REGION)
+		fmt.Fprintf(os.Stderr, "[INFO] Fetching Cloudtrail Global Permission Denied Event History from %v Region...", DEFAULT_REGION)
 		generator := defaultAwsAPI.GetEvents(p.ClusterID, requestTime)
 
 		for page := range generator {
@@ -131,11 +152,21 @@ func (p *permissionDeniedEventsOptions) run() error {
 				return err
 			}
 			if len(filteredEvents) > 0 {
-				printer.PrintEvents(filteredEvents, defaultFields)
+				deniedEvents = append(deniedEvents, filteredEvents...)
 			}
 		}
 	}
 
-	return err
+	if !p.Analyze {
+		printer.PrintEvents(deniedEvents, defaultFields)
+		return printer.Flush()
+	}
+
+	fmt.Fprintf(os.Stderr, "\n[INFO] Extracting the CredentialsRequests of release %v...\n", cluster.Version().RawID())
+	operatorPolicies, err := LoadOperatorPolicies(cluster.Version().RawID(), cluster.AWS().STS().OperatorIAMRoles())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "[WARNING] Unable to load the operator policies, denied actions are not cross-referenced: %v\n", err)
+	}
 
+	return printDeniedActions(os.Stdout, AnalyzePermissionDenied(deniedEvents, operatorPolicies), p.Output)
 }
@@ -0,0 +1,283 @@
+package cloudtrail
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/cloudtrail/types"
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+	"github.com/openshift/osdctl/pkg/policies"
+	"github.com/openshift/osdctl/pkg/printer"
+)
+
+// OperatorPolicy is the set of actions a cluster operator is granted through its CredentialsRequest.
+type OperatorPolicy struct {
+	CredentialsRequest string `json:"credentialsRequest"`
+	SecretNamespace    string `json:"secretNamespace"`
+	SecretName         string `json:"secretName"`
+	// RoleARN is the operator role of STS clusters, empty for clusters using static credentials.
+	RoleARN string   `json:"roleArn,omitempty"`
+	Actions []string `json:"-"`
+}
+
+// Role returns the operator role of the policy, or the secret holding its credentials.
+func (p OperatorPolicy) Role() string {
+	if p.RoleARN != "" {
+		return p.RoleARN
+	}
+	return p.SecretNamespace + "/" + p.SecretName
+}
+
+// roleName returns the name of the operator role, i.e. the last segment of its ARN.
+func (p OperatorPolicy) roleName() string {
+	if p.RoleARN == "" {
+		return ""
+	}
+	return p.RoleARN[strings.LastIndex(p.RoleARN, "/")+1:]
+}
+
+// allows reports whether the policy grants action. Actions of the policy may contain wildcards.
+func (p OperatorPolicy) allows(action string) bool {
+	for _, allowed := range p.Actions {
+		if strings.EqualFold(allowed, action) || allowed == "*" {
+			return true
+		}
+		if strings.ContainsAny(allowed, "*?") && globToRegexp(strings.ToLower(allowed)).MatchString(strings.ToLower(action)) {
+			return true
+		}
+	}
+	return false
+}
+
+// DeniedAction aggregates the denied events of a principal for a single IAM action.
+type DeniedAction struct {
+	Principal string    `json:"principal"`
+	Action    string    `json:"action"`
+	Count     int       `json:"count"`
+	FirstSeen time.Time `json:"firstSeen"`
+	LastSeen  time.Time `json:"lastSeen"`
+	ErrorCode string    `json:"errorCode"`
+
+	// ExpectedIn lists the operator policies granting the action.
+	ExpectedIn []OperatorPolicy `json:"expectedIn"`
+	Finding    string           `json:"finding"`
+}
+
+// LoadOperatorPolicies extracts the AWS CredentialsRequests of the given release and converts
+// them into operator policies. Operator roles of STS clusters are matched to their
+// CredentialsRequest by the secret they provide.
+func LoadOperatorPolicies(version string, operatorRoles []*cmv1.OperatorIAMRole) ([]OperatorPolicy, error) {
+	dir, err := policies.DownloadCredentialRequests(version, policies.AWS)
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	credReqs, err := policies.ParseCredentialsRequestsInDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	roles := map[string]string{}
+	for _, role := range operatorRoles {
+		roles[role.Namespace()+"/"+role.Name()] = role.RoleARN()
+	}
+
+	var operatorPolicies []OperatorPolicy
+	for _, credReq := range credReqs {
+		doc, err := policies.AWSCredentialsRequestToPolicyDocument(credReq)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing CredentialsRequest '%s': %w", credReq.Name, err)
+		}
+
+		policy := OperatorPolicy{
+			CredentialsRequest: credReq.Name,
+			SecretNamespace:    credReq.Spec.SecretRef.Namespace,
+			SecretName:         credReq.Spec.SecretRef.Name,
+		}
+		policy.RoleARN = roles[policy.SecretNamespace+"/"+policy.SecretName]
+		for _, statement := range doc.Statement {
+			if strings.EqualFold(statement.Effect, "Allow") {
+				policy.Actions = append(policy.Actions, statement.Action...)
+			}
+		}
+		operatorPolicies = append(operatorPolicies, policy)
+	}
+
+	return operatorPolicies, nil
+}
+
+// eventAction returns the IAM action of an event, i.e. ec2:RunInstances.
+func eventAction(event types.Event) string {
+	var source, name string
+	if event.EventSource != nil {
+		source = strings.TrimSuffix(*event.EventSource, ".amazonaws.com")
+	}
+	if event.EventName != nil {
+		name = *event.EventName
+	}
+	return source + ":" + name
+}
+
+// eventPrincipal returns the role that made the request, or the username if the request was not made by a role.
+func eventPrincipal(event types.Event, raw *RawEventDetails) string {
+	if raw != nil && raw.UserIdentity.SessionContext.SessionIssuer.UserName != "" {
+		return raw.UserIdentity.SessionContext.SessionIssuer.UserName
+	}
+	if event.Username != nil {
+		return *event.Username
+	}
+	return "unknown"
+}
+
+// AnalyzePermissionDenied aggregates denied events by principal and action and cross-references
+// each action with the operator policies of the cluster. The result is sorted by number of
+// denied events, most frequent first.
+func AnalyzePermissionDenied(events []types.Event, operatorPolicies []OperatorPolicy) []DeniedAction {
+	index := map[string]*DeniedAction{}
+	var denied []*DeniedAction
+
+	for _, event := range events {
+		raw, err := ExtractUserDetails(event.CloudTrailEvent)
+		if err != nil {
+			raw = nil
+		}
+		principal := eventPrincipal(event, raw)
+		action := eventAction(event)
+		eventTime := eventTimeOf(event)
+
+		key := principal + "\x00" + action
+		entry, ok := index[key]
+		if !ok {
+			entry = &DeniedAction{Principal: principal, Action: action, FirstSeen: eventTime, LastSeen: eventTime}
+			if raw != nil {
+				entry.ErrorCode = raw.ErrorCode
+			}
+			index[key] = entry
+			denied = append(denied, entry)
+		}
+		entry.Count++
+		if eventTime.Before(entry.FirstSeen) {
+			entry.FirstSeen = eventTime
+		}
+		if eventTime.After(entry.LastSeen) {
+			entry.LastSeen = eventTime
+		}
+	}
+
+	result := make([]DeniedAction, 0, len(denied))
+	for _, entry := range denied {
+		for _, policy := range operatorPolicies {
+			if policy.allows(entry.Action) {
+				entry.ExpectedIn = append(entry.ExpectedIn, policy)
+			}
+		}
+		entry.Finding = deniedActionFinding(*entry, operatorPolicies)
+		result = append(result, *entry)
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		if result[i].Count != result[j].Count {
+			return result[i].Count > result[j].Count
+		}
+		return result[i].LastSeen.After(result[j].LastSeen)
+	})
+	return result
+}
+
+// deniedActionFinding explains why the action was denied, based on the operator policies granting it.
+func deniedActionFinding(denied DeniedAction, operatorPolicies []OperatorPolicy) string {
+	if len(operatorPolicies) == 0 {
+		return "operator policies not available"
+	}
+
+	var principalPolicy *OperatorPolicy
+	for i := range operatorPolicies {
+		if name := operatorPolicies[i].roleName(); name != "" && strings.EqualFold(name, denied.Principal) {
+			principalPolicy = &operatorPolicies[i]
+			break
+		}
+	}
+
+	if len(denied.ExpectedIn) == 0 {
+		if principalPolicy != nil {
+			return fmt.Sprintf("%s is not granted to %s by its CredentialsRequest %s", denied.Action, denied.Principal, principalPolicy.CredentialsRequest)
+		}
+		return fmt.Sprintf("%s is not granted by any operator CredentialsRequest", denied.Action)
+	}
+
+	for _, policy := range denied.ExpectedIn {
+		if principalPolicy != nil && policy.CredentialsRequest == principalPolicy.CredentialsRequest {
+			return fmt.Sprintf("%s should be allowed for role %s by CredentialsRequest %s, it was likely removed from the role's policy",
+				denied.Action, denied.Principal, policy.CredentialsRequest)
+		}
+	}
+
+	roles := make([]string, 0, len(denied.ExpectedIn))
+	for _, policy := range denied.ExpectedIn {
+		roles = append(roles, fmt.Sprintf("%s (%s)", policy.Role(), policy.CredentialsRequest))
+	}
+	return fmt.Sprintf("%s is expected on %s", denied.Action, strings.Join(roles, ", "))
+}
+
+// printDeniedActions writes the analysis in the requested output format, text is printed as a table.
+func printDeniedActions(out io.Writer, denied []DeniedAction, output string) error {
+	switch output {
+	case OutputJSON:
+		if denied == nil {
+			denied = []DeniedAction{}
+		}
+		data, err := json.MarshalIndent(denied, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal the analysis: %w", err)
+		}
+		_, err = fmt.Fprintln(out, string(data))
+		return err
+	case OutputJSONL:
+		encoder := json.NewEncoder(out)
+		for _, entry := range denied {
+			if err := encoder.Encode(entry); err != nil {
+				return fmt.Errorf("failed to marshal the analysis: %w", err)
+			}
+		}
+		return nil
+	case OutputCSV:
+		writer := csv.NewWriter(out)
+		_ = writer.Write(deniedActionHeaders)
+		for _, entry := range denied {
+			_ = writer.Write(entry.row())
+		}
+		writer.Flush()
+		return writer.Error()
+	}
+
+	table := printer.NewTablePrinter(out, 20, 1, 3, ' ')
+	table.AddRow(deniedActionHeaders)
+	for _, entry := range denied {
+		table.AddRow(entry.row())
+	}
+	return table.Flush()
+}
+
+// deniedActionHeaders are the columns of the table and CSV outputs of the analysis.
+var deniedActionHeaders = []string{"PRINCIPAL", "ACTION", "COUNT", "LAST SEEN", "EXPECTED ROLE", "FINDING"}
+
+// row returns the columns of the denied action in the order of deniedActionHeaders.
+func (d DeniedAction) row() []string {
+	roles := make([]string, 0, len(d.ExpectedIn))
+	for _, policy := range d.ExpectedIn {
+		roles = append(roles, policy.Role())
+	}
+	expected := strings.Join(roles, ",")
+	if expected == "" {
+		expected = "-"
+	}
+	return []string{d.Principal, d.Action, strconv.Itoa(d.Count), d.LastSeen.Format(time.RFC3339), expected, d.Finding}
+}
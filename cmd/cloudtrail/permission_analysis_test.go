@@ -0,0 +1,96 @@
+package cloudtrail
+
+import (
+	"bytes"
+	"encoding/csv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudtrail/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func deniedEventAt(principal, source, name string, eventTime time.Time) types.Event {
+	raw := `{"eventVersion": "1.08", "errorCode": "Client.UnauthorizedOperation",
+		"userIdentity": {"sessionContext": {"sessionIssuer": {"userName": "` + principal + `"}}}}`
+	return types.Event{
+		EventName:       aws.String(name),
+		EventSource:     aws.String(source),
+		EventTime:       &eventTime,
+		CloudTrailEvent: aws.String(raw),
+	}
+}
+
+func TestAnalyzePermissionDenied(t *testing.T) {
+	now := time.Date(2025, 7, 15, 12, 0, 0, 0, time.UTC)
+	operatorPolicies := []OperatorPolicy{
+		{
+			CredentialsRequest: "openshift-machine-api-aws",
+			SecretNamespace:    "openshift-machine-api",
+			SecretName:         "aws-cloud-credentials",
+			RoleARN:            "arn:aws:iam::123456789012:role/mycluster-openshift-machine-api-aws-cloud-credentials",
+			Actions:            []string{"ec2:RunInstances", "ec2:Describe*"},
+		},
+		{
+			CredentialsRequest: "openshift-image-registry",
+			SecretNamespace:    "openshift-image-registry",
+			SecretName:         "installer-cloud-credentials",
+			Actions:            []string{"s3:CreateBucket"},
+		},
+	}
+
+	events := []types.Event{
+		deniedEventAt("mycluster-openshift-machine-api-aws-cloud-credentials", "ec2.amazonaws.com", "RunInstances", now.Add(-time.Hour)),
+		deniedEventAt("mycluster-openshift-machine-api-aws-cloud-credentials", "ec2.amazonaws.com", "RunInstances", now),
+		deniedEventAt("mycluster-openshift-machine-api-aws-cloud-credentials", "ec2.amazonaws.com", "DescribeSubnets", now),
+		deniedEventAt("ManagedOpenShift-Support-Role", "s3.amazonaws.com", "CreateBucket", now),
+		deniedEventAt("ManagedOpenShift-Support-Role", "iam.amazonaws.com", "CreateUser", now),
+	}
+
+	denied := AnalyzePermissionDenied(events, operatorPolicies)
+	assert.Len(t, denied, 4)
+
+	assert.Equal(t, "ec2:RunInstances", denied[0].Action)
+	assert.Equal(t, 2, denied[0].Count)
+	assert.Equal(t, now.Add(-time.Hour), denied[0].FirstSeen)
+	assert.Equal(t, now, denied[0].LastSeen)
+	assert.Equal(t, "Client.UnauthorizedOperation", denied[0].ErrorCode)
+	assert.Contains(t, denied[0].Finding, "likely removed")
+
+	byAction := map[string]DeniedAction{}
+	for _, entry := range denied {
+		byAction[entry.Action] = entry
+	}
+	assert.Contains(t, byAction["ec2:DescribeSubnets"].Finding, "likely removed")
+	assert.Len(t, byAction["s3:CreateBucket"].ExpectedIn, 1)
+	assert.Contains(t, byAction["s3:CreateBucket"].Finding, "openshift-image-registry/installer-cloud-credentials")
+	assert.Empty(t, byAction["iam:CreateUser"].ExpectedIn)
+	assert.Contains(t, byAction["iam:CreateUser"].Finding, "not granted by any operator")
+}
+
+func TestAnalyzePermissionDeniedWithoutPolicies(t *testing.T) {
+	now := time.Date(2025, 7, 15, 12, 0, 0, 0, time.UTC)
+	denied := AnalyzePermissionDenied([]types.Event{deniedEventAt("role", "ec2.amazonaws.com", "RunInstances", now)}, nil)
+	assert.Len(t, denied, 1)
+	assert.Equal(t, "operator policies not available", denied[0].Finding)
+
+	var out bytes.Buffer
+	assert.NoError(t, printDeniedActions(&out, denied, OutputJSON))
+	assert.Contains(t, out.String(), `"action": "ec2:RunInstances"`)
+
+	out.Reset()
+	assert.NoError(t, printDeniedActions(&out, denied, OutputJSONL))
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	assert.Len(t, lines, len(denied))
+	assert.Contains(t, lines[0], `"action":"ec2:RunInstances"`)
+
+	out.Reset()
+	assert.NoError(t, printDeniedActions(&out, denied, OutputCSV))
+	rows, err := csv.NewReader(&out).ReadAll()
+	assert.NoError(t, err)
+	assert.Len(t, rows, len(denied)+1)
+	assert.Equal(t, deniedActionHeaders, rows[0])
+	assert.Equal(t, "ec2:RunInstances", rows[1][1])
+}
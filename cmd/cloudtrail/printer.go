@@ -1,33 +1,169 @@
 package cloudtrail
 
 import (
+	"encoding/csv"
+	"encoding/json"
 	"fmt"
+	"io"
+	"os"
 	"strings"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/service/cloudtrail/types"
+	"github.com/openshift/osdctl/pkg/printer"
 )
 
+// Supported output formats for cloudtrail events.
+const (
+	OutputText  = "text"
+	OutputJSON  = "json"
+	OutputJSONL = "jsonl"
+	OutputCSV   = "csv"
+	OutputTable = "table"
+)
+
+var recordHeaders = []string{"EVENT", "TIME", "USERNAME", "SESSION ISSUER ARN", "REGION", "RESOURCES", "SOURCE IP", "ERROR CODE", "CONSOLE URL"}
+
+// EventResource is a normalized resource referenced by a CloudTrail event.
+type EventResource struct {
+	Name string `json:"name,omitempty"`
+	Type string `json:"type,omitempty"`
+}
+
+// EventRecord is a normalized, machine-readable representation of a CloudTrail event.
+type EventRecord struct {
+	EventID          string          `json:"eventId,omitempty"`
+	EventName        string          `json:"eventName"`
+	EventTime        time.Time       `json:"eventTime"`
+	Username         string          `json:"username,omitempty"`
+	SessionIssuerArn string          `json:"sessionIssuerArn,omitempty"`
+	Region           string          `json:"region,omitempty"`
+	Resources        []EventResource `json:"resources"`
+	SourceIPAddress  string          `json:"sourceIPAddress,omitempty"`
+	ErrorCode        string          `json:"errorCode,omitempty"`
+	ConsoleURL       string          `json:"consoleUrl,omitempty"`
+}
+
+// NewEventRecord builds an EventRecord from a CloudTrail event. Fields that are
+// only available in the raw CloudTrailEvent JSON are left empty if it cannot be parsed.
+func NewEventRecord(event types.Event) EventRecord {
+	record := EventRecord{Resources: []EventResource{}}
+
+	if event.EventId != nil {
+		record.EventID = *event.EventId
+	}
+	if event.EventName != nil {
+		record.EventName = *event.EventName
+	}
+	if event.EventTime != nil {
+		record.EventTime = event.EventTime.UTC()
+	}
+	if event.Username != nil {
+		record.Username = *event.Username
+	}
+	for _, resource := range event.Resources {
+		r := EventResource{}
+		if resource.ResourceName != nil {
+			r.Name = *resource.ResourceName
+		}
+		if resource.ResourceType != nil {
+			r.Type = *resource.ResourceType
+		}
+		record.Resources = append(record.Resources, r)
+	}
+
+	rawEventDetails, err := ExtractUserDetails(event.CloudTrailEvent)
+	if err != nil {
+		return record
+	}
+	if record.EventID == "" {
+		record.EventID = rawEventDetails.EventId
+	}
+	record.SessionIssuerArn = rawEventDetails.UserIdentity.SessionContext.SessionIssuer.Arn
+	record.Region = rawEventDetails.EventRegion
+	record.SourceIPAddress = rawEventDetails.SourceIPAddress
+	record.ErrorCode = rawEventDetails.ErrorCode
+	record.ConsoleURL = generateLink(*rawEventDetails)
+
+	return record
+}
+
+// row returns the record as a list of columns matching recordHeaders.
+func (r EventRecord) row() []string {
+	var eventTime string
+	if !r.EventTime.IsZero() {
+		eventTime = r.EventTime.Format(time.RFC3339)
+	}
+	resources := make([]string, 0, len(r.Resources))
+	for _, resource := range r.Resources {
+		resources = append(resources, resource.Type+":"+resource.Name)
+	}
+	return []string{
+		r.EventName,
+		eventTime,
+		r.Username,
+		r.SessionIssuerArn,
+		r.Region,
+		strings.Join(resources, ";"),
+		r.SourceIPAddress,
+		r.ErrorCode,
+		r.ConsoleURL,
+	}
+}
+
 // Printer struct handles the formatting and output of CloudTrail events.
 type Printer struct {
 	printUrl bool
 	printRaw bool
+	output   string
+	out      io.Writer
+
+	// records buffers events for output formats that can only be
+	// rendered once all events are known (json, table).
+	records   []EventRecord
+	csvHeader bool
 }
 
 // NewPrinter creates a new Printer instance with the specified output options.
 // Parameters:
 //   - printUrl: If true, generates and includes AWS Console links for events
 //   - printRaw: If true, displays events in raw JSON format
-func NewPrinter(printUrl, printRaw bool) *Printer {
+//   - output: One of text, json, jsonl, csv or table. Defaults to text if empty
+func NewPrinter(printUrl, printRaw bool, output string) *Printer {
+	if output == "" {
+		output = OutputText
+	}
 	return &Printer{
 		printUrl: printUrl,
 		printRaw: printRaw,
+		output:   output,
+		out:      os.Stdout,
 	}
 }
 
+// IsStructured returns true if the printer emits machine-readable output.
+func (o *Printer) IsStructured() bool {
+	return o.output != OutputText
+}
+
+// ValidateOutput checks that the given output format is supported.
+func ValidateOutput(output string) error {
+	switch output {
+	case OutputText, OutputJSON, OutputJSONL, OutputCSV, OutputTable:
+		return nil
+	}
+	return fmt.Errorf("invalid output format: %s (allowed: text, json, jsonl, csv, table)", output)
+}
+
 // PrintEvents prints the filtered CloudTrail events in a human-readable format.
 // Allows to print cloudtrail event url link or its raw JSON format.
 // Allows to print cloutrail event resource name & type.
 func (o *Printer) PrintEvents(filterEvents []types.Event, printFields []string) {
+	if o.IsStructured() {
+		o.printRecords(filterEvents)
+		return
+	}
+
 	var eventStringBuilder = strings.Builder{}
 	tableFilter := map[string]struct{}{}
 
@@ -38,7 +174,7 @@ func (o *Printer) PrintEvents(filterEvents []types.Event, printFields []string)
 	for i := range filterEvents {
 		rawEventDetails, err := ExtractUserDetails(filterEvents[i].CloudTrailEvent)
 		if err != nil {
-			fmt.Printf("[Error] Error extracting event details: %v", err)
+			_, _ = fmt.Fprintf(o.out, "[Error] Error extracting event details: %v", err)
 		}
 		sessionIssuer := rawEventDetails.UserIdentity.SessionContext.SessionIssuer.UserName
 		eventStringBuilder.WriteString("\n")
@@ -54,6 +190,9 @@ func (o *Printer) PrintEvents(filterEvents []types.Event, printFields []string)
 		if _, ok := tableFilter["arn"]; ok && sessionIssuer != "" {
 			_, _ = fmt.Fprintf(&eventStringBuilder, "ARN: %v | ", sessionIssuer)
 		}
+		if _, ok := tableFilter["region"]; ok && rawEventDetails.EventRegion != "" {
+			_, _ = fmt.Fprintf(&eventStringBuilder, "Region: %v | ", rawEventDetails.EventRegion)
+		}
 
 		for _, resource := range filterEvents[i].Resources {
 			if _, ok := tableFilter["resource-name"]; ok && resource.ResourceName != nil {
@@ -68,12 +207,79 @@ func (o *Printer) PrintEvents(filterEvents []types.Event, printFields []string)
 			if err == nil {
 				_, _ = fmt.Fprintf(&eventStringBuilder, "%v", generateLink(*rawEventDetails))
 			} else {
-				fmt.Println("EventLink: <not available>")
+				_, _ = fmt.Fprintln(o.out, "EventLink: <not available>")
 			}
 		}
 
 	}
-	fmt.Print(eventStringBuilder.String())
+	_, _ = fmt.Fprint(o.out, eventStringBuilder.String())
+}
+
+// printRecords writes the events in the structured output format of the printer.
+// jsonl and csv are streamed, json and table are buffered until Flush is called.
+func (o *Printer) printRecords(events []types.Event) {
+	switch o.output {
+	case OutputJSONL:
+		encoder := json.NewEncoder(o.out)
+		for _, event := range events {
+			if err := encoder.Encode(NewEventRecord(event)); err != nil {
+				_, _ = fmt.Fprintf(os.Stderr, "[Error] failed to encode event: %v\n", err)
+			}
+		}
+	case OutputCSV:
+		writer := csv.NewWriter(o.out)
+		if !o.csvHeader {
+			_ = writer.Write(recordHeaders)
+			o.csvHeader = true
+		}
+		for _, event := range events {
+			_ = writer.Write(NewEventRecord(event).row())
+		}
+		writer.Flush()
+	default:
+		for _, event := range events {
+			o.records = append(o.records, NewEventRecord(event))
+		}
+	}
+}
+
+// Flush writes any buffered records. It must be called once all events
+// have been passed to PrintEvents.
+func (o *Printer) Flush() error {
+	switch o.output {
+	case OutputJSON:
+		records := o.records
+		if records == nil {
+			records = []EventRecord{}
+		}
+		data, err := json.MarshalIndent(records, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal events: %w", err)
+		}
+		_, _ = fmt.Fprintln(o.out, string(data))
+	case OutputCSV:
+		if !o.csvHeader {
+			writer := csv.NewWriter(o.out)
+			_ = writer.Write(recordHeaders)
+			writer.Flush()
+			o.csvHeader = true
+		}
+	case OutputTable:
+		headers := recordHeaders
+		if !o.printUrl {
+			headers = headers[:len(headers)-1]
+		}
+		table := printer.NewTablePrinter(o.out, 20, 1, 3, ' ')
+		table.AddRow(headers)
+		for _, record := range o.records {
+			table.AddRow(record.row()[:len(headers)])
+		}
+		if err := table.Flush(); err != nil {
+			return err
+		}
+	}
+	o.records = nil
+	return nil
 }
 
 // generateLink generates a hyperlink to aws cloudTrail event
@@ -102,11 +308,12 @@ func ValidateFormat(table []string) error {
 		"resource-type": {},
 		"arn":           {},
 		"time":          {},
+		"region":        {},
 	}
 
 	for _, column := range table {
 		if _, ok := allowedKeys[strings.ToLower(column)]; !ok {
-			return fmt.Errorf("invalid table column: %s (allowed: username, event, resource-name, resource-type, arn, time, url, region)", column)
+			return fmt.Errorf("invalid table column: %s (allowed: username, event, resource-name, resource-type, arn, time, region)", column)
 		}
 	}
 
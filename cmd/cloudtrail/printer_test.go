@@ -0,0 +1,132 @@
+package cloudtrail
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudtrail/types"
+	"github.com/stretchr/testify/assert"
+)
+
+const testRawEvent = `{"eventVersion": "1.08","userIdentity": {"sessionContext": {"sessionIssuer": {"userName": "test-role","arn": "arn:aws:iam::123456789012:role/test-role"}}},"awsRegion": "us-east-2","eventID": "abcd-1234","sourceIPAddress": "10.0.0.1","errorCode": "Client.UnauthorizedOperation"}`
+
+func testEvent() types.Event {
+	eventTime := time.Date(2025, 7, 15, 9, 0, 0, 0, time.UTC)
+	return types.Event{
+		EventId:         aws.String("abcd-1234"),
+		EventName:       aws.String("RunInstances"),
+		EventTime:       &eventTime,
+		Username:        aws.String("john.doe"),
+		CloudTrailEvent: aws.String(testRawEvent),
+		Resources: []types.Resource{
+			{ResourceName: aws.String("i-123"), ResourceType: aws.String("AWS::EC2::Instance")},
+		},
+	}
+}
+
+func TestNewEventRecord(t *testing.T) {
+	record := NewEventRecord(testEvent())
+
+	assert.Equal(t, "abcd-1234", record.EventID)
+	assert.Equal(t, "RunInstances", record.EventName)
+	assert.Equal(t, "john.doe", record.Username)
+	assert.Equal(t, "arn:aws:iam::123456789012:role/test-role", record.SessionIssuerArn)
+	assert.Equal(t, "us-east-2", record.Region)
+	assert.Equal(t, "10.0.0.1", record.SourceIPAddress)
+	assert.Equal(t, "Client.UnauthorizedOperation", record.ErrorCode)
+	assert.Equal(t, []EventResource{{Name: "i-123", Type: "AWS::EC2::Instance"}}, record.Resources)
+	assert.Equal(t, "https://us-east-2.console.aws.amazon.com/cloudtrailv2/home?region=us-east-2#/events/abcd-1234", record.ConsoleURL)
+}
+
+func TestNewEventRecordInvalidRawEvent(t *testing.T) {
+	event := testEvent()
+	event.CloudTrailEvent = nil
+
+	record := NewEventRecord(event)
+	assert.Equal(t, "RunInstances", record.EventName)
+	assert.Empty(t, record.SessionIssuerArn)
+	assert.Empty(t, record.ConsoleURL)
+}
+
+func TestPrinterOutputs(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		check  func(t *testing.T, out string)
+	}{
+		{
+			name:   "jsonl emits one record per line",
+			output: OutputJSONL,
+			check: func(t *testing.T, out string) {
+				lines := strings.Split(strings.TrimSpace(out), "\n")
+				assert.Len(t, lines, 2)
+				var record EventRecord
+				assert.NoError(t, json.Unmarshal([]byte(lines[0]), &record))
+				assert.Equal(t, "RunInstances", record.EventName)
+			},
+		},
+		{
+			name:   "json emits a single array",
+			output: OutputJSON,
+			check: func(t *testing.T, out string) {
+				var records []EventRecord
+				assert.NoError(t, json.Unmarshal([]byte(out), &records))
+				assert.Len(t, records, 2)
+			},
+		},
+		{
+			name:   "csv emits the header once",
+			output: OutputCSV,
+			check: func(t *testing.T, out string) {
+				lines := strings.Split(strings.TrimSpace(out), "\n")
+				assert.Len(t, lines, 3)
+				assert.True(t, strings.HasPrefix(lines[0], "EVENT,TIME,USERNAME"))
+				assert.Contains(t, lines[1], "AWS::EC2::Instance:i-123")
+			},
+		},
+		{
+			name:   "table omits the url column by default",
+			output: OutputTable,
+			check: func(t *testing.T, out string) {
+				lines := strings.Split(strings.TrimSpace(out), "\n")
+				assert.Len(t, lines, 3)
+				assert.NotContains(t, lines[0], "CONSOLE URL")
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			p := NewPrinter(false, false, tt.output)
+			p.out = buf
+
+			// Events are printed in several batches, as write-events does.
+			p.PrintEvents([]types.Event{testEvent()}, defaultFields)
+			p.PrintEvents([]types.Event{testEvent()}, defaultFields)
+			assert.NoError(t, p.Flush())
+
+			tt.check(t, buf.String())
+		})
+	}
+}
+
+func TestPrinterEmptyJSON(t *testing.T) {
+	buf := &bytes.Buffer{}
+	p := NewPrinter(false, false, OutputJSON)
+	p.out = buf
+
+	assert.NoError(t, p.Flush())
+	assert.Equal(t, "[]\n", buf.String())
+}
+
+func TestValidateOutput(t *testing.T) {
+	for _, output := range []string{OutputText, OutputJSON, OutputJSONL, OutputCSV, OutputTable} {
+		assert.NoError(t, ValidateOutput(output))
+	}
+	assert.Error(t, ValidateOutput("yaml"))
+}
@@ -0,0 +1,181 @@
+package cloudtrail
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudtrail/types"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/sirupsen/logrus"
+)
+
+// AllRegions can be passed to --regions to look up events in every region enabled for the account.
+const AllRegions = "all"
+
+// ResolveRegions returns the list of regions to look up events in.
+// Without requested regions, the cluster home region and the global
+// DEFAULT_REGION are used. "all" resolves to every region returned by listEnabled.
+func ResolveRegions(requested []string, homeRegion string, listEnabled func() ([]string, error)) ([]string, error) {
+	var regions []string
+
+	switch {
+	case len(requested) == 0:
+		regions = []string{homeRegion, DEFAULT_REGION}
+	case slices.Contains(requested, AllRegions):
+		if len(requested) > 1 {
+			return nil, fmt.Errorf("--regions=%s cannot be combined with other regions", AllRegions)
+		}
+		enabled, err := listEnabled()
+		if err != nil {
+			return nil, fmt.Errorf("failed to list enabled regions: %w", err)
+		}
+		regions = append(enabled, DEFAULT_REGION)
+	default:
+		regions = requested
+	}
+
+	var resolved []string
+	for _, region := range regions {
+		region = strings.TrimSpace(region)
+		if region != "" && !slices.Contains(resolved, region) {
+			resolved = append(resolved, region)
+		}
+	}
+	if len(resolved) == 0 {
+		return nil, fmt.Errorf("no region to look up events in")
+	}
+	return resolved, nil
+}
+
+// EnabledRegions lists the regions that are enabled for the account of the given config.
+func EnabledRegions(cfg aws.Config) ([]string, error) {
+	output, err := ec2.NewFromConfig(cfg).DescribeRegions(context.TODO(), &ec2.DescribeRegionsInput{})
+	if err != nil {
+		return nil, err
+	}
+
+	var regions []string
+	for _, region := range output.Regions {
+		if region.RegionName != nil {
+			regions = append(regions, *region.RegionName)
+		}
+	}
+	return regions, nil
+}
+
+// regionLookup is the result of looking up the events of a single region.
+type regionLookup struct {
+	region  string
+	events  []types.Event
+	fetched []CachedEvent
+	periods []Period
+	err     error
+}
+
+// LookupRegions retrieves the events of the requested period from every given EventGetter
+// concurrently. Events already present in the cache are not retrieved again.
+// It returns the events of all regions sorted by event time (newest first), and the
+// newly retrieved data to be saved into the cache. A region that fails is logged and skipped,
+// an error is returned only if every region failed.
+func LookupRegions(log *logrus.Logger, cache *Cache, clusterID string, apis []EventGetter, requestedPeriod Period) ([]types.Event, Cache, error) {
+	results := make([]regionLookup, len(apis))
+
+	var wg sync.WaitGroup
+	for i, api := range apis {
+		wg.Add(1)
+		go func(i int, api EventGetter) {
+			defer wg.Done()
+			results[i] = lookupRegion(log, cache, clusterID, api, requestedPeriod)
+		}(i, api)
+	}
+	wg.Wait()
+
+	newCacheData := Cache{
+		Period:  []Period{},
+		Regions: map[string][]Period{},
+		Event:   []CachedEvent{},
+	}
+
+	var events []types.Event
+	var regionErrors []error
+	for _, result := range results {
+		if result.err != nil {
+			log.Warnf("Skipping region %s: %v", result.region, result.err)
+			regionErrors = append(regionErrors, fmt.Errorf("%s: %w", result.region, result.err))
+			continue
+		}
+		events = append(events, result.events...)
+		newCacheData.Regions[result.region] = append(newCacheData.Regions[result.region], result.periods...)
+		newCacheData.Event = append(newCacheData.Event, result.fetched...)
+	}
+
+	if len(results) > 0 && len(regionErrors) == len(results) {
+		return nil, newCacheData, fmt.Errorf("failed to look up the events of every region: %w", errors.Join(regionErrors...))
+	}
+	return MergeEvents(events), newCacheData, nil
+}
+
+// lookupRegion retrieves the events of a single region, using the cache where possible.
+func lookupRegion(log *logrus.Logger, cache *Cache, clusterID string, api EventGetter, requestedPeriod Period) regionLookup {
+	region := api.Region()
+	result := regionLookup{region: region}
+
+	missingPeriods := []Period{requestedPeriod}
+	if cache != nil {
+		missing, fullCacheOverlap := requestedPeriod.DiffMultiple(cache.Regions[region])
+		missingPeriods = missing
+		if fullCacheOverlap {
+			missingPeriods = nil
+		}
+		result.events = FilterByRegion(region, cache.FilterByPeriod(requestedPeriod))
+	}
+
+	for _, period := range missingPeriods {
+		log.Debugf("Retrieving events from %s between %v and %v", region, period.StartTime, period.EndTime)
+		for page := range api.GetEvents(clusterID, period) {
+			if page.errors != nil {
+				result.err = page.errors
+				return result
+			}
+			for _, event := range page.AWSEvent {
+				result.events = append(result.events, event)
+				result.fetched = append(result.fetched, CachedEvent{Region: region, Event: event})
+			}
+		}
+		result.periods = append(result.periods, period)
+	}
+
+	return result
+}
+
+// MergeEvents removes duplicated events and sorts them by event time, newest first.
+func MergeEvents(events []types.Event) []types.Event {
+	merged := make([]types.Event, 0, len(events))
+	seen := map[string]struct{}{}
+	for _, event := range events {
+		if event.EventId != nil {
+			if _, ok := seen[*event.EventId]; ok {
+				continue
+			}
+			seen[*event.EventId] = struct{}{}
+		}
+		merged = append(merged, event)
+	}
+
+	sort.SliceStable(merged, func(i, j int) bool {
+		if merged[i].EventTime == nil {
+			return false
+		}
+		if merged[j].EventTime == nil {
+			return true
+		}
+		return merged[j].EventTime.Before(*merged[i].EventTime)
+	})
+	return merged
+}
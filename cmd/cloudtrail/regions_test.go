@@ -0,0 +1,142 @@
+package cloudtrail
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudtrail/types"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeEventGetter struct {
+	region string
+	events []types.Event
+	err    error
+	calls  []Period
+}
+
+func (f *fakeEventGetter) Region() string {
+	return f.region
+}
+
+func (f *fakeEventGetter) GetEvents(_ string, missing Period) <-chan EventResult {
+	f.calls = append(f.calls, missing)
+	ch := make(chan EventResult, 1)
+	if f.err != nil {
+		ch <- EventResult{errors: f.err}
+	} else {
+		ch <- EventResult{AWSEvent: FilterEventsBefore(FilterEventsAfter(f.events, missing.StartTime), missing.EndTime)}
+	}
+	close(ch)
+	return ch
+}
+
+func eventAt(id string, region string, eventTime time.Time) types.Event {
+	raw := `{"eventVersion": "1.08","awsRegion": "` + region + `","eventID": "` + id + `"}`
+	return types.Event{
+		EventId:         aws.String(id),
+		EventName:       aws.String("CreateBucket"),
+		EventTime:       &eventTime,
+		CloudTrailEvent: aws.String(raw),
+	}
+}
+
+func TestResolveRegions(t *testing.T) {
+	enabled := func() ([]string, error) { return []string{"eu-west-1", "us-east-1"}, nil }
+
+	tests := []struct {
+		name      string
+		requested []string
+		expected  []string
+		wantErr   bool
+	}{
+		{name: "defaults to home and global region", requested: nil, expected: []string{"us-east-2", DEFAULT_REGION}},
+		{name: "explicit list is deduplicated", requested: []string{"eu-west-1", " eu-west-1", "ap-south-1"}, expected: []string{"eu-west-1", "ap-south-1"}},
+		{name: "all uses enabled regions", requested: []string{AllRegions}, expected: []string{"eu-west-1", "us-east-1"}},
+		{name: "all cannot be combined", requested: []string{AllRegions, "eu-west-1"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			regions, err := ResolveRegions(tt.requested, "us-east-2", enabled)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, regions)
+		})
+	}
+
+	_, err := ResolveRegions([]string{AllRegions}, "us-east-2", func() ([]string, error) { return nil, errors.New("denied") })
+	assert.Error(t, err)
+}
+
+func TestMergeEvents(t *testing.T) {
+	now := time.Now().UTC()
+	events := []types.Event{
+		eventAt("a", "us-east-1", now.Add(-2*time.Hour)),
+		eventAt("b", "eu-west-1", now.Add(-time.Hour)),
+		eventAt("a", "us-east-1", now.Add(-2*time.Hour)),
+		eventAt("c", "us-east-2", now),
+	}
+
+	merged := MergeEvents(events)
+	assert.Len(t, merged, 3)
+	assert.Equal(t, "c", *merged[0].EventId)
+	assert.Equal(t, "b", *merged[1].EventId)
+	assert.Equal(t, "a", *merged[2].EventId)
+}
+
+func TestLookupRegionsUsesCache(t *testing.T) {
+	log := logrus.New()
+	end := time.Date(2025, 7, 15, 12, 0, 0, 0, time.UTC)
+	requested := Period{StartTime: end.Add(-2 * time.Hour), EndTime: end}
+
+	east := &fakeEventGetter{region: "us-east-1", events: []types.Event{eventAt("east-1", "us-east-1", end.Add(-time.Hour))}}
+	west := &fakeEventGetter{region: "eu-west-1", events: []types.Event{eventAt("west-1", "eu-west-1", end.Add(-30*time.Minute))}}
+	broken := &fakeEventGetter{region: "ap-south-1", err: errors.New("region disabled")}
+
+	cache := newCacheInDir(log, filepath.Join(t.TempDir(), "cluster"))
+	assert.NoError(t, cache.EnsureFilenameExist())
+
+	events, newCacheData, err := LookupRegions(log, cache, "cluster", []EventGetter{east, west, broken}, requested)
+	assert.NoError(t, err)
+	assert.Len(t, events, 2)
+	assert.Equal(t, "west-1", *events[0].EventId)
+	assert.Contains(t, newCacheData.Regions, "us-east-1")
+	assert.Contains(t, newCacheData.Regions, "eu-west-1")
+	assert.NotContains(t, newCacheData.Regions, "ap-south-1")
+	assert.NoError(t, cache.Save(newCacheData))
+
+	reloaded := newCacheInDir(log, cache.dir)
+	assert.NoError(t, reloaded.Read())
+	assert.Len(t, reloaded.FilterByPeriod(requested), 2)
+	assert.Len(t, FilterByRegion("eu-west-1", reloaded.FilterByPeriod(requested)), 1)
+
+	// A second lookup of the same period is served from the cache.
+	events, newCacheData, err = LookupRegions(log, reloaded, "cluster", []EventGetter{east, west}, requested)
+	assert.NoError(t, err)
+	assert.Len(t, events, 2)
+	assert.Len(t, east.calls, 1)
+	assert.Len(t, west.calls, 1)
+	assert.Empty(t, newCacheData.Event)
+}
+
+func TestLookupRegionsAllFailed(t *testing.T) {
+	log := logrus.New()
+	end := time.Date(2025, 7, 15, 12, 0, 0, 0, time.UTC)
+	requested := Period{StartTime: end.Add(-2 * time.Hour), EndTime: end}
+
+	east := &fakeEventGetter{region: "us-east-1", err: errors.New("access denied")}
+	west := &fakeEventGetter{region: "eu-west-1", err: errors.New("region disabled")}
+
+	events, _, err := LookupRegions(log, nil, "cluster", []EventGetter{east, west}, requested)
+	assert.Empty(t, events)
+	assert.ErrorContains(t, err, "us-east-1: access denied")
+	assert.ErrorContains(t, err, "eu-west-1: region disabled")
+}
@@ -1,9 +1,13 @@
 package cloudtrail
 
 import (
+	"context"
 	"fmt"
-	"sort"
+	"os"
+	"os/signal"
+	"slices"
 	"strings"
+	"syscall"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/service/cloudtrail/types"
@@ -27,14 +31,16 @@ type writeEventsOptions struct {
 	PrintUrl    bool
 	PrintRaw    bool
 	PrintFields []string
+	Output      string
+	Regions     []string
 	Cache       bool
+	Follow      bool
+	Interval    time.Duration
 
-	awsAPI   *EventAPI
-	printer  *Printer
-	log      *logrus.Logger
-	logLevel string
-
-	missingPeriod []Period
+	printer    *Printer
+	log        *logrus.Logger
+	logLevel   string
+	filterExpr Filter
 }
 
 const (
@@ -47,7 +53,23 @@ const (
     $ osdctl cloudtrail write-events -C cluster-id --after 2025-07-15,15:00:00 --since 2h --url
 
     # Get all events until the specified time since the last 2 hours; print raw-event
-    $ osdctl cloudtrail write-events -C cluster-id --after 2025-07-15,15:00:00 --since 2h --raw-event`
+    $ osdctl cloudtrail write-events -C cluster-id --after 2025-07-15,15:00:00 --since 2h --raw-event
+
+    # Get all deletions of EC2 instances that were not made by the installer role, using a filter expression
+    $ osdctl cloudtrail write-events -C cluster-id --since 6h \
+      --filter 'event like "Delete*" and resource-type == AWS::EC2::Instance and not arn =~ ".*-Installer-Role"'
+
+    # Filter on any field of the raw CloudTrail event
+    $ osdctl cloudtrail write-events -C cluster-id --filter 'requestParameters.instanceId == i-0123456789abcdef0'
+
+    # Get all events of the last 2 hours from every enabled region
+    $ osdctl cloudtrail write-events -C cluster-id --since 2h --regions all
+
+    # Get all events of the last 6 hours as JSON Lines, one normalized record per event
+    $ osdctl cloudtrail write-events -C cluster-id --since 6h -o jsonl | jq .eventName
+
+    # Print the events of the last hour, then keep printing new events every minute until interrupted
+    $ osdctl cloudtrail write-events -C cluster-id --follow --interval 1m -E username=system`
 
 	cloudtrailWriteEventsDescription = `
 	Lists AWS CloudTrail write events for a specific OpenShift/ROSA cluster with advanced 
@@ -80,112 +102,67 @@ func newCmdWriteEvents() *cobra.Command {
 	listEventsCmd.Flags().StringVarP(&ops.Duration, "since", "", "1h", "Specifies that only events that occur within the specified time are returned. Defaults to 1h.Valid time units are \"ns\", \"us\" (or \"µs\"), \"ms\", \"s\", \"m\", \"h\".")
 	listEventsCmd.Flags().StringVarP(&ops.logLevel, "log-level", "l", "info", "Options: \"info\", \"debug\", \"warn\", \"error\". (default=info)")
 	listEventsCmd.Flags().BoolVarP(&ops.Cache, "cache", "", true, "Enable/Disable cache file for write-events")
+	listEventsCmd.Flags().BoolVarP(&ops.Follow, "follow", "f", false, "Keep polling for new events after printing the requested period, until interrupted")
+	listEventsCmd.Flags().DurationVar(&ops.Interval, "interval", 30*time.Second, "Polling interval of --follow")
+	listEventsCmd.Flags().StringSliceVarP(&ops.Regions, "regions", "", nil, "Regions to look up events in, or \"all\" for every enabled region. Defaults to the cluster region and us-east-1")
 
 	listEventsCmd.Flags().BoolVarP(&ops.PrintUrl, "url", "u", false, "Generates Url link to cloud console cloudtrail event")
 	listEventsCmd.Flags().BoolVarP(&ops.PrintRaw, "raw-event", "r", false, "Prints the cloudtrail events to the console in raw json format")
-	listEventsCmd.Flags().StringSliceVarP(&ops.PrintFields, "print-fields", "", defaultFields, "Prints all cloudtrail write events in selected format. Can specify (username, time, event, arn, resource-name, resource-type, arn). i.e --print-format username,time,event")
+	listEventsCmd.Flags().StringVarP(&ops.Output, "output", "o", OutputText, "Output format. One of: text, json, jsonl, csv, table. --print-fields only applies to text")
+	listEventsCmd.Flags().StringSliceVarP(&ops.PrintFields, "print-fields", "", defaultFields, "Prints all cloudtrail write events in selected format. Can specify (username, time, event, arn, resource-name, resource-type, region). i.e --print-format username,time,event")
 
 	listEventsCmd.Flags().StringSliceVarP(&fil.Include, "include", "I", nil, "Filter events by inclusion. (i.e. \"-I username=, -I event=, -I resource-name=, -I resource-type=, -I arn=\")")
 	listEventsCmd.Flags().StringSliceVarP(&fil.Exclude, "exclude", "E", nil, "Filter events by exclusion. (i.e. \"-E username=, -E event=, -E resource-name=, -E resource-type=, -E arn=\")")
+	listEventsCmd.Flags().StringVar(&fil.Expression, "filter", "", "Filter events with an expression, applied after --include/--exclude. "+
+		"Compare a field (event, username, arn, resource-name, resource-type, region, error-code, source-ip, event-source or a path in the raw event such as requestParameters.instanceId) "+
+		"with ==, !=, =~ (regex), !~ or like (glob), and combine comparisons with and, or, not and parentheses")
 	listEventsCmd.MarkFlagRequired("cluster-id")
 	return listEventsCmd
 }
 
-func (o *writeEventsOptions) getPages(filters WriteEventFilters, region string, requestedPeriod Period) error {
-	cache, err := NewCache(o.log, o.ClusterID)
-	if err != nil {
-		return err
-	}
-	err = cache.EnsureFilenameExist()
-	if err != nil {
-		return err
+// GetWriteEvents retrieves the events of the requested period from all regions of apis.
+// If useCache is set, cached events of the cluster are reused and the newly retrieved
+// events are saved into the cache.
+func GetWriteEvents(log *logrus.Logger, clusterID string, apis []EventGetter, requestedPeriod Period, useCache bool) ([]types.Event, error) {
+	var cache *Cache
+	if useCache {
+		var err error
+		if cache, err = openCache(log, clusterID); err != nil {
+			return nil, err
+		}
 	}
 
-	err = cache.Read()
+	events, newCacheData, err := LookupRegions(log, cache, clusterID, apis, requestedPeriod)
 	if err != nil {
-		return err
-	}
-
-	var fullCacheOverlap bool
-
-	if len(o.missingPeriod) == 0 {
-		o.missingPeriod, fullCacheOverlap = requestedPeriod.DiffMultiple(cache.Period)
+		return nil, err
 	}
 
-	cacheEvents := cache.FilterByPeriod(requestedPeriod)
-	cacheEvents = FilterByRegion(region, cacheEvents)
-
-	sort.Sort(sort.Reverse(Periods(o.missingPeriod)))
-
-	newCacheData := Cache{
-		Period: []Period{},
-		Event:  []types.Event{},
-	}
-
-	for i := 0; i < len(o.missingPeriod); i++ {
-		currentPeriod := o.missingPeriod[i]
-
-		if fullCacheOverlap {
-			o.log.Debugf("Retrieve all events from cache")
-			events := FilterEventsBefore(FilterEventsAfter(
-				cacheEvents, requestedPeriod.StartTime),
-				requestedPeriod.EndTime)
-			o.printer.PrintEvents(Filters(filters, events), o.PrintFields)
-			o.missingPeriod = []Period{}
-			break
-		}
-
-		if i == 0 && !requestedPeriod.EndTime.Equal(currentPeriod.EndTime) {
-			o.log.Debugf("Retrieving 1st Period Event in Cache \n")
-			cachedBefore := FilterEventsBefore(
-				FilterEventsAfter(cacheEvents, currentPeriod.EndTime),
-				requestedPeriod.EndTime,
-			)
-			o.printer.PrintEvents(Filters(filters, cachedBefore), o.PrintFields)
-		}
-
-		var missingEvents []types.Event
-		generator := o.awsAPI.GetEvents(o.ClusterID, currentPeriod)
-		for page := range generator {
-			o.log.Debug("\n Retrieving Pages \n")
-			if page.errors != nil {
-				o.log.Errorf("Error fetching events: %v", page.errors)
-				continue
-			}
-			missingEvents = append(missingEvents, page.AWSEvent...)
+	if cache != nil {
+		log.Debugf("Saving into Cache")
+		if err := cache.Save(newCacheData); err != nil {
+			return nil, err
 		}
-
-		fetchedEvents := Filters(filters, missingEvents)
-		o.printer.PrintEvents(fetchedEvents, o.PrintFields)
-
-		// if startTimePeriod is out of range, create a period starting
-		// at the requested end time to establish the final boundary.
-		var startTimePeriod time.Time
-		if i < len(o.missingPeriod)-1 {
-			startTimePeriod = o.missingPeriod[i+1].EndTime.Add(time.Second)
-			currentPeriod.StartTime = currentPeriod.StartTime.Add(-time.Second)
-		} else {
-			startTimePeriod = requestedPeriod.StartTime
+		if err := enforceCacheLimits(log); err != nil {
+			log.Warnf("Failed to apply the cache eviction policies: %v", err)
 		}
+	}
 
-		o.log.Debugf("\n Checking Cache %v, %v", startTimePeriod, currentPeriod.StartTime)
-
-		cachedBetween := FilterEventsBefore(FilterEventsAfter(
-			cacheEvents, startTimePeriod),
-			currentPeriod.StartTime,
-		)
-		o.printer.PrintEvents(Filters(filters, cachedBetween), o.PrintFields)
+	return events, nil
+}
 
-		newCacheData.Period = append(newCacheData.Period, currentPeriod)
-		newCacheData.Event = append(newCacheData.Event, missingEvents...)
+// openCache returns the cache of the cluster, creating it if needed.
+func openCache(log *logrus.Logger, clusterID string) (*Cache, error) {
+	cache, err := NewCache(log, clusterID)
+	if err != nil {
+		return nil, err
 	}
-
-	o.log.Debugf("Saving into Cache")
-	if err := cache.Save(newCacheData); err != nil {
-		return err
+	if err := cache.EnsureFilenameExist(); err != nil {
+		return nil, err
 	}
-
-	return nil
+	if err := cache.Read(); err != nil {
+		return nil, err
+	}
+	return cache, nil
 }
 
 func (o *writeEventsOptions) preRun(filters WriteEventFilters) error {
@@ -202,6 +179,22 @@ func (o *writeEventsOptions) preRun(filters WriteEventFilters) error {
 	if err := ValidateFormat(o.PrintFields); err != nil {
 		return err
 	}
+	if err := ValidateOutput(o.Output); err != nil {
+		return err
+	}
+	if o.Follow {
+		if o.EndTime != "" {
+			return fmt.Errorf("--follow cannot be used with --until")
+		}
+		if err := validateFollow(o.Output, o.Interval); err != nil {
+			return err
+		}
+	}
+	if filters.Expression != "" {
+		if o.filterExpr, err = ParseFilterExpression(filters.Expression); err != nil {
+			return fmt.Errorf("invalid --filter: %w", err)
+		}
+	}
 
 	log := logrus.New()
 	level, err := logrus.ParseLevel(o.logLevel)
@@ -252,30 +245,83 @@ func (o *writeEventsOptions) run(filters WriteEventFilters) error {
 		return err
 	}
 
-	o.log.Infof("Checking write event history for AWS Account %v as %v from %v until %v from %v Region...\n", accountId, arn, startTime, endTime, cfg.Region)
+	regions, err := ResolveRegions(o.Regions, cfg.Region, func() ([]string, error) {
+		return EnabledRegions(cfg)
+	})
+	if err != nil {
+		return err
+	}
+
+	o.log.Infof("Checking write event history for AWS Account %v as %v from %v until %v from %v Region(s)...\n", accountId, arn, startTime, endTime, strings.Join(regions, ", "))
 
-	o.awsAPI = NewEventAPI(cfg, true, cfg.Region)
-	o.printer = NewPrinter(o.PrintUrl, o.PrintRaw)
+	apis := make([]EventGetter, 0, len(regions))
+	for _, region := range regions {
+		apis = append(apis, NewEventAPI(cfg, true, region))
+	}
+	o.printer = NewPrinter(o.PrintUrl, o.PrintRaw, o.Output)
 
 	requestedPeriod := Period{StartTime: startTime, EndTime: endTime}
 
-	err = o.getPages(filters, cfg.Region, requestedPeriod)
+	events, err := GetWriteEvents(o.log, o.ClusterID, apis, requestedPeriod, o.Cache)
 	if err != nil {
 		return err
 	}
 
-	fmt.Println("")
-	if DEFAULT_REGION != cfg.Region {
-
-		o.log.Infof("Retrieving from %s...", DEFAULT_REGION)
-		defaultAwsAPI := NewEventAPI(cfg, true, DEFAULT_REGION)
-		o.awsAPI = defaultAwsAPI
+	filteredEvents, err := o.filterEvents(filters, events)
+	if err != nil {
+		return err
+	}
+	if !o.Follow {
+		o.printer.PrintEvents(filteredEvents, o.PrintFields)
+		if !o.printer.IsStructured() {
+			fmt.Println("")
+		}
+		return o.printer.Flush()
+	}
 
-		err = o.getPages(filters, DEFAULT_REGION, requestedPeriod)
-		if err != nil {
+	// Like tail, follow mode prints the oldest events first.
+	slices.Reverse(filteredEvents)
+	o.printer.PrintEvents(filteredEvents, o.PrintFields)
+
+	f := &follower{
+		log:      o.log,
+		apis:     apis,
+		interval: o.Interval,
+		seen:     seenEvents{},
+		now:      time.Now,
+		handle: func(newEvents []types.Event) error {
+			filteredEvents, err := o.filterEvents(filters, newEvents)
+			if err != nil {
+				return err
+			}
+			o.printer.PrintEvents(filteredEvents, o.PrintFields)
+			return nil
+		},
+	}
+	f.seen.markNew(events)
+	if o.Cache {
+		if f.cache, err = openCache(o.log, o.ClusterID); err != nil {
 			return err
 		}
 	}
 
-	return nil
+	o.log.Infof("Following new write events every %v, press Ctrl+C to stop...", o.Interval)
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	if err := f.run(ctx); err != nil {
+		return err
+	}
+	if !o.printer.IsStructured() {
+		fmt.Println("")
+	}
+	return o.printer.Flush()
+}
+
+// filterEvents applies the include/exclude filters and the --filter expression.
+func (o *writeEventsOptions) filterEvents(filters WriteEventFilters, events []types.Event) ([]types.Event, error) {
+	filteredEvents := Filters(filters, events)
+	if o.filterExpr == nil {
+		return filteredEvents, nil
+	}
+	return ApplyFilters(filteredEvents, o.filterExpr)
 }
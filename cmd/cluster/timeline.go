@@ -0,0 +1,255 @@
+package cluster
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/cloudtrail/types"
+	sdk "github.com/openshift-online/ocm-sdk-go"
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+	v1 "github.com/openshift-online/ocm-sdk-go/servicelogs/v1"
+	"github.com/openshift/osdctl/cmd/cloudtrail"
+	"github.com/openshift/osdctl/cmd/servicelog"
+	"github.com/openshift/osdctl/pkg/osdCloud"
+	"github.com/openshift/osdctl/pkg/printer"
+	"github.com/openshift/osdctl/pkg/utils"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+const (
+	timelineSourceCloudTrail     = "cloudtrail"
+	timelineSourceServiceLog     = "servicelog"
+	timelineSourceLimitedSupport = "limited-support"
+)
+
+const timelineExample = `
+  # Show what happened to the cluster in the last 24 hours
+  $ osdctl cluster timeline -C <cluster-id>
+
+  # Show a time window as JSON, looking up CloudTrail events in every enabled region
+  $ osdctl cluster timeline -C <cluster-id> --after 2025-07-15,09:00:00 --until 2025-07-15,17:00:00 --regions all -o json`
+
+type timelineOptions struct {
+	clusterID string
+	startTime string
+	endTime   string
+	duration  string
+	regions   []string
+	cache     bool
+	output    string
+}
+
+// TimelineEntry is a single event of the cluster timeline.
+type TimelineEntry struct {
+	Time    time.Time `json:"time"`
+	Source  string    `json:"source"`
+	Summary string    `json:"summary"`
+	Actor   string    `json:"actor,omitempty"`
+	Details string    `json:"details,omitempty"`
+}
+
+func newCmdTimeline() *cobra.Command {
+	o := &timelineOptions{}
+	timelineCmd := &cobra.Command{
+		Use:   "timeline --cluster-id <cluster-identifier>",
+		Short: "Shows CloudTrail write events, service logs and limited support reasons of a cluster in chronological order",
+		Long: `Merges the CloudTrail write events of the cluster AWS account, the OCM service logs and the
+limited support reasons of a cluster into a single chronologically sorted timeline.
+
+CloudTrail events are only retrieved for AWS clusters and share the cache of 'osdctl cloudtrail write-events'.`,
+		Example:           timelineExample,
+		Args:              cobra.NoArgs,
+		DisableAutoGenTag: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return o.run()
+		},
+	}
+
+	timelineCmd.Flags().StringVarP(&o.clusterID, "cluster-id", "C", "", "Cluster ID")
+	timelineCmd.Flags().StringVar(&o.startTime, "after", "", "Specifies all events that occur after the specified time. Format \"YY-MM-DD,hh:mm:ss\".")
+	timelineCmd.Flags().StringVar(&o.endTime, "until", "", "Specifies all events that occur before the specified time. Format \"YY-MM-DD,hh:mm:ss\".")
+	timelineCmd.Flags().StringVar(&o.duration, "since", "24h", "Specifies that only events that occur within the specified time are returned. Valid time units are \"ns\", \"us\" (or \"µs\"), \"ms\", \"s\", \"m\", \"h\".")
+	timelineCmd.Flags().StringSliceVar(&o.regions, "regions", nil, "Regions to look up CloudTrail events in, or \"all\" for every enabled region. Defaults to the cluster region and us-east-1")
+	timelineCmd.Flags().BoolVar(&o.cache, "cache", true, "Enable/Disable the CloudTrail event cache")
+	timelineCmd.Flags().StringVarP(&o.output, "output", "o", "table", "Output format. One of: table, json")
+	_ = timelineCmd.MarkFlagRequired("cluster-id")
+
+	return timelineCmd
+}
+
+func (o *timelineOptions) run() error {
+	if err := utils.IsValidClusterKey(o.clusterID); err != nil {
+		return err
+	}
+	if o.output != "table" && o.output != "json" {
+		return fmt.Errorf("invalid output format: %s (allowed: table, json)", o.output)
+	}
+
+	startTime, endTime, err := cloudtrail.ParseStartEndTime(o.startTime, o.endTime, o.duration)
+	if err != nil {
+		return err
+	}
+	period := cloudtrail.Period{StartTime: startTime, EndTime: endTime}
+
+	connection, err := utils.CreateConnection()
+	if err != nil {
+		return err
+	}
+	defer connection.Close()
+
+	cluster, err := utils.GetClusterAnyStatus(connection, o.clusterID)
+	if err != nil {
+		return err
+	}
+
+	// A source failing to load should not hide the others, the errors are reported at the end.
+	// Only when every source failed is the (empty) timeline meaningless and the command fails.
+	var sourceErrors []error
+	sources := 2
+
+	var events []types.Event
+	if strings.ToUpper(cluster.CloudProvider().ID()) == "AWS" {
+		sources++
+		events, err = o.getCloudTrailEvents(connection, cluster, period)
+		if err != nil {
+			sourceErrors = append(sourceErrors, fmt.Errorf("failed to get cloudtrail events: %w", err))
+		}
+	} else {
+		_, _ = fmt.Fprintln(os.Stderr, "Skipping CloudTrail events, the cluster is not an AWS cluster")
+	}
+
+	serviceLogs, err := servicelog.GetServiceLogsSince(cluster.ID(), startTime, true, false)
+	if err != nil {
+		sourceErrors = append(sourceErrors, fmt.Errorf("failed to get service logs: %w", err))
+	}
+
+	reasons, err := utils.GetClusterLimitedSupportReasons(connection, cluster.ID())
+	if err != nil {
+		sourceErrors = append(sourceErrors, err)
+	}
+
+	if len(sourceErrors) == sources {
+		return fmt.Errorf("failed to get the events of every source: %w", errors.Join(sourceErrors...))
+	}
+
+	timeline := buildTimeline(events, serviceLogs, reasons, period)
+	if err := printTimeline(timeline, o.output); err != nil {
+		return err
+	}
+
+	for _, sourceErr := range sourceErrors {
+		_, _ = fmt.Fprintf(os.Stderr, "[WARN] %v\n", sourceErr)
+	}
+	return nil
+}
+
+func (o *timelineOptions) getCloudTrailEvents(connection *sdk.Connection, cluster *cmv1.Cluster, period cloudtrail.Period) ([]types.Event, error) {
+	cfg, err := osdCloud.CreateAWSV2Config(connection, cluster)
+	if err != nil {
+		return nil, err
+	}
+
+	regions, err := cloudtrail.ResolveRegions(o.regions, cfg.Region, func() ([]string, error) {
+		return cloudtrail.EnabledRegions(cfg)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	apis := make([]cloudtrail.EventGetter, 0, len(regions))
+	for _, region := range regions {
+		apis = append(apis, cloudtrail.NewEventAPI(cfg, true, region))
+	}
+
+	log := logrus.New()
+	log.SetLevel(logrus.WarnLevel)
+	return cloudtrail.GetWriteEvents(log, cluster.ID(), apis, period, o.cache)
+}
+
+// buildTimeline merges the events of all sources that occurred within period, sorted oldest first.
+func buildTimeline(events []types.Event, serviceLogs []*v1.LogEntry, reasons []*cmv1.LimitedSupportReason, period cloudtrail.Period) []TimelineEntry {
+	var timeline []TimelineEntry
+
+	for _, event := range events {
+		record := cloudtrail.NewEventRecord(event)
+		actor := record.Username
+		if record.SessionIssuerArn != "" {
+			actor = record.SessionIssuerArn
+		}
+		var resources []string
+		for _, resource := range record.Resources {
+			resources = append(resources, resource.Type+":"+resource.Name)
+		}
+		timeline = append(timeline, TimelineEntry{
+			Time:    record.EventTime,
+			Source:  timelineSourceCloudTrail,
+			Summary: record.EventName,
+			Actor:   actor,
+			Details: strings.Join(resources, ", "),
+		})
+	}
+
+	for _, serviceLog := range serviceLogs {
+		timeline = append(timeline, TimelineEntry{
+			Time:    serviceLog.CreatedAt().UTC(),
+			Source:  timelineSourceServiceLog,
+			Summary: fmt.Sprintf("[%s] %s", serviceLog.Severity(), serviceLog.Summary()),
+			Actor:   serviceLog.CreatedBy(),
+			Details: serviceLog.ServiceName(),
+		})
+	}
+
+	for _, reason := range reasons {
+		timeline = append(timeline, TimelineEntry{
+			Time:    reason.CreationTimestamp().UTC(),
+			Source:  timelineSourceLimitedSupport,
+			Summary: reason.Summary(),
+			Details: reason.Details(),
+		})
+	}
+
+	inPeriod := timeline[:0]
+	for _, entry := range timeline {
+		if !entry.Time.Before(period.StartTime) && !entry.Time.After(period.EndTime) {
+			inPeriod = append(inPeriod, entry)
+		}
+	}
+
+	sort.SliceStable(inPeriod, func(i, j int) bool {
+		return inPeriod[i].Time.Before(inPeriod[j].Time)
+	})
+	return inPeriod
+}
+
+func printTimeline(timeline []TimelineEntry, output string) error {
+	if output == "json" {
+		if timeline == nil {
+			timeline = []TimelineEntry{}
+		}
+		data, err := json.MarshalIndent(timeline, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+
+	table := printer.NewTablePrinter(os.Stdout, 20, 1, 3, ' ')
+	table.AddRow([]string{"TIME", "SOURCE", "SUMMARY", "ACTOR", "DETAILS"})
+	for _, entry := range timeline {
+		table.AddRow([]string{
+			entry.Time.Format(time.RFC3339),
+			entry.Source,
+			entry.Summary,
+			entry.Actor,
+			entry.Details,
+		})
+	}
+	return table.Flush()
+}
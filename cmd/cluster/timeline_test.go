@@ -0,0 +1,64 @@
+package cluster
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudtrail/types"
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+	v1 "github.com/openshift-online/ocm-sdk-go/servicelogs/v1"
+	"github.com/openshift/osdctl/cmd/cloudtrail"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildTimeline(t *testing.T) {
+	end := time.Date(2025, 7, 15, 12, 0, 0, 0, time.UTC)
+	period := cloudtrail.Period{StartTime: end.Add(-3 * time.Hour), EndTime: end}
+
+	eventTime := end.Add(-2 * time.Hour)
+	events := []types.Event{
+		{
+			EventName:       aws.String("DeleteSecurityGroup"),
+			EventTime:       &eventTime,
+			Username:        aws.String("customer"),
+			CloudTrailEvent: aws.String(`{"eventVersion": "1.08","userIdentity": {"sessionContext": {"sessionIssuer": {"arn": "arn:aws:iam::123456789012:role/admin"}}}}`),
+			Resources:       []types.Resource{{ResourceName: aws.String("sg-123"), ResourceType: aws.String("AWS::EC2::SecurityGroup")}},
+		},
+	}
+
+	serviceLog, err := v1.NewLogEntry().
+		Summary("Cluster is in Limited Support").
+		Severity(v1.SeverityWarning).
+		ServiceName("SREManualAction").
+		CreatedBy("sre@redhat.com").
+		CreatedAt(end.Add(-time.Hour)).
+		Build()
+	assert.NoError(t, err)
+
+	oldServiceLog, err := v1.NewLogEntry().Summary("Too old").CreatedAt(end.Add(-24 * time.Hour)).Build()
+	assert.NoError(t, err)
+
+	reason, err := cmv1.NewLimitedSupportReason().
+		Summary("Security group deleted").
+		Details("The cluster security group was removed").
+		CreationTimestamp(end.Add(-90 * time.Minute)).
+		Build()
+	assert.NoError(t, err)
+
+	timeline := buildTimeline(events, []*v1.LogEntry{serviceLog, oldServiceLog}, []*cmv1.LimitedSupportReason{reason}, period)
+
+	assert.Len(t, timeline, 3)
+	assert.Equal(t, timelineSourceCloudTrail, timeline[0].Source)
+	assert.Equal(t, "DeleteSecurityGroup", timeline[0].Summary)
+	assert.Equal(t, "arn:aws:iam::123456789012:role/admin", timeline[0].Actor)
+	assert.Equal(t, "AWS::EC2::SecurityGroup:sg-123", timeline[0].Details)
+	assert.Equal(t, timelineSourceLimitedSupport, timeline[1].Source)
+	assert.Equal(t, timelineSourceServiceLog, timeline[2].Source)
+	assert.Equal(t, "[Warning] Cluster is in Limited Support", timeline[2].Summary)
+}
+
+func TestBuildTimelineEmpty(t *testing.T) {
+	period := cloudtrail.Period{StartTime: time.Now().Add(-time.Hour), EndTime: time.Now()}
+	assert.Empty(t, buildTimeline(nil, nil, nil, period))
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/url"
+	"os"
 	"time"
 
 	k8s "github.com/openshift/osdctl/pkg/k8s"
@@ -26,6 +27,9 @@ var (
 	containerList []string
 	statusList    []string
 	console       bool
+	follow        bool
+	interval      time.Duration
+	noColor       bool
 )
 
 const (
@@ -34,6 +38,9 @@ const (
 
   This command also prints the Dynatrace URL and the corresponding DQL in the output.
 
+  With --follow, the query is polled again every --interval over a sliding time window and only the
+  new logs are printed, like oc logs -f. On a terminal, the logs are coloured by status unless --no-color is set.
+
 `
 
 	logsCmdExample = `
@@ -60,6 +67,9 @@ const (
 
   # Restrict return of logs to those that contain a specific phrase
   $ osdctl dt logs alertmanager-main-0 -n openshift-monitoring --contains <phrase>
+
+  # Stream the error logs of the pod kube-apiserver-0 of the HCP cluster as they arrive, polling every minute
+  $ osdctl dt logs kube-apiserver-0 --status error --cluster-id <cluster-id> --follow --interval 1m
 `
 )
 
@@ -107,6 +117,12 @@ func NewCmdLogs() *cobra.Command {
 	logsCmd.Flags().StringSliceVar(&containerList, "container", []string{}, "Container name(s) (comma-separated)")
 	logsCmd.Flags().StringSliceVarP(&namespaceList, "namespace", "n", []string{}, "Namespace(s) (comma-separated)")
 	logsCmd.Flags().BoolVar(&console, "console", false, "Print the url to the dynatrace web console instead of outputting the logs")
+	logsCmd.Flags().BoolVarP(&follow, "follow", "f", false, "Keep polling Dynatrace and stream the new logs as they arrive")
+	logsCmd.Flags().DurationVar(&interval, "interval", 30*time.Second, "Interval between two polls of Dynatrace with --follow")
+	logsCmd.Flags().BoolVar(&noColor, "no-color", false, "Do not colourise the logs by status")
+	logsCmd.MarkFlagsMutuallyExclusive("follow", "from")
+	logsCmd.MarkFlagsMutuallyExclusive("follow", "to")
+	logsCmd.MarkFlagsMutuallyExclusive("follow", "console")
 
 	return logsCmd
 }
@@ -157,6 +173,10 @@ func main(clusterID string) error {
 		return fmt.Errorf("invalid sort order, expecting 'asc' or 'desc'")
 	}
 
+	if follow && interval < time.Second {
+		return fmt.Errorf("invalid interval %v, expecting at least 1s", interval)
+	}
+
 	query, err := GetQuery(hcpCluster, fromVar, toVar, since)
 	if err != nil {
 		return fmt.Errorf("failed to build query for Dynatrace %v", err)
@@ -186,6 +206,13 @@ func main(clusterID string) error {
 		return nil
 	}
 
+	if follow {
+		if dryRun {
+			return nil
+		}
+		return followLogs(hcpCluster, os.Stdout)
+	}
+
 	accessToken, err := getStorageAccessToken()
 	if err != nil {
 		return fmt.Errorf("failed to acquire access token %v", err)
@@ -204,6 +231,11 @@ func main(clusterID string) error {
 }
 
 func GetQuery(hcpCluster HCPCluster, fromVar time.Time, toVar time.Time, since int) (query DTQuery, error error) {
+	return buildLogsQuery(hcpCluster, fromVar, toVar, since, sortOrder)
+}
+
+// buildLogsQuery builds the logs query of the filters of the command, sorted by timestamp in order.
+func buildLogsQuery(hcpCluster HCPCluster, fromVar time.Time, toVar time.Time, since int, order string) (DTQuery, error) {
 	q := DTQuery{}
 
 	if !fromVar.IsZero() && !toVar.IsZero() {
@@ -212,12 +244,14 @@ func GetQuery(hcpCluster HCPCluster, fromVar time.Time, toVar time.Time, since i
 		q.InitLogs(since).Cluster(hcpCluster.managementClusterName)
 	}
 
+	// Copy the namespaces so that building the query again does not repeat the HCP namespace
+	namespaces := append([]string{}, namespaceList...)
 	if hcpCluster.hcpNamespace != "" {
-		namespaceList = append(namespaceList, hcpCluster.hcpNamespace)
+		namespaces = append(namespaces, hcpCluster.hcpNamespace)
 	}
 
-	if len(namespaceList) > 0 {
-		q.Namespaces(namespaceList)
+	if len(namespaces) > 0 {
+		q.Namespaces(namespaces)
 	}
 
 	if len(nodeList) > 0 {
@@ -240,8 +274,8 @@ func GetQuery(hcpCluster HCPCluster, fromVar time.Time, toVar time.Time, since i
 		q.ContainsPhrase(contains)
 	}
 
-	if sortOrder != "" {
-		q, err := q.Sort(sortOrder)
+	if order != "" {
+		q, err := q.Sort(order)
 		if err != nil {
 			return *q, err
 		}
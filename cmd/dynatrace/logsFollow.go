@@ -0,0 +1,181 @@
+package dynatrace
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/fatih/color"
+	"golang.org/x/term"
+)
+
+const (
+	// followOverlap is how far back each poll looks before the previous window, so that logs
+	// ingested late by Dynatrace are not missed. Already printed logs are de-duplicated.
+	followOverlap = 2 * time.Minute
+
+	// followTokenRefresh is the age after which the access token is renewed before it expires.
+	followTokenRefresh = 4 * time.Minute
+)
+
+// logDeduper remembers the logs already printed by timestamp and content.
+type logDeduper struct {
+	seen map[string]time.Time
+}
+
+func newLogDeduper() *logDeduper {
+	return &logDeduper{seen: map[string]time.Time{}}
+}
+
+// isNew returns true and records the log if it was not seen before.
+func (d *logDeduper) isNew(record LogContent, timestamp time.Time) bool {
+	key := record.Timestamp + "\x00" + record.Content
+	if _, ok := d.seen[key]; ok {
+		return false
+	}
+	d.seen[key] = timestamp
+	return true
+}
+
+// forget drops the logs older than before, which the following polls cannot return anymore.
+func (d *logDeduper) forget(before time.Time) {
+	for key, timestamp := range d.seen {
+		if timestamp.Before(before) {
+			delete(d.seen, key)
+		}
+	}
+}
+
+// nextWindowStart returns the start of the window of the next poll. When the poll returned limit
+// records, the window is truncated and the next one restarts at the last record.
+func nextWindowStart(records []LogContent, limit int, to time.Time) time.Time {
+	if limit > 0 && len(records) >= limit {
+		if last, err := parseLogTimestamp(records[len(records)-1].Timestamp); err == nil {
+			return last
+		}
+	}
+	return to.Add(-followOverlap)
+}
+
+func parseLogTimestamp(timestamp string) (time.Time, error) {
+	return time.Parse(time.RFC3339Nano, timestamp)
+}
+
+// statusColor returns the colour of the logs of a status, nil to keep the default colour.
+func statusColor(status string) *color.Color {
+	switch strings.ToUpper(status) {
+	case "ERROR", "FATAL", "CRITICAL", "ALERT", "EMERGENCY", "SEVERE":
+		return color.New(color.FgRed)
+	case "WARN", "WARNING":
+		return color.New(color.FgYellow)
+	case "DEBUG", "TRACE":
+		return color.New(color.Faint)
+	default:
+		return nil
+	}
+}
+
+// colorLogs reports whether the logs written to out are coloured: only on a terminal, and
+// unless --no-color or NO_COLOR is set.
+func colorLogs(out io.Writer) bool {
+	file, ok := out.(*os.File)
+	return ok && !noColor && !color.NoColor && term.IsTerminal(int(file.Fd()))
+}
+
+func printLogRecord(out io.Writer, record LogContent, colored bool) {
+	if c := statusColor(record.Status); colored && c != nil {
+		c.EnableColor()
+		fmt.Fprintln(out, c.Sprint(record.Content))
+		return
+	}
+	fmt.Fprintln(out, record.Content)
+}
+
+func fetchLogRecords(dtURL string, accessToken string, query string) ([]LogContent, error) {
+	requestToken, err := getDTQueryExecution(dtURL, accessToken, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute query %v", err)
+	}
+	resp, err := getDTPollResults(dtURL, requestToken, accessToken)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get query results %v", err)
+	}
+
+	var dtPollRes DTLogsPollResult
+	if err := json.Unmarshal([]byte(resp), &dtPollRes); err != nil {
+		return nil, err
+	}
+	return dtPollRes.Result.Records, nil
+}
+
+// followLogs polls the logs of the cluster until interrupted, printing the new logs of each poll.
+// The first poll prints the last --tail logs of --since, the next ones the logs since the previous poll.
+func followLogs(hcpCluster HCPCluster, out io.Writer) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	var accessToken string
+	var tokenAcquired time.Time
+	deduper := newLogDeduper()
+	colored := colorLogs(out)
+
+	to := time.Now().UTC()
+	from := to.Add(-time.Duration(since) * time.Hour)
+	first := true
+	for {
+		if time.Since(tokenAcquired) > followTokenRefresh {
+			token, err := getStorageAccessToken()
+			if err != nil {
+				return fmt.Errorf("failed to acquire access token %v", err)
+			}
+			accessToken, tokenAcquired = token, time.Now()
+		}
+
+		// The first poll fetches the most recent logs, the next ones the oldest new logs first
+		order := "asc"
+		if first {
+			order = "desc"
+		}
+		query, err := buildLogsQuery(hcpCluster, from, to, since, order)
+		if err != nil {
+			return fmt.Errorf("failed to build query for Dynatrace %v", err)
+		}
+
+		records, err := fetchLogRecords(hcpCluster.DynatraceURL, accessToken, query.Build())
+		if err != nil {
+			// Keep following, the same window is polled again
+			fmt.Fprintf(os.Stderr, "Failed to poll logs, retrying in %v: %v\n", interval, err)
+		} else {
+			if first {
+				for i, j := 0, len(records)-1; i < j; i, j = i+1, j-1 {
+					records[i], records[j] = records[j], records[i]
+				}
+				first = false
+			}
+			for _, record := range records {
+				timestamp, err := parseLogTimestamp(record.Timestamp)
+				if err != nil {
+					timestamp = to
+				}
+				if deduper.isNew(record, timestamp) {
+					printLogRecord(out, record, colored)
+				}
+			}
+			from = nextWindowStart(records, tail, to)
+			deduper.forget(from)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(interval):
+		}
+		to = time.Now().UTC()
+	}
+}
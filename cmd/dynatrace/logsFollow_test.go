@@ -0,0 +1,120 @@
+package dynatrace
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+)
+
+func TestLogDeduper(t *testing.T) {
+	deduper := newLogDeduper()
+	ts := time.Date(2025, 6, 15, 4, 0, 0, 0, time.UTC)
+	first := LogContent{Timestamp: "2025-06-15T04:00:00.000000000Z", Content: "starting"}
+
+	if !deduper.isNew(first, ts) {
+		t.Errorf("expected the first log to be new")
+	}
+	if deduper.isNew(first, ts) {
+		t.Errorf("expected the same log to be a duplicate")
+	}
+	sameTime := LogContent{Timestamp: first.Timestamp, Content: "started"}
+	if !deduper.isNew(sameTime, ts) {
+		t.Errorf("expected a log of the same timestamp with another content to be new")
+	}
+	sameContent := LogContent{Timestamp: "2025-06-15T04:00:01.000000000Z", Content: "starting"}
+	if !deduper.isNew(sameContent, ts.Add(time.Second)) {
+		t.Errorf("expected a log of the same content at another timestamp to be new")
+	}
+
+	deduper.forget(ts.Add(time.Second))
+	if len(deduper.seen) != 1 {
+		t.Errorf("expected 1 remembered log after forget, got %d", len(deduper.seen))
+	}
+}
+
+func TestNextWindowStart(t *testing.T) {
+	to := time.Date(2025, 6, 15, 4, 10, 0, 0, time.UTC)
+	records := []LogContent{
+		{Timestamp: "2025-06-15T04:01:00Z"},
+		{Timestamp: "2025-06-15T04:02:30.5Z"},
+	}
+
+	tests := []struct {
+		name     string
+		records  []LogContent
+		limit    int
+		expected time.Time
+	}{
+		{"no records", nil, 2, to.Add(-followOverlap)},
+		{"all records of the window", records, 3, to.Add(-followOverlap)},
+		{"truncated window", records, 2, time.Date(2025, 6, 15, 4, 2, 30, 500000000, time.UTC)},
+		{"no limit", records, 0, to.Add(-followOverlap)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := nextWindowStart(tt.records, tt.limit, to)
+			if !got.Equal(tt.expected) {
+				t.Errorf("expected: %v\ngot: %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestPrintLogRecord(t *testing.T) {
+	tests := []struct {
+		status   string
+		colored  bool
+		expected string
+	}{
+		{"ERROR", true, "\x1b[31m"},
+		{"warn", true, "\x1b[33m"},
+		{"INFO", false, ""},
+		{"", false, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.status, func(t *testing.T) {
+			var out bytes.Buffer
+			printLogRecord(&out, LogContent{Status: tt.status, Content: "message"}, true)
+			if tt.colored && !strings.HasPrefix(out.String(), tt.expected) {
+				t.Errorf("expected %q to start with %q", out.String(), tt.expected)
+			}
+			if !tt.colored && out.String() != "message\n" {
+				t.Errorf("expected an uncoloured log, got %q", out.String())
+			}
+
+			// Logs not written to a terminal are never coloured
+			out.Reset()
+			printLogRecord(&out, LogContent{Status: tt.status, Content: "message"}, colorLogs(&out))
+			if out.String() != "message\n" {
+				t.Errorf("expected an uncoloured log, got %q", out.String())
+			}
+		})
+	}
+}
+
+func TestBuildLogsQueryKeepsNamespaces(t *testing.T) {
+	namespaceList = []string{"openshift-monitoring"}
+	defer func() { namespaceList = nil }()
+	hcpCluster := HCPCluster{managementClusterName: "hs-mc-1", hcpNamespace: "ocm-production-abc123-my-hcp"}
+	from := time.Date(2025, 6, 15, 4, 0, 0, 0, time.UTC)
+
+	first, err := buildLogsQuery(hcpCluster, from, from.Add(time.Minute), 1, "desc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := buildLogsQuery(hcpCluster, from, from.Add(time.Minute), 1, "desc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.Build() != second.Build() {
+		t.Errorf("expected the same query when built twice\nfirst: %s\nsecond: %s", first.Build(), second.Build())
+	}
+	if len(namespaceList) != 1 {
+		t.Errorf("expected the namespaces of the flag to be unchanged, got %v", namespaceList)
+	}
+	if !strings.Contains(first.Build(), "sort timestamp desc") {
+		t.Errorf("expected the query to be sorted descending: %s", first.Build())
+	}
+}
@@ -0,0 +1,456 @@
+package dynatrace
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	k8s "github.com/openshift/osdctl/pkg/k8s"
+	"github.com/openshift/osdctl/pkg/printer"
+	"github.com/spf13/cobra"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+)
+
+const (
+	metricsOutputTable = "table"
+	metricsOutputCSV   = "csv"
+
+	// timeframePlaceholder is replaced by the window and interval of the metrics queries
+	timeframePlaceholder = "${TIMEFRAME}"
+
+	// namespaceFilter scopes the metric queries to the HCP namespace on its management cluster
+	namespaceFilter = `k8s.cluster.name == "${MC}" and k8s.namespace.name == "${HCP_NAMESPACE}"`
+
+	sparklineWidth = 40
+
+	metricsCmdDescription = `
+  Fetch control plane metrics of a HCP cluster from Dynatrace and print their p50, p95 and max over the window.
+
+  The metrics are queried on the management cluster of the HCP, scoped to the HCP namespace, with one
+  series per pod (and container for the container metrics). Use --list to print the available metrics.
+`
+
+	metricsCmdExample = `
+  # Summarize all the control plane metrics of the last hour
+  $ osdctl dt metrics --cluster-id <cluster-id>
+
+  # Summarize the etcd latencies of the last 6 hours with a sparkline of each series
+  $ osdctl dt metrics --cluster-id <cluster-id> --metric etcd-wal-fsync,etcd-backend-commit --since 6 --sparkline
+
+  # Export the time series of the container CPU throttling as CSV
+  $ osdctl dt metrics --cluster-id <cluster-id> --metric container-cpu-throttling -o csv > throttling.csv
+`
+)
+
+// HCPMetric is a control plane metric queried from Dynatrace.
+type HCPMetric struct {
+	Name        string
+	Description string
+	Unit        string
+	// Query is the DQL timeseries query of the metric, returning its series in the field "value".
+	// ${TIMEFRAME} is replaced by the window and interval, the cluster placeholders as in 'dt query'.
+	Query string
+}
+
+// HCPMetrics are the control plane metrics available to the metrics command.
+var HCPMetrics = []HCPMetric{
+	{
+		Name:        "etcd-wal-fsync",
+		Description: "Average etcd WAL fsync latency",
+		Unit:        "s",
+		Query: `timeseries {sum = sum(etcd_disk_wal_fsync_duration_seconds_sum, rate:1s), count = sum(etcd_disk_wal_fsync_duration_seconds_count, rate:1s)}, by:{k8s.pod.name}, filter:{` + namespaceFilter + `}, ` + timeframePlaceholder + `
+| fieldsAdd value = sum[] / count[]
+| fieldsRemove sum, count`,
+	},
+	{
+		Name:        "etcd-backend-commit",
+		Description: "Average etcd backend commit latency",
+		Unit:        "s",
+		Query: `timeseries {sum = sum(etcd_disk_backend_commit_duration_seconds_sum, rate:1s), count = sum(etcd_disk_backend_commit_duration_seconds_count, rate:1s)}, by:{k8s.pod.name}, filter:{` + namespaceFilter + `}, ` + timeframePlaceholder + `
+| fieldsAdd value = sum[] / count[]
+| fieldsRemove sum, count`,
+	},
+	{
+		Name:        "apiserver-request-rate",
+		Description: "kube-apiserver requests per second",
+		Unit:        "req/s",
+		Query:       `timeseries value = sum(apiserver_request_total, rate:1s), by:{k8s.pod.name}, filter:{` + namespaceFilter + `}, ` + timeframePlaceholder,
+	},
+	{
+		Name:        "apiserver-5xx-rate",
+		Description: "kube-apiserver 5xx responses per second",
+		Unit:        "req/s",
+		Query:       `timeseries value = sum(apiserver_request_total, rate:1s), by:{k8s.pod.name}, filter:{` + namespaceFilter + ` and startsWith(code, "5")}, ` + timeframePlaceholder,
+	},
+	{
+		Name:        "container-cpu-throttling",
+		Description: "CPU throttling of the control plane containers",
+		Unit:        "millicores",
+		Query:       `timeseries value = sum(dt.kubernetes.container.cpu_throttled), by:{k8s.pod.name, k8s.container.name}, filter:{` + namespaceFilter + `}, ` + timeframePlaceholder,
+	},
+	{
+		Name:        "container-cpu-usage",
+		Description: "CPU usage of the control plane containers",
+		Unit:        "millicores",
+		Query:       `timeseries value = sum(dt.kubernetes.container.cpu_usage), by:{k8s.pod.name, k8s.container.name}, filter:{` + namespaceFilter + `}, ` + timeframePlaceholder,
+	},
+	{
+		Name:        "container-memory",
+		Description: "Memory working set of the control plane containers",
+		Unit:        "bytes",
+		Query:       `timeseries value = sum(dt.kubernetes.container.memory_working_set), by:{k8s.pod.name, k8s.container.name}, filter:{` + namespaceFilter + `}, ` + timeframePlaceholder,
+	},
+}
+
+type metricsOptions struct {
+	clusterID string
+	metrics   []string
+	since     int
+	interval  time.Duration
+	output    string
+	sparkline bool
+	list      bool
+	dryRun    bool
+}
+
+// MetricSeries is a time series of a metric, one value per interval. Missing values are nil.
+type MetricSeries struct {
+	Metric   HCPMetric
+	Labels   string
+	Start    time.Time
+	Interval time.Duration
+	Values   []*float64
+}
+
+// MetricSummary are the statistics of the values of a series.
+type MetricSummary struct {
+	Count int
+	P50   float64
+	P95   float64
+	Max   float64
+}
+
+func NewCmdMetrics() *cobra.Command {
+	opts := &metricsOptions{}
+	metricsCmd := &cobra.Command{
+		Use:               "metrics --cluster-id <cluster-identifier>",
+		Short:             "Fetch control plane metrics of a HCP cluster from Dynatrace",
+		Long:              metricsCmdDescription,
+		Example:           metricsCmdExample,
+		Args:              cobra.NoArgs,
+		DisableAutoGenTag: true,
+		Run: func(cmd *cobra.Command, args []string) {
+			cmdutil.CheckErr(opts.run())
+		},
+	}
+
+	metricsCmd.Flags().StringVarP(&opts.clusterID, "cluster-id", "C", "", "Name or Internal ID of the cluster (defaults to current cluster context)")
+	metricsCmd.Flags().StringSliceVar(&opts.metrics, "metric", []string{}, "Metric(s) to fetch (comma-separated, defaults to all the metrics of --list)")
+	metricsCmd.Flags().IntVar(&opts.since, "since", 1, "Number of hours (integer) of the window of the metrics")
+	metricsCmd.Flags().DurationVar(&opts.interval, "interval", time.Minute, "Interval between two values of the time series")
+	metricsCmd.Flags().StringVarP(&opts.output, "output", "o", metricsOutputTable, "Output format. One of: table (summary statistics), csv (time series)")
+	metricsCmd.Flags().BoolVar(&opts.sparkline, "sparkline", false, "Add a sparkline of each series to the table output")
+	metricsCmd.Flags().BoolVar(&opts.list, "list", false, "List the available metrics")
+	metricsCmd.Flags().BoolVar(&opts.dryRun, "dry-run", false, "Only print the queries without fetching any metrics")
+
+	return metricsCmd
+}
+
+func (o *metricsOptions) run() error {
+	if o.list {
+		return printHCPMetrics(os.Stdout)
+	}
+	if o.output != metricsOutputTable && o.output != metricsOutputCSV {
+		return fmt.Errorf("invalid output format: %s (allowed: table, csv)", o.output)
+	}
+	if o.sparkline && o.output != metricsOutputTable {
+		return fmt.Errorf("--sparkline is only supported with the table output")
+	}
+	if o.since <= 0 {
+		return fmt.Errorf("invalid time duration")
+	}
+	if o.interval < time.Second {
+		return fmt.Errorf("invalid interval %v, expecting at least 1s", o.interval)
+	}
+	metrics, err := selectHCPMetrics(o.metrics)
+	if err != nil {
+		return err
+	}
+
+	if o.clusterID == "" {
+		o.clusterID, err = k8s.GetCurrentCluster()
+		if err != nil {
+			return err
+		}
+	}
+	hcpCluster, err := FetchClusterDetails(o.clusterID)
+	if err != nil {
+		return fmt.Errorf("failed to acquire cluster details %v", err)
+	}
+	if hcpCluster.hcpNamespace == "" {
+		return fmt.Errorf("metrics are only supported for HCP clusters")
+	}
+
+	queries := make([]string, 0, len(metrics))
+	for _, metric := range metrics {
+		query, err := metricQuery(metric, hcpCluster, o.since, o.interval)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(os.Stderr, query)
+		queries = append(queries, query)
+	}
+	if o.dryRun {
+		return nil
+	}
+
+	accessToken, err := getScopedAccessToken(DTStorageVaultPath, DTQueryScopes)
+	if err != nil {
+		return fmt.Errorf("failed to acquire access token %v", err)
+	}
+
+	var series []MetricSeries
+	for i, metric := range metrics {
+		requestToken, err := getDTQueryExecution(hcpCluster.DynatraceURL, accessToken, queries[i])
+		if err != nil {
+			return fmt.Errorf("failed to execute query of %s %v", metric.Name, err)
+		}
+		resp, err := getDTPollResults(hcpCluster.DynatraceURL, requestToken, accessToken)
+		if err != nil {
+			return fmt.Errorf("failed to get results of %s %v", metric.Name, err)
+		}
+		records, err := parseQueryRecords(resp)
+		if err != nil {
+			return err
+		}
+		metricSeries, err := parseMetricSeries(metric, records)
+		if err != nil {
+			return err
+		}
+		series = append(series, metricSeries...)
+	}
+
+	if o.output == metricsOutputCSV {
+		return printMetricsCSV(os.Stdout, series)
+	}
+	return printMetricsSummary(os.Stdout, series, o.sparkline)
+}
+
+// selectHCPMetrics returns the metrics of names, all of them if names is empty.
+func selectHCPMetrics(names []string) ([]HCPMetric, error) {
+	if len(names) == 0 {
+		return HCPMetrics, nil
+	}
+	var metrics []HCPMetric
+	for _, name := range names {
+		found := false
+		for _, metric := range HCPMetrics {
+			if metric.Name == name {
+				metrics = append(metrics, metric)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("unknown metric %s, see --list for the available metrics", name)
+		}
+	}
+	return metrics, nil
+}
+
+func printHCPMetrics(out io.Writer) error {
+	table := printer.NewTablePrinter(out, 20, 1, 3, ' ')
+	table.AddRow([]string{"NAME", "UNIT", "DESCRIPTION"})
+	for _, metric := range HCPMetrics {
+		table.AddRow([]string{metric.Name, metric.Unit, metric.Description})
+	}
+	return table.Flush()
+}
+
+// metricQuery returns the DQL query of the metric for the cluster over the last since hours.
+func metricQuery(metric HCPMetric, hcpCluster HCPCluster, since int, interval time.Duration) (string, error) {
+	timeframe := fmt.Sprintf("from:now()-%dh, interval:%ds", since, int(interval.Seconds()))
+	return ResolvePlaceholders(strings.ReplaceAll(metric.Query, timeframePlaceholder, timeframe), hcpCluster)
+}
+
+// parseMetricSeries converts the records of a timeseries query to series, labelled by their dimensions.
+func parseMetricSeries(metric HCPMetric, records []map[string]interface{}) ([]MetricSeries, error) {
+	series := make([]MetricSeries, 0, len(records))
+	for _, record := range records {
+		s := MetricSeries{Metric: metric}
+
+		if timeframe, ok := record["timeframe"].(map[string]interface{}); ok {
+			start, err := time.Parse(time.RFC3339Nano, recordValue(timeframe["start"]))
+			if err != nil {
+				return nil, fmt.Errorf("invalid timeframe of %s: %v", metric.Name, err)
+			}
+			s.Start = start
+		}
+		if interval := recordValue(record["interval"]); interval != "" {
+			nanos, err := strconv.ParseInt(interval, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid interval of %s: %v", metric.Name, err)
+			}
+			s.Interval = time.Duration(nanos)
+		}
+
+		values, _ := record["value"].([]interface{})
+		for _, value := range values {
+			if value == nil {
+				s.Values = append(s.Values, nil)
+				continue
+			}
+			f, err := strconv.ParseFloat(recordValue(value), 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid value of %s: %v", metric.Name, err)
+			}
+			s.Values = append(s.Values, &f)
+		}
+
+		var labels []string
+		for _, column := range recordColumns([]map[string]interface{}{record}) {
+			if column == "timeframe" || column == "interval" || column == "value" {
+				continue
+			}
+			labels = append(labels, recordValue(record[column]))
+		}
+		s.Labels = strings.Join(labels, "/")
+
+		series = append(series, s)
+	}
+	return series, nil
+}
+
+// Summarize returns the p50, p95 and max of the values of the series, ignoring the missing ones.
+func (s MetricSeries) Summarize() MetricSummary {
+	values := make([]float64, 0, len(s.Values))
+	for _, value := range s.Values {
+		if value != nil {
+			values = append(values, *value)
+		}
+	}
+	if len(values) == 0 {
+		return MetricSummary{}
+	}
+	sort.Float64s(values)
+	return MetricSummary{
+		Count: len(values),
+		P50:   percentile(values, 50),
+		P95:   percentile(values, 95),
+		Max:   values[len(values)-1],
+	}
+}
+
+// percentile returns the nearest-rank percentile p of the sorted values.
+func percentile(sorted []float64, p float64) float64 {
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
+
+// Sparkline draws the values of the series in width characters, averaging the values of each character.
+// Missing values are drawn as spaces.
+func (s MetricSeries) Sparkline(width int) string {
+	const levels = "_.-~=+*#"
+	if len(s.Values) == 0 || width <= 0 {
+		return ""
+	}
+	if width > len(s.Values) {
+		width = len(s.Values)
+	}
+
+	buckets := make([]*float64, width)
+	for i := range buckets {
+		from, to := i*len(s.Values)/width, (i+1)*len(s.Values)/width
+		sum, count := 0.0, 0
+		for _, value := range s.Values[from:to] {
+			if value != nil {
+				sum += *value
+				count++
+			}
+		}
+		if count > 0 {
+			avg := sum / float64(count)
+			buckets[i] = &avg
+		}
+	}
+
+	low, high := math.Inf(1), math.Inf(-1)
+	for _, bucket := range buckets {
+		if bucket != nil {
+			low, high = math.Min(low, *bucket), math.Max(high, *bucket)
+		}
+	}
+
+	var sparkline strings.Builder
+	for _, bucket := range buckets {
+		switch {
+		case bucket == nil:
+			sparkline.WriteByte(' ')
+		case high == low:
+			sparkline.WriteByte(levels[0])
+		default:
+			level := int((*bucket - low) / (high - low) * float64(len(levels)-1))
+			sparkline.WriteByte(levels[level])
+		}
+	}
+	return sparkline.String()
+}
+
+func formatMetricValue(value float64) string {
+	return strconv.FormatFloat(value, 'g', 4, 64)
+}
+
+func printMetricsSummary(out io.Writer, series []MetricSeries, sparkline bool) error {
+	if len(series) == 0 {
+		fmt.Fprintln(out, "No metrics found.")
+		return nil
+	}
+
+	table := printer.NewTablePrinter(out, 20, 1, 3, ' ')
+	headers := []string{"METRIC", "SERIES", "UNIT", "P50", "P95", "MAX"}
+	if sparkline {
+		headers = append(headers, "SPARKLINE")
+	}
+	table.AddRow(headers)
+	for _, s := range series {
+		summary := s.Summarize()
+		row := []string{s.Metric.Name, s.Labels, s.Metric.Unit, "-", "-", "-"}
+		if summary.Count > 0 {
+			row = []string{s.Metric.Name, s.Labels, s.Metric.Unit, formatMetricValue(summary.P50), formatMetricValue(summary.P95), formatMetricValue(summary.Max)}
+		}
+		if sparkline {
+			row = append(row, s.Sparkline(sparklineWidth))
+		}
+		table.AddRow(row)
+	}
+	return table.Flush()
+}
+
+func printMetricsCSV(out io.Writer, series []MetricSeries) error {
+	writer := csv.NewWriter(out)
+	if err := writer.Write([]string{"metric", "series", "timestamp", "value"}); err != nil {
+		return err
+	}
+	for _, s := range series {
+		for i, value := range s.Values {
+			if value == nil {
+				continue
+			}
+			timestamp := s.Start.Add(time.Duration(i) * s.Interval).UTC().Format(time.RFC3339)
+			if err := writer.Write([]string{s.Metric.Name, s.Labels, timestamp, strconv.FormatFloat(*value, 'g', -1, 64)}); err != nil {
+				return err
+			}
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
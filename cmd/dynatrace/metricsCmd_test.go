@@ -0,0 +1,141 @@
+package dynatrace
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func floats(values ...float64) []*float64 {
+	result := make([]*float64, 0, len(values))
+	for i := range values {
+		result = append(result, &values[i])
+	}
+	return result
+}
+
+func TestMetricQuery(t *testing.T) {
+	hcpCluster := HCPCluster{managementClusterName: "hs-mc-1", hcpNamespace: "ocm-production-abc123-my-hcp"}
+	metrics, err := selectHCPMetrics([]string{"apiserver-request-rate"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	query, err := metricQuery(metrics[0], hcpCluster, 6, 5*time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `timeseries value = sum(apiserver_request_total, rate:1s), by:{k8s.pod.name}, filter:{k8s.cluster.name == "hs-mc-1" and k8s.namespace.name == "ocm-production-abc123-my-hcp"}, from:now()-6h, interval:300s`
+	if query != expected {
+		t.Errorf("expected: %s\ngot: %s", expected, query)
+	}
+
+	if _, err := selectHCPMetrics([]string{"unknown"}); err == nil {
+		t.Errorf("expected an error for an unknown metric")
+	}
+	if all, _ := selectHCPMetrics(nil); len(all) != len(HCPMetrics) {
+		t.Errorf("expected all the metrics by default, got %d", len(all))
+	}
+}
+
+func TestParseMetricSeries(t *testing.T) {
+	resp := `{"state":"SUCCEEDED","result":{"records":[{
+		"timeframe":{"start":"2025-06-15T04:00:00.000000000Z","end":"2025-06-15T04:03:00.000000000Z"},
+		"interval":"60000000000",
+		"k8s.pod.name":"etcd-0",
+		"value":[0.002,null,0.004]
+	}]}}`
+	records, err := parseQueryRecords(resp)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	series, err := parseMetricSeries(HCPMetrics[0], records)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(series) != 1 {
+		t.Fatalf("expected 1 series, got %d", len(series))
+	}
+	s := series[0]
+	if s.Labels != "etcd-0" {
+		t.Errorf("expected labels etcd-0, got %s", s.Labels)
+	}
+	if s.Interval != time.Minute || !s.Start.Equal(time.Date(2025, 6, 15, 4, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected timeframe %v every %v", s.Start, s.Interval)
+	}
+	if len(s.Values) != 3 || s.Values[1] != nil || *s.Values[2] != 0.004 {
+		t.Errorf("unexpected values %v", s.Values)
+	}
+
+	var out bytes.Buffer
+	if err := printMetricsCSV(&out, series); err != nil {
+		t.Fatal(err)
+	}
+	expected := "metric,series,timestamp,value\netcd-wal-fsync,etcd-0,2025-06-15T04:00:00Z,0.002\netcd-wal-fsync,etcd-0,2025-06-15T04:02:00Z,0.004\n"
+	if out.String() != expected {
+		t.Errorf("expected: %s\ngot: %s", expected, out.String())
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	values := make([]float64, 0, 100)
+	for i := 1; i <= 100; i++ {
+		values = append(values, float64(i))
+	}
+	summary := MetricSeries{Values: append(floats(values...), nil)}.Summarize()
+	if summary.Count != 100 || summary.P50 != 50 || summary.P95 != 95 || summary.Max != 100 {
+		t.Errorf("unexpected summary %+v", summary)
+	}
+
+	if summary := (MetricSeries{Values: []*float64{nil}}).Summarize(); summary.Count != 0 {
+		t.Errorf("expected an empty summary, got %+v", summary)
+	}
+}
+
+func TestSparkline(t *testing.T) {
+	tests := []struct {
+		name     string
+		values   []*float64
+		width    int
+		expected string
+	}{
+		{"ramp", floats(0, 1, 2, 3, 4, 5, 6, 7), 8, "_.-~=+*#"},
+		{"downsampled", floats(0, 0, 7, 7), 2, "_#"},
+		{"flat", floats(3, 3, 3), 10, "___"},
+		{"missing values", []*float64{floats(1)[0], nil, floats(2)[0]}, 3, "_ #"},
+		{"empty", nil, 10, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := MetricSeries{Values: tt.values}.Sparkline(tt.width)
+			if got != tt.expected {
+				t.Errorf("expected: %q\ngot: %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestPrintMetricsSummary(t *testing.T) {
+	series := []MetricSeries{
+		{Metric: HCPMetrics[0], Labels: "etcd-0", Values: floats(0.001, 0.002, 0.010)},
+		{Metric: HCPMetrics[0], Labels: "etcd-1", Values: []*float64{nil}},
+	}
+	var out bytes.Buffer
+	if err := printMetricsSummary(&out, series, true); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("expected a header and 2 rows, got:\n%s", out.String())
+	}
+	for _, field := range []string{"etcd-0", "0.002", "0.01", "__#"} {
+		if !strings.Contains(lines[1], field) {
+			t.Errorf("expected %q in %q", field, lines[1])
+		}
+	}
+	if !strings.Contains(lines[2], "-") {
+		t.Errorf("expected an empty summary for a series without values: %q", lines[2])
+	}
+}
@@ -0,0 +1,378 @@
+package dynatrace
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	k8s "github.com/openshift/osdctl/pkg/k8s"
+	"github.com/openshift/osdctl/pkg/printer"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// DTQueryLibraryKey is the config key of the path of the saved query library
+	DTQueryLibraryKey string = "dt_query_library"
+	DTQueryScopes     string = "storage:logs:read storage:events:read storage:buckets:read storage:metrics:read storage:spans:read"
+
+	queryOutputTable = "table"
+	queryOutputJSON  = "json"
+	queryOutputCSV   = "csv"
+
+	queryCmdDescription = `
+  Run a DQL query against the Dynatrace tenant of a HCP or management cluster and print the records.
+
+  The query is either passed as argument or read from a named query of the library file
+  (--library, 'dt_query_library' in the osdctl config, or ~/.config/osdctl-dql-queries.yaml):
+
+    queries:
+      hcp-errors:
+        description: Error logs of the hosted control plane
+        query: |
+          fetch logs, from:now()-1h
+          | filter k8s.namespace.name == "${HCP_NAMESPACE}" and status == "ERROR"
+
+  The following placeholders are resolved from the cluster:
+    ${CLUSTER_ID}       internal ID of the cluster
+    ${CLUSTER_NAME}     name of the cluster
+    ${EXTERNAL_ID}      external ID of the cluster
+    ${MC}               name of the management cluster
+    ${HCP_NAMESPACE}    namespace of the hosted control plane
+    ${HOSTED_NAMESPACE} namespace of the hosted cluster resources
+    ${KLUSTERLET_NS}    klusterlet namespace of the cluster
+`
+
+	queryCmdExample = `
+  # Run a raw DQL query for the cluster in the current context
+  $ osdctl dt query 'fetch logs | filter k8s.namespace.name == "${HCP_NAMESPACE}" | limit 10'
+
+  # Run a saved query of the library as CSV
+  $ osdctl dt query --name hcp-errors --cluster-id <cluster-id> -o csv
+
+  # List the saved queries
+  $ osdctl dt query --list
+`
+)
+
+type queryOptions struct {
+	clusterID string
+	name      string
+	library   string
+	output    string
+	list      bool
+	dryRun    bool
+}
+
+// SavedQuery is a named DQL query of the library.
+type SavedQuery struct {
+	Description string `json:"description"`
+	Query       string `json:"query"`
+}
+
+// QueryLibrary is the file listing the saved DQL queries.
+type QueryLibrary struct {
+	Queries map[string]SavedQuery `json:"queries"`
+}
+
+// DTQueryPollResult is the result of a DQL query with arbitrary records.
+type DTQueryPollResult struct {
+	State  string `json:"state"`
+	Result struct {
+		Records []map[string]interface{} `json:"records"`
+	} `json:"result"`
+}
+
+var placeholderRegex = regexp.MustCompile(`\$\{([A-Z_]+)\}`)
+
+func NewCmdQuery() *cobra.Command {
+	opts := &queryOptions{}
+	queryCmd := &cobra.Command{
+		Use:               "query [DQL]",
+		Short:             "Run a raw or saved DQL query against Dynatrace",
+		Long:              queryCmdDescription,
+		Example:           queryCmdExample,
+		Args:              cobra.MaximumNArgs(1),
+		DisableAutoGenTag: true,
+		Run: func(cmd *cobra.Command, args []string) {
+			cmdutil.CheckErr(opts.run(args))
+		},
+	}
+
+	queryCmd.Flags().StringVarP(&opts.clusterID, "cluster-id", "C", "", "Name or Internal ID of the cluster (defaults to current cluster context)")
+	queryCmd.Flags().StringVar(&opts.name, "name", "", "Name of the saved query of the library to run")
+	queryCmd.Flags().StringVar(&opts.library, "library", "", "Library file of saved queries (defaults to 'dt_query_library' in the config or ~/.config/osdctl-dql-queries.yaml)")
+	queryCmd.Flags().StringVarP(&opts.output, "output", "o", queryOutputTable, "Output format. One of: table, json, csv")
+	queryCmd.Flags().BoolVar(&opts.list, "list", false, "List the saved queries of the library")
+	queryCmd.Flags().BoolVar(&opts.dryRun, "dry-run", false, "Only print the resolved query without running it")
+	queryCmd.MarkFlagsMutuallyExclusive("name", "list")
+
+	return queryCmd
+}
+
+func (o *queryOptions) run(args []string) error {
+	if o.output != queryOutputTable && o.output != queryOutputJSON && o.output != queryOutputCSV {
+		return fmt.Errorf("invalid output format: %s (allowed: table, json, csv)", o.output)
+	}
+
+	if o.list {
+		library, err := LoadQueryLibrary(o.libraryPath())
+		if err != nil {
+			return err
+		}
+		return printQueryLibrary(os.Stdout, library)
+	}
+
+	query, err := o.query(args)
+	if err != nil {
+		return err
+	}
+
+	if o.clusterID == "" {
+		o.clusterID, err = k8s.GetCurrentCluster()
+		if err != nil {
+			return err
+		}
+	}
+	hcpCluster, err := FetchClusterDetails(o.clusterID)
+	if err != nil {
+		return fmt.Errorf("failed to acquire cluster details %v", err)
+	}
+
+	query, err = ResolvePlaceholders(query, hcpCluster)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(os.Stderr, query)
+	if o.dryRun {
+		return nil
+	}
+
+	accessToken, err := getScopedAccessToken(DTStorageVaultPath, DTQueryScopes)
+	if err != nil {
+		return fmt.Errorf("failed to acquire access token %v", err)
+	}
+	requestToken, err := getDTQueryExecution(hcpCluster.DynatraceURL, accessToken, query)
+	if err != nil {
+		return fmt.Errorf("failed to execute query %v", err)
+	}
+	resp, err := getDTPollResults(hcpCluster.DynatraceURL, requestToken, accessToken)
+	if err != nil {
+		return fmt.Errorf("failed to get query results %v", err)
+	}
+
+	records, err := parseQueryRecords(resp)
+	if err != nil {
+		return err
+	}
+	return printQueryRecords(os.Stdout, records, o.output)
+}
+
+// query returns the raw query argument or the saved query named --name.
+func (o *queryOptions) query(args []string) (string, error) {
+	if len(args) > 0 && o.name != "" {
+		return "", fmt.Errorf("a query and --name cannot be used together")
+	}
+	if len(args) > 0 {
+		return args[0], nil
+	}
+	if o.name == "" {
+		return "", fmt.Errorf("a DQL query or --name is required")
+	}
+
+	library, err := LoadQueryLibrary(o.libraryPath())
+	if err != nil {
+		return "", err
+	}
+	saved, ok := library.Queries[o.name]
+	if !ok {
+		return "", fmt.Errorf("query %s not found in the library", o.name)
+	}
+	return saved.Query, nil
+}
+
+func (o *queryOptions) libraryPath() string {
+	if o.library != "" {
+		return o.library
+	}
+	if viper.IsSet(DTQueryLibraryKey) {
+		return viper.GetString(DTQueryLibraryKey)
+	}
+	// Next to the osdctl config file, ~/.config/osdctl is the config file itself
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(configDir, "osdctl-dql-queries.yaml")
+}
+
+// LoadQueryLibrary reads the saved queries of the library file.
+func LoadQueryLibrary(path string) (QueryLibrary, error) {
+	library := QueryLibrary{}
+	data, err := os.ReadFile(path) //#nosec G304 -- path is provided by the user
+	if err != nil {
+		return library, fmt.Errorf("failed to read query library: %v", err)
+	}
+	if err := yaml.UnmarshalStrict(data, &library); err != nil {
+		return library, fmt.Errorf("failed to parse query library %s: %v", path, err)
+	}
+	for name, saved := range library.Queries {
+		if strings.TrimSpace(saved.Query) == "" {
+			return library, fmt.Errorf("query %s of the library %s is empty", name, path)
+		}
+	}
+	return library, nil
+}
+
+func printQueryLibrary(out io.Writer, library QueryLibrary) error {
+	names := make([]string, 0, len(library.Queries))
+	for name := range library.Queries {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	table := printer.NewTablePrinter(out, 20, 1, 3, ' ')
+	table.AddRow([]string{"NAME", "DESCRIPTION"})
+	for _, name := range names {
+		table.AddRow([]string{name, library.Queries[name].Description})
+	}
+	return table.Flush()
+}
+
+// ResolvePlaceholders replaces the ${...} placeholders of the query with the details of the cluster.
+func ResolvePlaceholders(query string, hcpCluster HCPCluster) (string, error) {
+	values := map[string]string{
+		"CLUSTER_ID":       hcpCluster.internalID,
+		"CLUSTER_NAME":     hcpCluster.name,
+		"EXTERNAL_ID":      hcpCluster.externalID,
+		"MC":               hcpCluster.managementClusterName,
+		"HCP_NAMESPACE":    hcpCluster.hcpNamespace,
+		"HOSTED_NAMESPACE": hcpCluster.hostedNS,
+		"KLUSTERLET_NS":    hcpCluster.klusterletNS,
+	}
+
+	var errs []string
+	resolved := placeholderRegex.ReplaceAllStringFunc(query, func(placeholder string) string {
+		name := placeholderRegex.FindStringSubmatch(placeholder)[1]
+		value, known := values[name]
+		if !known {
+			errs = append(errs, fmt.Sprintf("unknown placeholder %s", placeholder))
+		} else if value == "" {
+			errs = append(errs, fmt.Sprintf("placeholder %s cannot be resolved for this cluster", placeholder))
+		}
+		return value
+	})
+	if len(errs) > 0 {
+		return "", fmt.Errorf("%s", strings.Join(errs, ", "))
+	}
+	return resolved, nil
+}
+
+func parseQueryRecords(resp string) ([]map[string]interface{}, error) {
+	var result DTQueryPollResult
+	decoder := json.NewDecoder(strings.NewReader(resp))
+	// Keep the precision of the numbers of the records
+	decoder.UseNumber()
+	if err := decoder.Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to parse query results: %v", err)
+	}
+	return result.Result.Records, nil
+}
+
+// recordColumns returns the fields of the records, timestamp first and the others sorted.
+func recordColumns(records []map[string]interface{}) []string {
+	fields := map[string]bool{}
+	for _, record := range records {
+		for field := range record {
+			fields[field] = true
+		}
+	}
+
+	columns := make([]string, 0, len(fields))
+	for field := range fields {
+		if field != "timestamp" {
+			columns = append(columns, field)
+		}
+	}
+	sort.Strings(columns)
+	if fields["timestamp"] {
+		columns = append([]string{"timestamp"}, columns...)
+	}
+	return columns
+}
+
+// recordValue formats a field of a record, nested values as JSON.
+func recordValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	default:
+		var buf bytes.Buffer
+		encoder := json.NewEncoder(&buf)
+		encoder.SetEscapeHTML(false)
+		if err := encoder.Encode(v); err != nil {
+			return fmt.Sprint(v)
+		}
+		return strings.TrimSpace(buf.String())
+	}
+}
+
+func printQueryRecords(out io.Writer, records []map[string]interface{}, output string) error {
+	if output == queryOutputJSON {
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		if records == nil {
+			records = []map[string]interface{}{}
+		}
+		return encoder.Encode(records)
+	}
+
+	columns := recordColumns(records)
+	rows := make([][]string, 0, len(records))
+	for _, record := range records {
+		row := make([]string, 0, len(columns))
+		for _, column := range columns {
+			row = append(row, recordValue(record[column]))
+		}
+		rows = append(rows, row)
+	}
+
+	if output == queryOutputCSV {
+		writer := csv.NewWriter(out)
+		if err := writer.Write(columns); err != nil {
+			return err
+		}
+		if err := writer.WriteAll(rows); err != nil {
+			return err
+		}
+		return writer.Error()
+	}
+
+	if len(records) == 0 {
+		fmt.Fprintln(out, "No records found.")
+		return nil
+	}
+	table := printer.NewTablePrinter(out, 20, 1, 3, ' ')
+	headers := make([]string, 0, len(columns))
+	for _, column := range columns {
+		headers = append(headers, strings.ToUpper(column))
+	}
+	table.AddRow(headers)
+	for _, row := range rows {
+		table.AddRow(row)
+	}
+	return table.Flush()
+}
@@ -0,0 +1,181 @@
+package dynatrace
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestResolvePlaceholders(t *testing.T) {
+	hcpCluster := HCPCluster{
+		internalID:            "abc123",
+		name:                  "my-hcp",
+		managementClusterName: "hs-mc-1",
+		hcpNamespace:          "ocm-production-abc123-my-hcp",
+	}
+
+	tests := []struct {
+		name        string
+		query       string
+		expected    string
+		expectedErr string
+	}{
+		{
+			name:     "known placeholders",
+			query:    `fetch logs | filter dt.kubernetes.cluster.name == "${MC}" and k8s.namespace.name == "${HCP_NAMESPACE}" | fieldsAdd id = "${CLUSTER_ID}"`,
+			expected: `fetch logs | filter dt.kubernetes.cluster.name == "hs-mc-1" and k8s.namespace.name == "ocm-production-abc123-my-hcp" | fieldsAdd id = "abc123"`,
+		},
+		{
+			name:     "no placeholders",
+			query:    "fetch spans | limit 1",
+			expected: "fetch spans | limit 1",
+		},
+		{
+			name:        "unknown placeholder",
+			query:       `fetch logs | filter x == "${UNKNOWN}"`,
+			expectedErr: "unknown placeholder ${UNKNOWN}",
+		},
+		{
+			name:        "unresolved placeholder",
+			query:       `fetch logs | filter x == "${KLUSTERLET_NS}"`,
+			expectedErr: "placeholder ${KLUSTERLET_NS} cannot be resolved",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ResolvePlaceholders(tt.query, hcpCluster)
+			if tt.expectedErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectedErr) {
+					t.Fatalf("expected error containing %q, got %v", tt.expectedErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.expected {
+				t.Errorf("expected: %s\ngot: %s", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestLoadQueryLibrary(t *testing.T) {
+	dir := t.TempDir()
+
+	valid := filepath.Join(dir, "valid.yaml")
+	content := `queries:
+  hcp-errors:
+    description: Error logs of the hosted control plane
+    query: |
+      fetch logs | filter k8s.namespace.name == "${HCP_NAMESPACE}"
+`
+	if err := os.WriteFile(valid, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	library, err := LoadQueryLibrary(valid)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if library.Queries["hcp-errors"].Description != "Error logs of the hosted control plane" {
+		t.Errorf("unexpected library: %+v", library)
+	}
+
+	opts := queryOptions{name: "hcp-errors", library: valid}
+	query, err := opts.query(nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.HasPrefix(query, "fetch logs") {
+		t.Errorf("unexpected query: %s", query)
+	}
+	opts.name = "missing"
+	if _, err := opts.query(nil); err == nil {
+		t.Error("expected an error for a missing query")
+	}
+
+	for name, content := range map[string]string{
+		"unknown field": "queries:\n  q:\n    dql: fetch logs\n",
+		"empty query":   "queries:\n  q:\n    description: nothing\n",
+	} {
+		path := filepath.Join(dir, strings.ReplaceAll(name, " ", "-")+".yaml")
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := LoadQueryLibrary(path); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+
+	if _, err := LoadQueryLibrary(filepath.Join(dir, "missing.yaml")); err == nil {
+		t.Error("expected an error for a missing library")
+	}
+}
+
+func TestDefaultLibraryPathWithConfigFile(t *testing.T) {
+	// The osdctl config file takes the osdctl entry of the config directory
+	configDir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", configDir)
+	if err := os.WriteFile(filepath.Join(configDir, "osdctl"), []byte("prod_jumprole_account_id: 123\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(configDir, "osdctl-dql-queries.yaml"), []byte("queries:\n  q:\n    query: fetch logs\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	opts := queryOptions{name: "q"}
+	query, err := opts.query(nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if query != "fetch logs" {
+		t.Errorf("unexpected query: %s", query)
+	}
+}
+
+func TestPrintQueryRecords(t *testing.T) {
+	resp := `{"state":"SUCCEEDED","result":{"records":[
+		{"timestamp":"2024-05-01T10:00:00Z","status":"ERROR","count":12345678901234567890,"labels":{"app":"a,b"}},
+		{"timestamp":"2024-05-01T10:01:00Z","status":"INFO","extra":null}
+	]}}`
+	records, err := parseQueryRecords(resp)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var out bytes.Buffer
+	if err := printQueryRecords(&out, records, queryOutputCSV); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := `timestamp,count,extra,labels,status
+2024-05-01T10:00:00Z,12345678901234567890,,"{""app"":""a,b""}",ERROR
+2024-05-01T10:01:00Z,,,,INFO
+`
+	if out.String() != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, out.String())
+	}
+
+	out.Reset()
+	if err := printQueryRecords(&out, records, queryOutputTable); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.HasPrefix(out.String(), "TIMESTAMP") || !strings.Contains(out.String(), "ERROR") {
+		t.Errorf("unexpected table:\n%s", out.String())
+	}
+
+	out.Reset()
+	if err := printQueryRecords(&out, records, queryOutputJSON); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(out.String(), `"count": 12345678901234567890`) {
+		t.Errorf("unexpected json:\n%s", out.String())
+	}
+
+	out.Reset()
+	if err := printQueryRecords(&out, nil, queryOutputJSON); err != nil || strings.TrimSpace(out.String()) != "[]" {
+		t.Errorf("expected an empty JSON array, got %q (%v)", out.String(), err)
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"io"
 	"net/http"
 	"net/url"
+	"os"
 	"time"
 
 	"github.com/spf13/viper"
@@ -183,7 +184,9 @@ type LogResult struct {
 }
 
 type LogContent struct {
-	Content string `json:"content"`
+	Timestamp string `json:"timestamp"`
+	Status    string `json:"status"`
+	Content   string `json:"content"`
 }
 
 type DTEventsPollResult struct {
@@ -285,7 +288,7 @@ func getDTQueryExecution(dtURL string, accessToken string, query string) (reqTok
 }
 
 func getDTPollResults(dtURL string, requestToken string, accessToken string) (respBody string, error error) {
-	var dtPollRes DTLogsPollResult
+	var dtPollRes DTPollResult
 	reqData := url.Values{
 		"request-token": {requestToken},
 	}.Encode()
@@ -380,12 +383,13 @@ func getLogs(dtURL string, accessToken string, requestToken string, dumpWriter i
 		return err
 	}
 
+	colored := dumpWriter == nil && colorLogs(os.Stdout)
 	for _, result := range dtPollRes.Result.Records {
 		content := result.Content
 		if dumpWriter != nil {
 			dumpWriter.Write([]byte(fmt.Sprintf("%s\n", content)))
 		} else {
-			fmt.Println(content)
+			printLogRecord(os.Stdout, result, colored)
 		}
 	}
 
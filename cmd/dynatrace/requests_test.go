@@ -0,0 +1,29 @@
+package dynatrace
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestGetDTPollResultsAnyRecords(t *testing.T) {
+	// Metric records have no string timestamp or status, only the state of the poll is decoded
+	body := `{"state":"SUCCEEDED","progress":100,"result":{"records":[{"timestamp":1718424000,"status":{"code":1},"avg(dt.cpu)":[0.5]}]}}`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasSuffix(r.URL.Path, "query:poll") || r.URL.Query().Get("request-token") != "token" {
+			http.Error(w, "unexpected request", http.StatusBadRequest)
+			return
+		}
+		_, _ = w.Write([]byte(body))
+	}))
+	defer server.Close()
+
+	resp, err := getDTPollResults(server.URL+"/", "token", "access-token")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp != body {
+		t.Errorf("expected the response body, got %s", resp)
+	}
+}
@@ -17,6 +17,8 @@ func NewCmdDynatrace() *cobra.Command {
 	dtCmd.AddCommand(newCmdURL())
 	dtCmd.AddCommand(newCmdDashboard())
 	dtCmd.AddCommand(NewCmdHCPMustGather())
+	dtCmd.AddCommand(NewCmdQuery())
+	dtCmd.AddCommand(NewCmdMetrics())
 
 	return dtCmd
 }
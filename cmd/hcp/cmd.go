@@ -18,6 +18,7 @@ func NewCmdHCP() *cobra.Command {
 	hcp.AddCommand(backup.NewCmdBackup())
 	hcp.AddCommand(getcpautoscalingstatus.NewCmdGetCPAutoscalingStatus())
 	hcp.AddCommand(mustgather.NewCmdMustGather())
+	hcp.AddCommand(mustgather.NewCmdGatherBundle())
 	hcp.AddCommand(forceupgrade.NewCmdForceUpgrade())
 	hcp.AddCommand(status.NewCmdStatus())
 
@@ -28,8 +28,20 @@ type forceUpgradeOptions struct {
 	dryRun             bool
 	serviceLogTemplate string
 
+	// Staged rollout flags
+	waves            string
+	waveTimeout      time.Duration
+	pollInterval     time.Duration
+	failureThreshold int
+	stateFile        string
+	resume           bool
+
 	// Parsed cluster IDs (populated during validation)
 	clusterIDs []string
+
+	// Parsed wave sizes and rollout state (populated during validation)
+	waveSizes []int
+	state     *rolloutState
 }
 
 // Service log template mappings
@@ -64,7 +76,17 @@ This serves two purposes:
 1. Force upgrades to latest z-stream of the SAME y-stream for critical bug fixes
 2. Force upgrades to latest z-stream of a SUBSEQUENT y-stream when current y-stream goes out of support
 
-Example: --target-y 4.15 will upgrade to the latest available 4.15.z version (e.g., 4.15.32).`,
+Example: --target-y 4.15 will upgrade to the latest available 4.15.z version (e.g., 4.15.32).
+
+STAGED ROLLOUT:
+With --waves, the clusters are upgraded in waves of the given sizes, in the order of the clusters file.
+Between waves, the command waits for the upgrade policy of each cluster of the wave to complete and checks
+the health of the HCP cluster until it is healthy. The rollout stops when more than --failure-threshold
+percent of the clusters of a wave failed to be scheduled or failed to upgrade, or had not completed their
+upgrade or were still unhealthy when --wave-timeout expired.
+
+The progress of the rollout is kept in --state-file, so that --resume continues where it stopped. On resume,
+a pending cluster which already has a manual upgrade policy to the target Y-stream is recorded as scheduled.`,
 		Example: `  # Force upgrade without service log
   osdctl hcp force-upgrade -C cluster123 --target-y 4.15
 
@@ -77,6 +99,12 @@ Example: --target-y 4.15 will upgrade to the latest available 4.15.z version (e.
   # Force upgrade with custom service log template file
   osdctl hcp force-upgrade -C cluster123 --target-y 4.15 --send-service-log /path/to/custom-template.json
 
+  # Staged rollout: a canary cluster, then 10, then 50, then the rest, stopping if more than 10% of a wave fails
+  osdctl hcp force-upgrade --clusters-file clusters.json --target-y 4.16 --waves 1,10,50,rest --state-file rollout.json
+
+  # Continue a stopped or interrupted staged rollout
+  osdctl hcp force-upgrade --target-y 4.16 --resume --state-file rollout.json
+
 `,
 		Args:              cobra.NoArgs,
 		DisableAutoGenTag: true,
@@ -97,6 +125,14 @@ Example: --target-y 4.15 will upgrade to the latest available 4.15.z version (e.
 	// Service log flags
 	cmd.Flags().StringVar(&opts.serviceLogTemplate, "send-service-log", "", "Send service log notification after scheduling upgrade. Specify template name (e.g., 'end-of-support') or file path (e.g., '/path/to/template.json')")
 
+	// Staged rollout flags
+	cmd.Flags().StringVar(&opts.waves, "waves", "", "Comma-separated wave sizes for a staged rollout, the last one may be 'rest' (e.g., 1,10,50,rest)")
+	cmd.Flags().DurationVar(&opts.waveTimeout, "wave-timeout", 2*time.Hour, "Maximum time to wait for the upgrades of a wave to complete before the next wave")
+	cmd.Flags().DurationVar(&opts.pollInterval, "poll-interval", 2*time.Minute, "Interval between checks of the upgrades of a wave")
+	cmd.Flags().IntVar(&opts.failureThreshold, "failure-threshold", 10, "Stop the rollout when more than this percentage of the clusters of a wave failed")
+	cmd.Flags().StringVar(&opts.stateFile, "state-file", "force-upgrade-state.json", "File keeping the progress of a staged rollout")
+	cmd.Flags().BoolVar(&opts.resume, "resume", false, "Resume the staged rollout of --state-file, with its clusters and waves")
+
 	// Mark required flags
 	_ = cmd.MarkFlagRequired("target-y")
 
@@ -104,6 +140,10 @@ Example: --target-y 4.15 will upgrade to the latest available 4.15.z version (e.
 }
 
 func (o *forceUpgradeOptions) validate() error {
+	if o.resume {
+		return o.validateResume()
+	}
+
 	// Exactly one cluster targeting method must be provided
 	if o.clusterID == "" && o.clustersFile == "" {
 		return fmt.Errorf("no cluster identifier has been found, please specify either --cluster-id or --clusters-file")
@@ -113,23 +153,8 @@ func (o *forceUpgradeOptions) validate() error {
 		return fmt.Errorf("cannot specify both --cluster-id and --clusters-file, choose one")
 	}
 
-	if o.nextRunMinutes < 6 {
-		return fmt.Errorf("next-run-minutes must be at least 6 minutes")
-	}
-
-	// Service log validation
-	if o.serviceLogTemplate != "" {
-		// Check if it's a template name
-		if _, exists := serviceLogTemplates[o.serviceLogTemplate]; !exists {
-			// If not a template name, check if it's a valid file path
-			if _, err := os.Stat(o.serviceLogTemplate); os.IsNotExist(err) {
-				var validTemplates []string
-				for template := range serviceLogTemplates {
-					validTemplates = append(validTemplates, template)
-				}
-				return fmt.Errorf("service log value '%s' is neither a valid template name %v nor an existing file", o.serviceLogTemplate, validTemplates)
-			}
-		}
+	if err := o.validateCommon(); err != nil {
+		return err
 	}
 
 	// Validate cluster ID format when using single cluster ID
@@ -154,6 +179,84 @@ func (o *forceUpgradeOptions) validate() error {
 		o.clusterIDs = []string{o.clusterID}
 	}
 
+	// Parse and validate the waves of a staged rollout
+	if o.waves != "" {
+		waveSizes, err := parseWaves(o.waves)
+		if err != nil {
+			return err
+		}
+		if !o.dryRun {
+			if _, err := os.Stat(o.stateFile); err == nil {
+				return fmt.Errorf("state file %s already exists, use --resume to continue its rollout or remove it to start a new one", o.stateFile)
+			}
+		}
+		o.waveSizes = waveSizes
+	}
+
+	return nil
+}
+
+// validateResume validates the options of a resumed staged rollout, whose clusters and waves come from the state file
+func (o *forceUpgradeOptions) validateResume() error {
+	if o.clusterID != "" || o.clustersFile != "" {
+		return fmt.Errorf("cannot specify --cluster-id or --clusters-file with --resume, the clusters of the state file are used")
+	}
+
+	if o.waves != "" {
+		return fmt.Errorf("cannot specify --waves with --resume, the waves of the state file are used")
+	}
+
+	if err := o.validateCommon(); err != nil {
+		return err
+	}
+
+	state, err := loadRolloutState(o.stateFile)
+	if err != nil {
+		return err
+	}
+
+	if state.TargetYStream != o.targetYStream {
+		return fmt.Errorf("target Y-stream '%s' does not match '%s' of the rollout in %s", o.targetYStream, state.TargetYStream, o.stateFile)
+	}
+
+	if state.finished() {
+		return fmt.Errorf("the rollout in %s is already finished", o.stateFile)
+	}
+
+	o.state = state
+	o.clusterIDs = state.clusterIDs()
+	return nil
+}
+
+// validateCommon validates the options shared by new and resumed force upgrades
+func (o *forceUpgradeOptions) validateCommon() error {
+	if o.nextRunMinutes < 6 {
+		return fmt.Errorf("next-run-minutes must be at least 6 minutes")
+	}
+
+	if o.failureThreshold < 0 || o.failureThreshold > 100 {
+		return fmt.Errorf("failure-threshold must be between 0 and 100")
+	}
+
+	if (o.waves != "" || o.resume) && (o.waveTimeout <= 0 || o.pollInterval <= 0) {
+		return fmt.Errorf("wave-timeout and poll-interval must be positive")
+	}
+
+	// Service log validation
+	if o.serviceLogTemplate != "" {
+		// Check if it's a template name
+		if _, exists := serviceLogTemplates[o.serviceLogTemplate]; !exists {
+			// If not a template name, check if it's a valid file path
+			if _, err := os.Stat(o.serviceLogTemplate); os.IsNotExist(err) {
+				var validTemplates []string
+				for template := range serviceLogTemplates {
+					validTemplates = append(validTemplates, template)
+				}
+				return fmt.Errorf("service log value '%s' is neither a valid template name %v nor an existing file", o.serviceLogTemplate, validTemplates)
+			}
+		}
+	}
+
 	return nil
 }
 
@@ -178,11 +281,23 @@ func (o *forceUpgradeOptions) Run() error {
 		return nil
 	}
 
+	// Start a staged rollout in the order of the given clusters
+	if o.state == nil && len(o.waveSizes) > 0 {
+		o.state, err = newRolloutState(o.targetYStream, o.waveSizes, orderClusters(clusters, o.clusterIDs))
+		if err != nil {
+			return err
+		}
+	}
+
 	// Display cluster list and service log preview before processing
 	if err := o.printPreProcessingSummary(clusters); err != nil {
 		return fmt.Errorf("failed to display pre-processing summary: %w", err)
 	}
 
+	if o.state != nil {
+		o.printRolloutPlan()
+	}
+
 	// Ask for confirmation before proceeding (unless in dry-run mode)
 	if !o.dryRun {
 		if !ocmutils.ConfirmPrompt() {
@@ -192,38 +307,99 @@ func (o *forceUpgradeOptions) Run() error {
 		fmt.Println()
 	}
 
-	var successful, failed []string
-	var serviceLogSuccessful, serviceLogFailed []string
+	if o.state != nil {
+		return o.runWaves(ocmClient, clusters)
+	}
 
+	results := make([]clusterState, len(clusters))
 	for i, cluster := range clusters {
 		fmt.Printf("\n[%d/%d] Processing cluster: %s (%s)\n", i+1, len(clusters), cluster.ID(), cluster.Name())
 
-		targetVersion, err := o.processCluster(ocmClient, cluster)
-		if err != nil {
-			failed = append(failed, fmt.Sprintf("%s: %s", cluster.ExternalID(), err.Error()))
-			fmt.Printf("  ⚠️  Failed to create upgrade policy: %v\n", err)
-		} else {
-			successful = append(successful, cluster.ExternalID())
+		results[i] = newClusterState(cluster, 1)
+		o.upgradeCluster(ocmClient, cluster, &results[i])
+	}
 
-			if o.serviceLogTemplate != "" {
-				if o.dryRun {
-					fmt.Printf("  📧 DRY-RUN: Would send service log notification\n")
-					serviceLogSuccessful = append(serviceLogSuccessful, cluster.ExternalID())
-					continue
-				}
+	o.printSummary(results)
+	return nil
+}
 
-				if err := sendUpgradeServiceLog(ocmClient, cluster, o.serviceLogTemplate, targetVersion); err != nil {
-					serviceLogFailed = append(serviceLogFailed, fmt.Sprintf("%s: %s", cluster.ExternalID(), err.Error()))
-					fmt.Printf("  ⚠️  Failed to send service log: %v\n", err)
-				} else {
-					serviceLogSuccessful = append(serviceLogSuccessful, cluster.ExternalID())
-					fmt.Printf("  📧 Service log notification sent successfully\n")
-				}
-			}
-		}
+// upgradeCluster schedules the force upgrade of the cluster, sends the service log and records the outcome in c
+func (o *forceUpgradeOptions) upgradeCluster(ocmClient *sdk.Connection, cluster *v1.Cluster, c *clusterState) {
+	if o.resume && o.adoptScheduledPolicy(ocmClient, cluster, c) {
+		return
+	}
+
+	targetVersion, policyID, err := o.processCluster(ocmClient, cluster)
+	if err != nil {
+		c.Status = clusterScheduleFailed
+		c.Error = err.Error()
+		fmt.Printf("  ⚠️  Failed to create upgrade policy: %v\n", err)
+		return
+	}
+
+	c.Status = clusterScheduled
+	c.TargetVersion = targetVersion
+	c.PolicyID = policyID
+
+	if o.serviceLogTemplate == "" {
+		return
+	}
+
+	if o.dryRun {
+		fmt.Printf("  📧 DRY-RUN: Would send service log notification\n")
+		c.ServiceLogSent = true
+		return
+	}
+
+	if err := sendUpgradeServiceLog(ocmClient, cluster, o.serviceLogTemplate, targetVersion); err != nil {
+		c.ServiceLogError = err.Error()
+		fmt.Printf("  ⚠️  Failed to send service log: %v\n", err)
+	} else {
+		c.ServiceLogSent = true
+		fmt.Printf("  📧 Service log notification sent successfully\n")
+	}
+}
+
+// adoptScheduledPolicy records the manual upgrade policy to the target Y-stream of a cluster as scheduled, when a
+// previous run was interrupted after creating it but before saving the state. It returns false if there is none.
+func (o *forceUpgradeOptions) adoptScheduledPolicy(ocmClient *sdk.Connection, cluster *v1.Cluster, c *clusterState) bool {
+	policiesResponse, err := ocmClient.ClustersMgmt().V1().Clusters().Cluster(cluster.ID()).
+		ControlPlane().UpgradePolicies().List().Send()
+	if err != nil {
+		return false
+	}
+
+	policy := rolloutPolicy(policiesResponse.Items().Slice(), o.targetYStream)
+	if policy == nil {
+		return false
+	}
+
+	c.Status = clusterScheduled
+	c.TargetVersion = policy.Version()
+	c.PolicyID = policy.ID()
+	fmt.Printf("  ✅ Found the force upgrade to %s scheduled by the interrupted rollout (ID: %s)\n", policy.Version(), policy.ID())
+	if o.serviceLogTemplate != "" {
+		// The previous run may have stopped before or after sending it, it is not sent twice
+		c.ServiceLogError = "unknown, the rollout was interrupted after scheduling the upgrade"
+		fmt.Printf("  ⚠️  Not sending the service log again, check whether the cluster received it\n")
 	}
+	return true
+}
 
-	o.printSummary(successful, failed, serviceLogSuccessful, serviceLogFailed)
+// rolloutPolicy returns the manual upgrade policy to a version of the target Y-stream, if any
+func rolloutPolicy(policies []*v1.ControlPlaneUpgradePolicy, targetYStream string) *v1.ControlPlaneUpgradePolicy {
+	for _, policy := range policies {
+		if policy.ScheduleType() != v1.ScheduleTypeManual {
+			continue
+		}
+		version, err := semver.NewVersion(policy.Version())
+		if err != nil {
+			continue
+		}
+		if fmt.Sprintf("%d.%d", version.Major(), version.Minor()) == targetYStream {
+			return policy
+		}
+	}
 	return nil
 }
 
@@ -257,27 +433,29 @@ func (o *forceUpgradeOptions) getClusters(ocmClient *sdk.Connection) ([]*v1.Clus
 	return clusters, nil
 }
 
-func (o *forceUpgradeOptions) processCluster(ocmClient *sdk.Connection, cluster *v1.Cluster) (string, error) {
+// processCluster schedules the force upgrade of the cluster and returns the target version and the ID of the
+// created upgrade policy, which is empty in dry-run mode
+func (o *forceUpgradeOptions) processCluster(ocmClient *sdk.Connection, cluster *v1.Cluster) (string, string, error) {
 	// Some sanity checking - we should only ever be upgrading ROSA HCP clusters.
 	if !cluster.Hypershift().Enabled() {
-		return "", fmt.Errorf("force upgrading is only allowed on ROSA HCP clusters")
+		return "", "", fmt.Errorf("force upgrading is only allowed on ROSA HCP clusters")
 	}
 
 	// Check cluster state
 	if cluster.State() != v1.ClusterStateReady {
-		return "", fmt.Errorf("cluster is not ready (current state: %s)", cluster.State())
+		return "", "", fmt.Errorf("cluster is not ready (current state: %s)", cluster.State())
 	}
 
 	// Check for available upgrades
 	if len(cluster.Version().AvailableUpgrades()) == 0 {
-		return "", fmt.Errorf("no available upgrades path")
+		return "", "", fmt.Errorf("no available upgrades path")
 	}
 
 	// Check for existing upgrade policies
 	policiesResponse, err := ocmClient.ClustersMgmt().V1().Clusters().Cluster(cluster.ID()).
 		ControlPlane().UpgradePolicies().List().Send()
 	if err != nil {
-		return "", fmt.Errorf("failed to list existing upgrade policies: %w", err)
+		return "", "", fmt.Errorf("failed to list existing upgrade policies: %w", err)
 	}
 
 	var automaticPolicyIDs []string
@@ -285,7 +463,7 @@ func (o *forceUpgradeOptions) processCluster(ocmClient *sdk.Connection, cluster
 		if policy.ScheduleType() == v1.ScheduleTypeAutomatic {
 			automaticPolicyIDs = append(automaticPolicyIDs, policy.ID())
 		} else {
-			return "", fmt.Errorf("existing manual upgrade policy found: target version %s scheduled at %s",
+			return "", "", fmt.Errorf("existing manual upgrade policy found: target version %s scheduled at %s",
 				policy.Version(), policy.NextRun().Format(time.RFC3339))
 		}
 	}
@@ -293,11 +471,11 @@ func (o *forceUpgradeOptions) processCluster(ocmClient *sdk.Connection, cluster
 	// Find target version
 	targetVersion, err := o.determineTargetVersion(cluster.Version().AvailableUpgrades())
 	if err != nil {
-		return "", fmt.Errorf("failed to determine target version: %w", err)
+		return "", "", fmt.Errorf("failed to determine target version: %w", err)
 	}
 
 	if targetVersion == "" {
-		return "", fmt.Errorf("no valid upgrade version found for Y-stream '%s'", o.targetYStream)
+		return "", "", fmt.Errorf("no valid upgrade version found for Y-stream '%s'", o.targetYStream)
 	}
 
 	scheduleTime := time.Now().UTC().Add(time.Duration(o.nextRunMinutes) * time.Minute)
@@ -305,7 +483,7 @@ func (o *forceUpgradeOptions) processCluster(ocmClient *sdk.Connection, cluster
 	if o.dryRun {
 		fmt.Printf("  🔍 DRY RUN: Would schedule force upgrade to %s at %s\n",
 			targetVersion, scheduleTime.Format(time.RFC3339))
-		return targetVersion, nil
+		return targetVersion, "", nil
 	}
 
 	// Delete automatic Z-stream upgrade policies
@@ -314,7 +492,7 @@ func (o *forceUpgradeOptions) processCluster(ocmClient *sdk.Connection, cluster
 		_, err := ocmClient.ClustersMgmt().V1().Clusters().Cluster(cluster.ID()).
 			ControlPlane().UpgradePolicies().ControlPlaneUpgradePolicy(id).Delete().Send()
 		if err != nil {
-			return "", fmt.Errorf("failed to delete automatic upgrade policy (ID: %s): %w", id, err)
+			return "", "", fmt.Errorf("failed to delete automatic upgrade policy (ID: %s): %w", id, err)
 		}
 	}
 
@@ -325,19 +503,19 @@ func (o *forceUpgradeOptions) processCluster(ocmClient *sdk.Connection, cluster
 		NextRun(scheduleTime).
 		Build()
 	if err != nil {
-		return "", fmt.Errorf("failed to build upgrade policy: %w", err)
+		return "", "", fmt.Errorf("failed to build upgrade policy: %w", err)
 	}
 
-	_, err = ocmClient.ClustersMgmt().V1().Clusters().Cluster(cluster.ID()).
+	response, err := ocmClient.ClustersMgmt().V1().Clusters().Cluster(cluster.ID()).
 		ControlPlane().UpgradePolicies().Add().Body(policy).Send()
 	if err != nil {
-		return "", fmt.Errorf("failed to create upgrade policy: %w", err)
+		return "", "", fmt.Errorf("failed to create upgrade policy: %w", err)
 	}
 
 	fmt.Printf("  ✅ Scheduled force upgrade to version %s at %s\n",
 		targetVersion, scheduleTime.Format(time.RFC3339))
 
-	return targetVersion, nil
+	return targetVersion, response.Body().ID(), nil
 }
 
 func (o *forceUpgradeOptions) determineTargetVersion(availableUpgrades []string) (string, error) {
@@ -457,7 +635,26 @@ func sendUpgradeServiceLog(ocmClient *sdk.Connection, cluster *v1.Cluster, templ
 	return nil
 }
 
-func (o *forceUpgradeOptions) printSummary(successful, failed, serviceLogSuccessful, serviceLogFailed []string) {
+func (o *forceUpgradeOptions) printSummary(results []clusterState) {
+	var successful, failed []string
+	var serviceLogSuccessful, serviceLogFailed []string
+	for _, c := range results {
+		switch c.Status {
+		case clusterPending:
+			continue
+		case clusterScheduleFailed:
+			failed = append(failed, fmt.Sprintf("%s: %s", c.ExternalID, c.Error))
+		default:
+			successful = append(successful, c.ExternalID)
+		}
+
+		if c.ServiceLogSent {
+			serviceLogSuccessful = append(serviceLogSuccessful, c.ExternalID)
+		} else if c.ServiceLogError != "" {
+			serviceLogFailed = append(serviceLogFailed, fmt.Sprintf("%s: %s", c.ExternalID, c.ServiceLogError))
+		}
+	}
+
 	fmt.Print("\n" + strings.Repeat("=", 60) + "\n")
 	fmt.Print("FORCE UPGRADE SUMMARY\n")
 	fmt.Print(strings.Repeat("=", 60) + "\n")
@@ -5,6 +5,8 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+
+	v1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
 )
 
 func TestForceUpgradeOptionsValidation(t *testing.T) {
@@ -290,3 +292,25 @@ func TestDetermineTargetVersion(t *testing.T) {
 		})
 	}
 }
+
+func TestRolloutPolicy(t *testing.T) {
+	build := func(id, version string, scheduleType v1.ScheduleType) *v1.ControlPlaneUpgradePolicy {
+		policy, err := v1.NewControlPlaneUpgradePolicy().ID(id).Version(version).ScheduleType(scheduleType).Build()
+		if err != nil {
+			t.Fatalf("failed to build policy: %v", err)
+		}
+		return policy
+	}
+
+	policies := []*v1.ControlPlaneUpgradePolicy{
+		build("automatic", "4.16.3", v1.ScheduleTypeAutomatic),
+		build("other-y", "4.15.9", v1.ScheduleTypeManual),
+		build("rollout", "4.16.5", v1.ScheduleTypeManual),
+	}
+	if policy := rolloutPolicy(policies, "4.16"); policy == nil || policy.ID() != "rollout" {
+		t.Errorf("expected the manual policy to 4.16, got %v", policy)
+	}
+	if policy := rolloutPolicy(policies, "4.17"); policy != nil {
+		t.Errorf("expected no policy to 4.17, got %s", policy.ID())
+	}
+}
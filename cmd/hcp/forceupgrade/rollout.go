@@ -0,0 +1,487 @@
+package forceupgrade
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	sdk "github.com/openshift-online/ocm-sdk-go"
+	v1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+	"github.com/openshift/osdctl/cmd/hcp/status"
+)
+
+// restWave is the wave size standing for all the remaining clusters
+const restWave = -1
+
+// Status of a cluster in a staged rollout
+const (
+	clusterPending        = "pending"
+	clusterScheduleFailed = "schedule_failed"
+	clusterScheduled      = "scheduled"
+	clusterUpgraded       = "upgraded"
+	clusterUpgradeFailed  = "upgrade_failed"
+)
+
+// rolloutState is the progress of a staged rollout, saved in the state file after every step
+type rolloutState struct {
+	TargetYStream  string         `json:"target_y_stream"`
+	Waves          []int          `json:"waves"`
+	CompletedWaves int            `json:"completed_waves"`
+	Stopped        string         `json:"stopped,omitempty"`
+	UpdatedAt      time.Time      `json:"updated_at"`
+	Clusters       []clusterState `json:"clusters"`
+}
+
+// clusterState is the progress of the force upgrade of a cluster
+type clusterState struct {
+	ID              string `json:"id"`
+	ExternalID      string `json:"external_id"`
+	Name            string `json:"name"`
+	Wave            int    `json:"wave"`
+	Status          string `json:"status"`
+	TargetVersion   string `json:"target_version,omitempty"`
+	PolicyID        string `json:"policy_id,omitempty"`
+	Error           string `json:"error,omitempty"`
+	ServiceLogSent  bool   `json:"service_log_sent,omitempty"`
+	ServiceLogError string `json:"service_log_error,omitempty"`
+}
+
+func newClusterState(cluster *v1.Cluster, wave int) clusterState {
+	return clusterState{
+		ID:         cluster.ID(),
+		ExternalID: cluster.ExternalID(),
+		Name:       cluster.Name(),
+		Wave:       wave,
+		Status:     clusterPending,
+	}
+}
+
+// parseWaves parses wave sizes such as "1,10,50,rest", where only the last wave may be "rest"
+func parseWaves(spec string) ([]int, error) {
+	parts := strings.Split(spec, ",")
+	sizes := make([]int, 0, len(parts))
+	for i, part := range parts {
+		part = strings.TrimSpace(part)
+		if part == "rest" {
+			if i != len(parts)-1 {
+				return nil, fmt.Errorf("invalid waves '%s': 'rest' can only be the last wave", spec)
+			}
+			sizes = append(sizes, restWave)
+			continue
+		}
+
+		size, err := strconv.Atoi(part)
+		if err != nil || size < 1 {
+			return nil, fmt.Errorf("invalid waves '%s': '%s' is neither a positive number nor 'rest'", spec, part)
+		}
+		sizes = append(sizes, size)
+	}
+	return sizes, nil
+}
+
+// resolveWaves returns the size of each wave for the given number of clusters. Waves beyond the last cluster are
+// dropped and the last wave is shrunk to the remaining clusters.
+func resolveWaves(sizes []int, total int) ([]int, error) {
+	var waves []int
+	remaining := total
+	for _, size := range sizes {
+		if remaining == 0 {
+			break
+		}
+		if size == restWave || size > remaining {
+			size = remaining
+		}
+		waves = append(waves, size)
+		remaining -= size
+	}
+
+	if remaining > 0 {
+		return nil, fmt.Errorf("the waves only cover %d of the %d clusters, end them with 'rest' to upgrade the remaining clusters", total-remaining, total)
+	}
+	return waves, nil
+}
+
+// orderClusters returns the clusters in the order of the cluster identifiers they were requested with, so that
+// the waves follow the clusters file. Clusters not matching any identifier come last.
+func orderClusters(clusters []*v1.Cluster, clusterIDs []string) []*v1.Cluster {
+	ordered := make([]*v1.Cluster, 0, len(clusters))
+	added := map[string]bool{}
+	for _, id := range clusterIDs {
+		for _, cluster := range clusters {
+			if added[cluster.ID()] {
+				continue
+			}
+			if cluster.ID() == id || cluster.ExternalID() == id || cluster.Name() == id {
+				ordered = append(ordered, cluster)
+				added[cluster.ID()] = true
+			}
+		}
+	}
+	for _, cluster := range clusters {
+		if !added[cluster.ID()] {
+			ordered = append(ordered, cluster)
+		}
+	}
+	return ordered
+}
+
+func newRolloutState(targetYStream string, waveSizes []int, clusters []*v1.Cluster) (*rolloutState, error) {
+	waves, err := resolveWaves(waveSizes, len(clusters))
+	if err != nil {
+		return nil, err
+	}
+
+	state := &rolloutState{TargetYStream: targetYStream, Waves: waves}
+	i := 0
+	for w, size := range waves {
+		for ; size > 0; size-- {
+			state.Clusters = append(state.Clusters, newClusterState(clusters[i], w+1))
+			i++
+		}
+	}
+	return state, nil
+}
+
+func loadRolloutState(path string) (*rolloutState, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read state file: %w", err)
+	}
+
+	state := &rolloutState{}
+	if err := json.Unmarshal(data, state); err != nil {
+		return nil, fmt.Errorf("failed to parse state file %s: %w", path, err)
+	}
+
+	if len(state.Clusters) == 0 || len(state.Waves) == 0 {
+		return nil, fmt.Errorf("state file %s contains no clusters or waves", path)
+	}
+	return state, nil
+}
+
+func (s *rolloutState) save(path string) error {
+	s.UpdatedAt = time.Now().UTC()
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal rollout state: %w", err)
+	}
+
+	// Write to a temporary file first so that an interruption never leaves a truncated state file
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("failed to write state file: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("failed to write state file: %w", err)
+	}
+	return nil
+}
+
+func (s *rolloutState) finished() bool {
+	return s.CompletedWaves >= len(s.Waves)
+}
+
+// clusterIDs returns the internal IDs of the clusters of the rollout
+func (s *rolloutState) clusterIDs() []string {
+	ids := make([]string, 0, len(s.Clusters))
+	for _, c := range s.Clusters {
+		ids = append(ids, c.ID)
+	}
+	return ids
+}
+
+// waveFailures returns the number of clusters of the wave which failed to be scheduled or upgraded, and its size
+func (s *rolloutState) waveFailures(wave int) (int, int) {
+	failed, total := 0, 0
+	for _, c := range s.Clusters {
+		if c.Wave != wave {
+			continue
+		}
+		total++
+		if c.Status == clusterScheduleFailed || c.Status == clusterUpgradeFailed {
+			failed++
+		}
+	}
+	return failed, total
+}
+
+// exceedsFailureThreshold returns true if more than threshold percent of the clusters failed
+func exceedsFailureThreshold(failed, total, threshold int) bool {
+	return total > 0 && failed*100 > threshold*total
+}
+
+// saveState saves the rollout state, except in dry-run mode where nothing is changed
+func (o *forceUpgradeOptions) saveState() error {
+	if o.dryRun {
+		return nil
+	}
+	return o.state.save(o.stateFile)
+}
+
+// runWaves upgrades the pending clusters of the rollout wave by wave. Between waves, it waits for the upgrades
+// to complete and stops when the failures of the wave exceed the failure threshold.
+func (o *forceUpgradeOptions) runWaves(ocmClient *sdk.Connection, clusters []*v1.Cluster) error {
+	state := o.state
+	state.Stopped = ""
+	if err := o.saveState(); err != nil {
+		return err
+	}
+
+	clustersByID := make(map[string]*v1.Cluster, len(clusters))
+	for _, cluster := range clusters {
+		clustersByID[cluster.ID()] = cluster
+	}
+
+	for wave := state.CompletedWaves + 1; wave <= len(state.Waves); wave++ {
+		fmt.Printf("\n🌊 Wave %d/%d (%d clusters)\n", wave, len(state.Waves), state.Waves[wave-1])
+
+		for i := range state.Clusters {
+			c := &state.Clusters[i]
+			if c.Wave != wave || c.Status != clusterPending {
+				continue
+			}
+
+			fmt.Printf("\n[wave %d] Processing cluster: %s (%s)\n", wave, c.ID, c.Name)
+			cluster, found := clustersByID[c.ID]
+			if !found {
+				c.Status = clusterScheduleFailed
+				c.Error = "cluster not found in OCM"
+				fmt.Printf("  ⚠️  Cluster not found in OCM\n")
+			} else {
+				o.upgradeCluster(ocmClient, cluster, c)
+			}
+
+			if err := o.saveState(); err != nil {
+				return err
+			}
+		}
+
+		// The last wave is only scheduled, there is no further wave to hold back
+		if wave < len(state.Waves) {
+			if err := o.waitForWave(ocmClient, wave); err != nil {
+				return err
+			}
+		}
+
+		state.CompletedWaves = wave
+		failed, total := state.waveFailures(wave)
+		fmt.Printf("\n🌊 Wave %d/%d done: %d/%d clusters failed\n", wave, len(state.Waves), failed, total)
+
+		if wave < len(state.Waves) && exceedsFailureThreshold(failed, total, o.failureThreshold) {
+			state.Stopped = fmt.Sprintf("%d/%d clusters of wave %d failed, above the %d%% failure threshold", failed, total, wave, o.failureThreshold)
+		}
+		if err := o.saveState(); err != nil {
+			return err
+		}
+
+		if state.Stopped != "" {
+			o.printSummary(state.Clusters)
+			o.printRolloutSummary()
+			return fmt.Errorf("rollout stopped: %s. Once investigated, continue it with --resume --state-file %s", state.Stopped, o.stateFile)
+		}
+	}
+
+	o.printSummary(state.Clusters)
+	o.printRolloutSummary()
+	return nil
+}
+
+// waitForWave waits until the upgrades scheduled in the wave completed or failed and the upgraded clusters are
+// healthy. Conditions may take a while to settle after an upgrade, so an unhealthy cluster is checked again at
+// every poll and is only a failure if it is still unhealthy when the wave timeout expires, like an upgrade which
+// has not completed by then.
+func (o *forceUpgradeOptions) waitForWave(ocmClient *sdk.Connection, wave int) error {
+	if o.dryRun {
+		fmt.Printf("\n  🔍 DRY RUN: Would wait up to %s for the upgrades of wave %d to complete\n", o.waveTimeout, wave)
+		return nil
+	}
+
+	// Last health problem of the clusters whose upgrade completed, by cluster ID
+	unhealthy := map[string]string{}
+
+	deadline := time.Now().Add(o.waveTimeout)
+	fmt.Printf("\n⏳ Waiting up to %s for the upgrades of wave %d to complete\n", o.waveTimeout, wave)
+	for {
+		pending := 0
+		for i := range o.state.Clusters {
+			c := &o.state.Clusters[i]
+			if c.Wave != wave || c.Status != clusterScheduled {
+				continue
+			}
+
+			if _, completed := unhealthy[c.ID]; !completed {
+				state, description, err := upgradePolicyState(ocmClient, c)
+				if err != nil {
+					fmt.Printf("  ⚠️  %s: failed to get upgrade state: %v\n", c.Name, err)
+					pending++
+					continue
+				}
+
+				done, failure := evaluateUpgradeState(state, description)
+				if !done {
+					pending++
+					continue
+				}
+				if failure != "" {
+					c.Status = clusterUpgradeFailed
+					c.Error = failure
+					fmt.Printf("  ❌ %s: %s\n", c.Name, failure)
+					continue
+				}
+			}
+
+			if problem := checkUpgradedCluster(ocmClient, c); problem != "" {
+				unhealthy[c.ID] = problem
+				pending++
+				fmt.Printf("  ⏳ %s: upgraded to %s, waiting for it to become healthy: %s\n", c.Name, c.TargetVersion, problem)
+				continue
+			}
+
+			c.Status = clusterUpgraded
+			fmt.Printf("  ✅ %s: upgraded to %s and healthy\n", c.Name, c.TargetVersion)
+		}
+
+		if err := o.saveState(); err != nil {
+			return err
+		}
+
+		if pending == 0 {
+			return nil
+		}
+
+		if time.Now().After(deadline) {
+			for i := range o.state.Clusters {
+				c := &o.state.Clusters[i]
+				if c.Wave == wave && c.Status == clusterScheduled {
+					c.Status = clusterUpgradeFailed
+					c.Error = waveTimeoutFailure(unhealthy[c.ID], o.waveTimeout)
+					fmt.Printf("  ❌ %s: %s\n", c.Name, c.Error)
+				}
+			}
+			return o.saveState()
+		}
+
+		fmt.Printf("  %d cluster(s) of wave %d still upgrading or unhealthy, checking again in %s\n", pending, wave, o.pollInterval)
+		time.Sleep(o.pollInterval)
+	}
+}
+
+// waveTimeoutFailure returns why a cluster failed when the wave timeout expired: its last health problem
+// if its upgrade completed, or else that the upgrade did not complete.
+func waveTimeoutFailure(problem string, timeout time.Duration) string {
+	if problem != "" {
+		return fmt.Sprintf("%s, still after %s", problem, timeout)
+	}
+	return fmt.Sprintf("upgrade did not complete within %s", timeout)
+}
+
+// upgradePolicyState returns the state of the upgrade policy of the cluster. OCM removes the policy once the
+// upgrade is done, so a missing policy is completed if the cluster runs the target version.
+func upgradePolicyState(ocmClient *sdk.Connection, c *clusterState) (v1.UpgradePolicyStateValue, string, error) {
+	clusterClient := ocmClient.ClustersMgmt().V1().Clusters().Cluster(c.ID)
+	response, err := clusterClient.ControlPlane().UpgradePolicies().ControlPlaneUpgradePolicy(c.PolicyID).Get().Send()
+	if err == nil {
+		return response.Body().State().Value(), response.Body().State().Description(), nil
+	}
+	if response == nil || response.Status() != http.StatusNotFound {
+		return "", "", err
+	}
+
+	clusterResponse, err := clusterClient.Get().Send()
+	if err != nil {
+		return "", "", fmt.Errorf("failed to get cluster: %w", err)
+	}
+	version := clusterResponse.Body().Version().RawID()
+	if version == c.TargetVersion {
+		return v1.UpgradePolicyStateValueCompleted, "", nil
+	}
+	return v1.UpgradePolicyStateValueFailed, fmt.Sprintf("upgrade policy no longer exists and the cluster runs %s", version), nil
+}
+
+// evaluateUpgradeState returns whether the upgrade is done and, if it did not complete, why
+func evaluateUpgradeState(state v1.UpgradePolicyStateValue, description string) (bool, string) {
+	switch state {
+	case v1.UpgradePolicyStateValueCompleted:
+		return true, ""
+	case v1.UpgradePolicyStateValueFailed, v1.UpgradePolicyStateValueCancelled:
+		failure := fmt.Sprintf("upgrade policy is %s", state)
+		if description != "" {
+			failure += ": " + description
+		}
+		return true, failure
+	default:
+		return false, ""
+	}
+}
+
+// checkUpgradedCluster returns the first problem of the HCP cluster after its upgrade, if any
+func checkUpgradedCluster(ocmClient *sdk.Connection, c *clusterState) string {
+	s, err := status.FetchStatus(ocmClient, c.ID)
+	if err != nil {
+		return fmt.Sprintf("failed to check health after upgrade: %v", err)
+	}
+
+	problems := status.CheckStatus(s, 0, time.Now())
+	if len(problems) == 0 {
+		return ""
+	}
+	if len(problems) == 1 {
+		return fmt.Sprintf("unhealthy after upgrade: %s", problems[0])
+	}
+	return fmt.Sprintf("unhealthy after upgrade: %s (+%d more)", problems[0], len(problems)-1)
+}
+
+// printRolloutPlan displays the waves of the rollout and their progress before processing
+func (o *forceUpgradeOptions) printRolloutPlan() {
+	fmt.Printf("\nStaged rollout (state file: %s, failure threshold: %d%%):\n", o.stateFile, o.failureThreshold)
+	for wave, size := range o.state.Waves {
+		progress := "pending"
+		if wave < o.state.CompletedWaves {
+			failed, _ := o.state.waveFailures(wave + 1)
+			progress = fmt.Sprintf("done, %d failed", failed)
+		}
+		fmt.Printf("  Wave %d: %d clusters (%s)\n", wave+1, size, progress)
+	}
+	if o.state.Stopped != "" {
+		fmt.Printf("\nPreviously stopped: %s\n", o.state.Stopped)
+	}
+	fmt.Print(strings.Repeat("=", 60) + "\n")
+}
+
+func (o *forceUpgradeOptions) printRolloutSummary() {
+	fmt.Printf("\n🌊 ROLLOUT SUMMARY:\n")
+	fmt.Printf("Completed waves: %d/%d\n", o.state.CompletedWaves, len(o.state.Waves))
+
+	var upgradeFailed []string
+	counts := map[string]int{}
+	for _, c := range o.state.Clusters {
+		counts[c.Status]++
+		if c.Status == clusterUpgradeFailed {
+			upgradeFailed = append(upgradeFailed, fmt.Sprintf("%s: %s", c.ExternalID, c.Error))
+		}
+	}
+	fmt.Printf("Upgraded and healthy: %d\n", counts[clusterUpgraded])
+	fmt.Printf("Scheduled, not checked: %d\n", counts[clusterScheduled])
+	fmt.Printf("Failed upgrade checks: %d\n", counts[clusterUpgradeFailed])
+	fmt.Printf("Not processed yet: %d\n", counts[clusterPending])
+
+	if len(upgradeFailed) > 0 {
+		fmt.Printf("\n⚠️  The following clusters failed their upgrade checks (please follow-up manually):\n")
+		for _, entry := range upgradeFailed {
+			fmt.Printf("  - %s\n", entry)
+		}
+	}
+
+	if o.state.Stopped != "" {
+		fmt.Printf("\n🛑 Rollout stopped: %s\n", o.state.Stopped)
+	}
+
+	if !o.dryRun {
+		fmt.Printf("State saved to %s\n", o.stateFile)
+	}
+	fmt.Print(strings.Repeat("=", 60) + "\n")
+}
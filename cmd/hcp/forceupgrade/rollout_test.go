@@ -0,0 +1,310 @@
+package forceupgrade
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	v1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+)
+
+func TestParseWaves(t *testing.T) {
+	tests := []struct {
+		spec     string
+		expected []int
+		errMsg   string
+	}{
+		{spec: "1,10,50,rest", expected: []int{1, 10, 50, restWave}},
+		{spec: " 5 , 5 ", expected: []int{5, 5}},
+		{spec: "rest", expected: []int{restWave}},
+		{spec: "1,rest,10", errMsg: "'rest' can only be the last wave"},
+		{spec: "1,0,rest", errMsg: "'0' is neither a positive number nor 'rest'"},
+		{spec: "1,ten", errMsg: "'ten' is neither a positive number nor 'rest'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			sizes, err := parseWaves(tt.spec)
+			if tt.errMsg != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.errMsg) {
+					t.Fatalf("expected error containing %q, got %v", tt.errMsg, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(sizes, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, sizes)
+			}
+		})
+	}
+}
+
+func TestResolveWaves(t *testing.T) {
+	tests := []struct {
+		name     string
+		sizes    []int
+		total    int
+		expected []int
+		wantErr  bool
+	}{
+		{name: "rest takes the remaining clusters", sizes: []int{1, 10, restWave}, total: 30, expected: []int{1, 10, 19}},
+		{name: "waves beyond the clusters are dropped", sizes: []int{1, 10, 50, restWave}, total: 5, expected: []int{1, 4}},
+		{name: "exact cover without rest", sizes: []int{2, 3}, total: 5, expected: []int{2, 3}},
+		{name: "nothing left for rest", sizes: []int{2, 3, restWave}, total: 5, expected: []int{2, 3}},
+		{name: "waves not covering all clusters", sizes: []int{1, 2}, total: 5, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			waves, err := resolveWaves(tt.sizes, tt.total)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error, got waves %v", waves)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(waves, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, waves)
+			}
+		})
+	}
+}
+
+func buildCluster(t *testing.T, id, externalID, name string) *v1.Cluster {
+	t.Helper()
+	cluster, err := v1.NewCluster().ID(id).ExternalID(externalID).Name(name).Build()
+	if err != nil {
+		t.Fatalf("failed to build cluster: %v", err)
+	}
+	return cluster
+}
+
+func TestNewRolloutStateFollowsClustersFile(t *testing.T) {
+	clusters := []*v1.Cluster{
+		buildCluster(t, "id-c", "ext-c", "charlie"),
+		buildCluster(t, "id-a", "ext-a", "alpha"),
+		buildCluster(t, "id-b", "ext-b", "bravo"),
+		buildCluster(t, "id-d", "ext-d", "delta"),
+	}
+
+	// Clusters can be requested by ID, external ID or name, and unmatched clusters come last
+	ordered := orderClusters(clusters, []string{"ext-a", "bravo", "id-c"})
+	state, err := newRolloutState("4.16", []int{1, restWave}, ordered)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var got []string
+	for _, c := range state.Clusters {
+		got = append(got, fmt.Sprintf("%s:%d:%s", c.Name, c.Wave, c.Status))
+	}
+	expected := []string{"alpha:1:pending", "bravo:2:pending", "charlie:2:pending", "delta:2:pending"}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+	if !reflect.DeepEqual(state.Waves, []int{1, 3}) {
+		t.Errorf("expected waves [1 3], got %v", state.Waves)
+	}
+}
+
+func TestRolloutStateSaveAndLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	state := &rolloutState{
+		TargetYStream:  "4.16",
+		Waves:          []int{1, 2},
+		CompletedWaves: 1,
+		Stopped:        "1/1 clusters of wave 1 failed, above the 10% failure threshold",
+		Clusters: []clusterState{
+			{ID: "id-a", Name: "alpha", Wave: 1, Status: clusterUpgradeFailed, TargetVersion: "4.16.20", PolicyID: "policy-a", Error: "upgrade policy is failed"},
+			{ID: "id-b", Name: "bravo", Wave: 2, Status: clusterPending},
+			{ID: "id-c", Name: "charlie", Wave: 2, Status: clusterPending},
+		},
+	}
+	if err := state.save(path); err != nil {
+		t.Fatalf("unexpected error saving state: %v", err)
+	}
+	if _, err := os.Stat(path + ".tmp"); !os.IsNotExist(err) {
+		t.Errorf("expected the temporary state file to be renamed")
+	}
+
+	loaded, err := loadRolloutState(path)
+	if err != nil {
+		t.Fatalf("unexpected error loading state: %v", err)
+	}
+	if !reflect.DeepEqual(loaded.Clusters, state.Clusters) || loaded.CompletedWaves != 1 || loaded.Stopped != state.Stopped {
+		t.Errorf("loaded state %+v does not match saved state %+v", loaded, state)
+	}
+	if loaded.finished() {
+		t.Errorf("expected the rollout not to be finished")
+	}
+	if !reflect.DeepEqual(loaded.clusterIDs(), []string{"id-a", "id-b", "id-c"}) {
+		t.Errorf("unexpected cluster IDs %v", loaded.clusterIDs())
+	}
+
+	if err := os.WriteFile(path, []byte(`{"clusters":[]}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := loadRolloutState(path); err == nil {
+		t.Errorf("expected an error loading a state file without clusters")
+	}
+}
+
+func TestWaveFailures(t *testing.T) {
+	state := &rolloutState{
+		Clusters: []clusterState{
+			{Wave: 1, Status: clusterUpgraded},
+			{Wave: 2, Status: clusterUpgraded},
+			{Wave: 2, Status: clusterScheduleFailed},
+			{Wave: 2, Status: clusterUpgradeFailed},
+			{Wave: 2, Status: clusterUpgraded},
+			{Wave: 3, Status: clusterPending},
+		},
+	}
+
+	failed, total := state.waveFailures(2)
+	if failed != 2 || total != 4 {
+		t.Errorf("expected 2/4 failures in wave 2, got %d/%d", failed, total)
+	}
+
+	tests := []struct {
+		failed, total, threshold int
+		expected                 bool
+	}{
+		{failed: 2, total: 4, threshold: 10, expected: true},
+		{failed: 2, total: 4, threshold: 50, expected: false},
+		{failed: 1, total: 10, threshold: 10, expected: false},
+		{failed: 1, total: 1, threshold: 0, expected: true},
+		{failed: 0, total: 5, threshold: 0, expected: false},
+		{failed: 0, total: 0, threshold: 0, expected: false},
+	}
+	for _, tt := range tests {
+		if got := exceedsFailureThreshold(tt.failed, tt.total, tt.threshold); got != tt.expected {
+			t.Errorf("%d/%d failed with threshold %d%%: expected %v, got %v", tt.failed, tt.total, tt.threshold, tt.expected, got)
+		}
+	}
+}
+
+func TestEvaluateUpgradeState(t *testing.T) {
+	tests := []struct {
+		state    v1.UpgradePolicyStateValue
+		done     bool
+		failure  string
+		describe string
+	}{
+		{state: v1.UpgradePolicyStateValueScheduled, done: false},
+		{state: v1.UpgradePolicyStateValueStarted, done: false},
+		{state: v1.UpgradePolicyStateValueDelayed, done: false},
+		{state: v1.UpgradePolicyStateValueCompleted, done: true},
+		{state: v1.UpgradePolicyStateValueFailed, describe: "etcd is unavailable", done: true, failure: "upgrade policy is failed: etcd is unavailable"},
+		{state: v1.UpgradePolicyStateValueCancelled, done: true, failure: "upgrade policy is cancelled"},
+	}
+
+	for _, tt := range tests {
+		done, failure := evaluateUpgradeState(tt.state, tt.describe)
+		if done != tt.done || failure != tt.failure {
+			t.Errorf("state %s: expected (%v, %q), got (%v, %q)", tt.state, tt.done, tt.failure, done, failure)
+		}
+	}
+}
+
+func TestForceUpgradeRolloutValidation(t *testing.T) {
+	tmpDir := t.TempDir()
+	stateFile := filepath.Join(tmpDir, "state.json")
+	state := &rolloutState{
+		TargetYStream: "4.16",
+		Waves:         []int{1, 1},
+		Clusters:      []clusterState{{ID: "id-a", Wave: 1, Status: clusterPending}, {ID: "id-b", Wave: 2, Status: clusterPending}},
+	}
+	if err := state.save(stateFile); err != nil {
+		t.Fatal(err)
+	}
+	missingStateFile := filepath.Join(tmpDir, "missing.json")
+
+	tests := []struct {
+		name    string
+		opts    *forceUpgradeOptions
+		wantErr string
+	}{
+		{
+			name: "valid waves",
+			opts: &forceUpgradeOptions{clusterID: "test-cluster", nextRunMinutes: 10, waves: "1,rest", stateFile: missingStateFile, waveTimeout: 1, pollInterval: 1},
+		},
+		{
+			name:    "invalid waves",
+			opts:    &forceUpgradeOptions{clusterID: "test-cluster", nextRunMinutes: 10, waves: "rest,1", stateFile: missingStateFile, waveTimeout: 1, pollInterval: 1},
+			wantErr: "'rest' can only be the last wave",
+		},
+		{
+			name:    "existing state file without resume",
+			opts:    &forceUpgradeOptions{clusterID: "test-cluster", nextRunMinutes: 10, waves: "1,rest", stateFile: stateFile, waveTimeout: 1, pollInterval: 1},
+			wantErr: "already exists, use --resume",
+		},
+		{
+			name:    "failure threshold out of range",
+			opts:    &forceUpgradeOptions{clusterID: "test-cluster", nextRunMinutes: 10, failureThreshold: 101},
+			wantErr: "failure-threshold must be between 0 and 100",
+		},
+		{
+			name: "valid resume",
+			opts: &forceUpgradeOptions{targetYStream: "4.16", nextRunMinutes: 10, resume: true, stateFile: stateFile, waveTimeout: 1, pollInterval: 1},
+		},
+		{
+			name:    "resume with clusters file",
+			opts:    &forceUpgradeOptions{clustersFile: "clusters.json", targetYStream: "4.16", nextRunMinutes: 10, resume: true, stateFile: stateFile, waveTimeout: 1, pollInterval: 1},
+			wantErr: "cannot specify --cluster-id or --clusters-file with --resume",
+		},
+		{
+			name:    "resume with another target",
+			opts:    &forceUpgradeOptions{targetYStream: "4.17", nextRunMinutes: 10, resume: true, stateFile: stateFile, waveTimeout: 1, pollInterval: 1},
+			wantErr: "does not match '4.16'",
+		},
+		{
+			name:    "resume without state file",
+			opts:    &forceUpgradeOptions{targetYStream: "4.16", nextRunMinutes: 10, resume: true, stateFile: missingStateFile, waveTimeout: 1, pollInterval: 1},
+			wantErr: "failed to read state file",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.opts.validate()
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		})
+	}
+
+	resumed := &forceUpgradeOptions{targetYStream: "4.16", nextRunMinutes: 10, resume: true, stateFile: stateFile, waveTimeout: 1, pollInterval: 1}
+	if err := resumed.validate(); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(resumed.clusterIDs, []string{"id-a", "id-b"}) || resumed.state == nil {
+		t.Errorf("expected the clusters of the state file to be resumed, got %v", resumed.clusterIDs)
+	}
+}
+
+func TestWaveTimeoutFailure(t *testing.T) {
+	if got := waveTimeoutFailure("", 2*time.Hour); got != "upgrade did not complete within 2h0m0s" {
+		t.Errorf("unexpected failure for an upgrade not completed: %s", got)
+	}
+
+	problem := "unhealthy after upgrade: NodePool workers condition Ready is False: Provisioning"
+	if got := waveTimeoutFailure(problem, 2*time.Hour); got != problem+", still after 2h0m0s" {
+		t.Errorf("unexpected failure for an upgraded cluster still unhealthy: %s", got)
+	}
+}
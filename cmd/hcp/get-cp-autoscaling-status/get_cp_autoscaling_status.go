@@ -11,6 +11,7 @@ import (
 	"time"
 
 	sdk "github.com/openshift-online/ocm-sdk-go"
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
 	hypershiftv1beta1 "github.com/openshift/hypershift/api/hypershift/v1beta1"
 	"github.com/openshift/osdctl/pkg/k8s"
 	"github.com/openshift/osdctl/pkg/printer"
@@ -126,54 +127,16 @@ func (o *options) run(ctx context.Context) error {
 }
 
 func (o *options) auditManagementCluster(ctx context.Context, conn *sdk.Connection) error {
-	if err := utils.IsValidClusterKey(o.mgmtClusterID); err != nil {
-		return err
-	}
-
-	cluster, err := utils.GetCluster(conn, o.mgmtClusterID)
-	if err != nil {
-		return fmt.Errorf("failed to get cluster: %v", err)
-	}
-
-	isMC, err := utils.IsManagementCluster(cluster.ID())
+	mgmtClient, cluster, err := ManagementClusterClient(conn, o.mgmtClusterID)
 	if err != nil {
-		return fmt.Errorf("failed to verify management cluster: %v", err)
-	}
-	if !isMC {
-		return fmt.Errorf("cluster %s is not a management cluster", cluster.ID())
+		return err
 	}
 
-	resolvedMgmtClusterID := cluster.ID()
 	resolvedMgmtClusterName := cluster.Name()
 
-	scheme := runtime.NewScheme()
-	if err := hypershiftv1beta1.AddToScheme(scheme); err != nil {
-		return fmt.Errorf("failed to add hypershift scheme: %v", err)
-	}
-	if err := corev1.AddToScheme(scheme); err != nil {
-		return fmt.Errorf("failed to add core v1 scheme: %v", err)
-	}
-
-	mgmtClient, err := k8s.NewWithConn(resolvedMgmtClusterID, client.Options{Scheme: scheme}, conn)
+	namespaces, err := ListOcmNamespacesWithRetry(ctx, mgmtClient)
 	if err != nil {
-		return fmt.Errorf("failed to create management cluster client: %v", err)
-	}
-
-	var namespaces []corev1.Namespace
-	maxRetries := 3
-	retryDelay := 2 * time.Second
-
-	for attempt := 1; attempt <= maxRetries; attempt++ {
-		namespaces, err = listOcmNamespaces(ctx, mgmtClient)
-		if err == nil {
-			break
-		}
-
-		if attempt == maxRetries {
-			return fmt.Errorf("failed to list namespaces after %d attempts (cluster may be unreachable): %v", maxRetries, err)
-		}
-
-		time.Sleep(retryDelay)
+		return err
 	}
 
 	results := &auditResults{
@@ -201,7 +164,42 @@ func (o *options) auditManagementCluster(ctx context.Context, conn *sdk.Connecti
 	return o.outputResults(results)
 }
 
-func listOcmNamespaces(ctx context.Context, kubeClient client.Client) ([]corev1.Namespace, error) {
+// ManagementClusterClient returns a client of the HostedClusters and namespaces of the management cluster.
+func ManagementClusterClient(conn *sdk.Connection, mgmtClusterKey string) (client.Client, *cmv1.Cluster, error) {
+	if err := utils.IsValidClusterKey(mgmtClusterKey); err != nil {
+		return nil, nil, err
+	}
+
+	cluster, err := utils.GetCluster(conn, mgmtClusterKey)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get cluster: %v", err)
+	}
+
+	isMC, err := utils.IsManagementCluster(cluster.ID())
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to verify management cluster: %v", err)
+	}
+	if !isMC {
+		return nil, nil, fmt.Errorf("cluster %s is not a management cluster", cluster.ID())
+	}
+
+	scheme := runtime.NewScheme()
+	if err := hypershiftv1beta1.AddToScheme(scheme); err != nil {
+		return nil, nil, fmt.Errorf("failed to add hypershift scheme: %v", err)
+	}
+	if err := corev1.AddToScheme(scheme); err != nil {
+		return nil, nil, fmt.Errorf("failed to add core v1 scheme: %v", err)
+	}
+
+	mgmtClient, err := k8s.NewWithConn(cluster.ID(), client.Options{Scheme: scheme}, conn)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create management cluster client: %v", err)
+	}
+	return mgmtClient, cluster, nil
+}
+
+// ListOcmNamespaces returns the OCM namespaces of the management cluster, one per hosted cluster.
+func ListOcmNamespaces(ctx context.Context, kubeClient client.Client) ([]corev1.Namespace, error) {
 	nsList := &corev1.NamespaceList{}
 	if err := kubeClient.List(ctx, nsList); err != nil {
 		return nil, err
@@ -219,8 +217,28 @@ func listOcmNamespaces(ctx context.Context, kubeClient client.Client) ([]corev1.
 	return filtered, nil
 }
 
+// ListOcmNamespacesWithRetry lists the OCM namespaces of the management cluster, retrying
+// transient failures up to 3 times.
+func ListOcmNamespacesWithRetry(ctx context.Context, kubeClient client.Client) ([]corev1.Namespace, error) {
+	maxRetries := 3
+	retryDelay := 2 * time.Second
+
+	for attempt := 1; ; attempt++ {
+		namespaces, err := ListOcmNamespaces(ctx, kubeClient)
+		if err == nil {
+			return namespaces, nil
+		}
+
+		if attempt == maxRetries {
+			return nil, fmt.Errorf("failed to list namespaces after %d attempts (cluster may be unreachable): %v", maxRetries, err)
+		}
+
+		time.Sleep(retryDelay)
+	}
+}
+
 func auditNamespace(ctx context.Context, kubeClient client.Client, namespace string) (*clusterInfo, error) {
-	hc, err := getHostedClusterInNamespace(ctx, kubeClient, namespace)
+	hc, err := GetHostedClusterInNamespace(ctx, kubeClient, namespace)
 	if err != nil {
 		return nil, err
 	}
@@ -252,7 +270,8 @@ func auditNamespace(ctx context.Context, kubeClient client.Client, namespace str
 	}, nil
 }
 
-func getHostedClusterInNamespace(ctx context.Context, kubeClient client.Client, namespace string) (*hypershiftv1beta1.HostedCluster, error) {
+// GetHostedClusterInNamespace returns the only HostedCluster of the namespace.
+func GetHostedClusterInNamespace(ctx context.Context, kubeClient client.Client, namespace string) (*hypershiftv1beta1.HostedCluster, error) {
 	hcList := &hypershiftv1beta1.HostedClusterList{}
 	listOpts := []client.ListOption{client.InNamespace(namespace)}
 
@@ -0,0 +1,339 @@
+package mustgather
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/openshift/osdctl/cmd/common"
+	"github.com/openshift/osdctl/cmd/dynatrace"
+	"github.com/openshift/osdctl/cmd/hcp/status"
+	"github.com/openshift/osdctl/pkg/backplane"
+	"github.com/openshift/osdctl/pkg/osdctlConfig"
+	"github.com/openshift/osdctl/pkg/utils"
+	"github.com/spf13/cobra"
+)
+
+const (
+	bundleManifestFile = "manifest.json"
+	// maxReportBundleSize is the size of the largest tarball attached to a backplane report
+	maxReportBundleSize = 10 * 1024 * 1024
+
+	componentCollected = "collected"
+	componentFailed    = "failed"
+)
+
+type gatherBundle struct {
+	clusterId          string
+	reason             string
+	gatherTargets      string
+	acmMustGatherImage string
+	since              int
+	destDir            string
+	report             bool
+}
+
+// BundleManifest describes the content of a gather bundle.
+type BundleManifest struct {
+	ClusterID         string            `json:"clusterId"`
+	ExternalID        string            `json:"externalId"`
+	ClusterName       string            `json:"clusterName"`
+	ManagementCluster string            `json:"managementCluster"`
+	ServiceCluster    string            `json:"serviceCluster"`
+	Reason            string            `json:"reason"`
+	CreatedAt         time.Time         `json:"createdAt"`
+	Window            BundleWindow      `json:"window"`
+	Components        []BundleComponent `json:"components"`
+	PartialFailure    bool              `json:"partialFailure"`
+}
+
+// BundleWindow is the time window of the logs and events of the bundle.
+type BundleWindow struct {
+	From time.Time `json:"from"`
+	To   time.Time `json:"to"`
+}
+
+// BundleComponent is a part of the bundle and the result of its collection.
+type BundleComponent struct {
+	Name     string `json:"name"`
+	Path     string `json:"path"`
+	Status   string `json:"status"`
+	Error    string `json:"error,omitempty"`
+	Duration string `json:"duration"`
+}
+
+// bundleTask collects a component of the bundle into its directory.
+type bundleTask struct {
+	name    string
+	path    string
+	collect func(dir string) error
+}
+
+func NewCmdGatherBundle() *cobra.Command {
+	gb := &gatherBundle{}
+
+	gatherBundleCommand := &cobra.Command{
+		Use:   "gather-bundle --cluster-id <cluster-identifier>",
+		Short: "Gather the must-gathers, Dynatrace logs and status of a HCP cluster into one bundle",
+		Long: `Gather the must-gathers, the Dynatrace logs and events and the status of an HCP cluster concurrently
+and write them into a single tarball, along with a manifest.json listing what was collected, from which
+clusters, the time window of the logs and any partial failures.
+
+The tarball can be attached to a backplane report of the cluster with --report, unless it is larger than 10MiB.`,
+		Example: `
+  # Gather a bundle with the hosted cluster dump and the last 24 hours of logs
+  osdctl hcp gather-bundle --cluster-id CLUSTER_ID --reason OHSS-1234 --since 24
+
+  # Gather a bundle including the management cluster must-gather and attach it to a backplane report
+  osdctl hcp gather-bundle --cluster-id CLUSTER_ID --reason OHSS-1234 --gather hcp,mc --report`,
+		Args:              cobra.NoArgs,
+		DisableAutoGenTag: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return gb.Run()
+		},
+	}
+
+	defaultAcmImage := "quay.io/stolostron/must-gather:2.11.4-SNAPSHOT-2024-12-02-15-19-44"
+	gatherBundleCommand.Flags().StringVarP(&gb.clusterId, "cluster-id", "C", "", "Internal ID of the cluster to gather data from")
+	gatherBundleCommand.Flags().StringVar(&gb.reason, "reason", "", "The reason for this command, which requires elevation (e.g., OHSS ticket or PD incident).")
+	gatherBundleCommand.Flags().StringVar(&gb.gatherTargets, "gather", "hcp", "Comma-separated list of must-gather targets (available: sc, sc_acm, mc, hcp).")
+	gatherBundleCommand.Flags().StringVar(&gb.acmMustGatherImage, "acm_image", defaultAcmImage, "Overrides the acm must-gather image being used for acm mc, sc as well as hcp must-gathers.")
+	gatherBundleCommand.Flags().IntVar(&gb.since, "since", 72, "Number of hours (integer) since which to pull the Dynatrace logs and events")
+	gatherBundleCommand.Flags().StringVar(&gb.destDir, "dest-dir", "/tmp", "Directory in which the bundle is written")
+	gatherBundleCommand.Flags().BoolVar(&gb.report, "report", false, "Attach the bundle to a backplane report of the cluster")
+
+	_ = gatherBundleCommand.MarkFlagRequired("cluster-id")
+	_ = gatherBundleCommand.MarkFlagRequired("reason")
+
+	return gatherBundleCommand
+}
+
+func (gb *gatherBundle) Run() error {
+	if gb.since <= 0 {
+		return fmt.Errorf("invalid time duration")
+	}
+	gatherTargets := strings.Split(gb.gatherTargets, ",")
+	for _, gatherTarget := range gatherTargets {
+		switch gatherTarget {
+		case "sc", "sc_acm", "mc", "hcp":
+		default:
+			return fmt.Errorf("unknown gather type: %s", gatherTarget)
+		}
+	}
+
+	ocmClient, err := utils.CreateConnection()
+	if err != nil {
+		return err
+	}
+	defer ocmClient.Close()
+
+	cluster, err := utils.GetClusterAnyStatus(ocmClient, gb.clusterId)
+	if err != nil {
+		return fmt.Errorf("failed to get OCM cluster info for %s: %s", gb.clusterId, err)
+	}
+
+	mc, err := utils.GetManagementCluster(cluster.ID())
+	if err != nil {
+		return err
+	}
+
+	sc, err := utils.GetServiceCluster(cluster.ID())
+	if err != nil {
+		return err
+	}
+
+	_, mcRestCfg, mcK8sCli, err := common.GetKubeConfigAndClient(mc.ID(), gb.reason)
+	if err != nil {
+		return err
+	}
+
+	_, scRestCfg, scK8sCli, err := common.GetKubeConfigAndClient(sc.ID(), gb.reason)
+	if err != nil {
+		return err
+	}
+
+	// hack(typeid): work around backplane overwriting our config
+	err = osdctlConfig.EnsureConfigFile()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	bundleName := fmt.Sprintf("hcp_bundle_%s_%s", cluster.ID(), now.Format("20060102150405"))
+	outputDir := filepath.Join(gb.destDir, bundleName)
+	tarballPath := outputDir + ".tar.gz"
+	err = os.MkdirAll(outputDir, 0750)
+	if err != nil {
+		return err
+	}
+
+	tasks := []bundleTask{
+		{name: "status", path: "status", collect: func(dir string) error {
+			hcpStatus, err := status.FetchStatus(ocmClient, cluster.ID())
+			if err != nil {
+				return err
+			}
+			f, err := os.Create(filepath.Join(dir, "status.txt")) //#nosec G304 -- dir is the bundle directory
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			status.PrintStatus(f, hcpStatus)
+			return nil
+		}},
+		{name: "dynatrace-logs", path: "dynatrace", collect: func(dir string) error {
+			gatherOptions := &dynatrace.GatherLogsOpts{Since: gb.since, SortOrder: "asc", DestDir: dir}
+			return gatherOptions.GatherLogs(cluster.ID(), gb.reason)
+		}},
+	}
+	for _, gatherTarget := range gatherTargets {
+		var collect func(dir string) error
+		switch gatherTarget {
+		case "sc":
+			collect = func(dir string) error {
+				return createMustGather(scRestCfg, scK8sCli, []string{"--dest-dir=" + dir})
+			}
+		case "sc_acm":
+			collect = func(dir string) error {
+				return createMustGather(scRestCfg, scK8sCli, []string{"--dest-dir=" + dir, "--image=" + gb.acmMustGatherImage})
+			}
+		case "mc":
+			collect = func(dir string) error {
+				return createMustGather(mcRestCfg, mcK8sCli, []string{"--dest-dir=" + dir})
+			}
+		case "hcp":
+			collect = func(dir string) error {
+				return gatherHostedClusterDump(ocmClient, cluster, mcRestCfg, mcK8sCli, dir)
+			}
+		}
+		tasks = append(tasks, bundleTask{name: "must-gather-" + gatherTarget, path: filepath.Join("must-gather", gatherTarget), collect: collect})
+	}
+
+	// Prints with color :)
+	fmt.Printf("\033[1;34mCreating gather bundle with must-gather targets '%s'. Output directory: '%s'\033[0m\n", gb.gatherTargets, outputDir)
+	manifest := BundleManifest{
+		ClusterID:         cluster.ID(),
+		ExternalID:        cluster.ExternalID(),
+		ClusterName:       cluster.Name(),
+		ManagementCluster: mc.Name(),
+		ServiceCluster:    sc.Name(),
+		Reason:            gb.reason,
+		Window:            BundleWindow{From: now.Add(-time.Duration(gb.since) * time.Hour), To: now},
+	}
+	manifest.Components = collectBundle(outputDir, tasks)
+	manifest.CreatedAt = time.Now().UTC()
+	if err := writeBundleManifest(outputDir, &manifest); err != nil {
+		return err
+	}
+
+	fmt.Println()
+	fmt.Println("All gather tasks completed. Creating tarball.")
+	if err := createTarball(outputDir, tarballPath); err != nil {
+		return fmt.Errorf("failed to create tarball: %w", err)
+	}
+	info, err := os.Stat(tarballPath)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("Data collection completed in:", outputDir)
+	fmt.Printf("Compressed archive has been created at: %s (%s)\n", tarballPath, utils.FormatBytes(info.Size()))
+	if manifest.PartialFailure {
+		fmt.Printf("Some components could not be collected, see %s\n", filepath.Join(outputDir, bundleManifestFile))
+	}
+
+	if gb.report {
+		return attachBundleToReport(cluster.ID(), tarballPath, fmt.Sprintf("HCP gather bundle %s (%s)", filepath.Base(tarballPath), gb.reason))
+	}
+	return nil
+}
+
+// collectBundle runs the tasks concurrently, each in its directory of outputDir, and returns the
+// result of each task in the order of the tasks.
+func collectBundle(outputDir string, tasks []bundleTask) []BundleComponent {
+	components := make([]BundleComponent, len(tasks))
+
+	var wg sync.WaitGroup
+	for i, task := range tasks {
+		wg.Add(1)
+		go func(i int, task bundleTask) {
+			defer wg.Done()
+
+			component := BundleComponent{Name: task.name, Path: filepath.ToSlash(task.path), Status: componentCollected}
+			start := time.Now()
+			dir := filepath.Join(outputDir, task.path)
+			err := os.MkdirAll(dir, 0750)
+			if err == nil {
+				err = task.collect(dir)
+			}
+			if err != nil {
+				component.Status = componentFailed
+				component.Error = err.Error()
+				fmt.Printf("failed to gather %s: %v\n", task.name, err)
+			} else {
+				fmt.Printf("gathered %s\n", task.name)
+			}
+			component.Duration = time.Since(start).Round(time.Second).String()
+			components[i] = component
+		}(i, task)
+	}
+	wg.Wait()
+
+	return components
+}
+
+// writeBundleManifest writes the manifest into the bundle directory, flagging any failed component.
+func writeBundleManifest(outputDir string, manifest *BundleManifest) error {
+	manifest.PartialFailure = false
+	for _, component := range manifest.Components {
+		if component.Status == componentFailed {
+			manifest.PartialFailure = true
+		}
+	}
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(outputDir, bundleManifestFile), data, 0600); err != nil {
+		return fmt.Errorf("failed to write bundle manifest: %w", err)
+	}
+	return nil
+}
+
+// attachBundleToReport creates a backplane report of the cluster with the tarball as data. Tarballs
+// above maxReportBundleSize are not attached, they have to be shared from their local path.
+func attachBundleToReport(clusterID, tarballPath, summary string) error {
+	info, err := os.Stat(tarballPath)
+	if err != nil {
+		return fmt.Errorf("failed to read bundle: %w", err)
+	}
+	if info.Size() > maxReportBundleSize {
+		fmt.Printf("The bundle is %s, above the %s limit of backplane reports, it was not attached. Share %s instead\n",
+			utils.FormatBytes(info.Size()), utils.FormatBytes(maxReportBundleSize), tarballPath)
+		return nil
+	}
+
+	data, err := os.ReadFile(tarballPath) //#nosec G304 -- tarballPath is the bundle written by this command
+	if err != nil {
+		return fmt.Errorf("failed to read bundle: %w", err)
+	}
+
+	backplaneClient, err := backplane.NewClient(clusterID)
+	if err != nil {
+		return fmt.Errorf("failed to create backplane client: %w", err)
+	}
+	report, err := backplaneClient.CreateReport(context.Background(), summary, base64.StdEncoding.EncodeToString(data))
+	if err != nil {
+		return fmt.Errorf("failed to attach bundle to a report: %w", err)
+	}
+
+	fmt.Printf("Bundle attached to report %s of cluster %s\n", report.ReportId, clusterID)
+	return nil
+}
@@ -0,0 +1,76 @@
+package mustgather
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCollectBundle(t *testing.T) {
+	outputDir := t.TempDir()
+	tasks := []bundleTask{
+		{name: "status", path: "status", collect: func(dir string) error {
+			return os.WriteFile(filepath.Join(dir, "status.txt"), []byte("ok"), 0600)
+		}},
+		{name: "must-gather-mc", path: filepath.Join("must-gather", "mc"), collect: func(dir string) error {
+			return errors.New("oc adm must-gather failed")
+		}},
+	}
+
+	components := collectBundle(outputDir, tasks)
+
+	require.Len(t, components, 2)
+	assert.Equal(t, "status", components[0].Name)
+	assert.Equal(t, componentCollected, components[0].Status)
+	assert.Empty(t, components[0].Error)
+	assert.FileExists(t, filepath.Join(outputDir, "status", "status.txt"))
+
+	assert.Equal(t, "must-gather-mc", components[1].Name)
+	assert.Equal(t, "must-gather/mc", components[1].Path)
+	assert.Equal(t, componentFailed, components[1].Status)
+	assert.Equal(t, "oc adm must-gather failed", components[1].Error)
+	assert.DirExists(t, filepath.Join(outputDir, "must-gather", "mc"))
+}
+
+func TestWriteBundleManifest(t *testing.T) {
+	outputDir := t.TempDir()
+	manifest := &BundleManifest{
+		ClusterID: "abc123",
+		Components: []BundleComponent{
+			{Name: "status", Status: componentCollected},
+			{Name: "dynatrace-logs", Status: componentFailed, Error: "no access token"},
+		},
+	}
+
+	require.NoError(t, writeBundleManifest(outputDir, manifest))
+	assert.True(t, manifest.PartialFailure)
+
+	data, err := os.ReadFile(filepath.Join(outputDir, bundleManifestFile))
+	require.NoError(t, err)
+	var written BundleManifest
+	require.NoError(t, json.Unmarshal(data, &written))
+	assert.Equal(t, "abc123", written.ClusterID)
+	assert.True(t, written.PartialFailure)
+	assert.Len(t, written.Components, 2)
+
+	manifest.Components[1].Status = componentCollected
+	require.NoError(t, writeBundleManifest(outputDir, manifest))
+	assert.False(t, manifest.PartialFailure)
+}
+
+func TestAttachBundleToReportTooLarge(t *testing.T) {
+	tarballPath := filepath.Join(t.TempDir(), "bundle.tar.gz")
+	file, err := os.Create(tarballPath)
+	require.NoError(t, err)
+	require.NoError(t, file.Truncate(maxReportBundleSize+1))
+	require.NoError(t, file.Close())
+
+	// The bundle is skipped before contacting backplane
+	assert.NoError(t, attachBundleToReport("cluster-id", tarballPath, "summary"))
+	assert.Error(t, attachBundleToReport("cluster-id", filepath.Join(t.TempDir(), "missing.tar.gz"), "summary"))
+}
@@ -18,6 +18,8 @@ import (
 	"syscall"
 	"time"
 
+	sdk "github.com/openshift-online/ocm-sdk-go"
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
 	"github.com/openshift/osdctl/cmd/common"
 	"github.com/openshift/osdctl/cmd/dynatrace"
 	"github.com/openshift/osdctl/pkg/osdctlConfig"
@@ -179,26 +181,8 @@ func (mg *mustGather) Run() error {
 				}
 
 				// 2. ACM must-gather which includes running the hypershift binary for a dump
-				clusterHyperShift, err := ocmClient.ClustersMgmt().V1().Clusters().Cluster(mg.clusterId).Hypershift().Get().Send()
-				if err != nil {
-					fmt.Printf("collected HCP dynatrace logs but failed to get OCM cluster hypershift info for %s: %v\n", mg.clusterId, err)
-					return
-				}
-
-				hcpNamespace, ok := clusterHyperShift.Body().GetHCPNamespace()
-				if !ok {
-					fmt.Println("collected HCP dynatrace logs but failed to get HCP namespace")
-					return
-				}
-
-				hcName := cluster.DomainPrefix()
-				hcNamespace := strings.TrimSuffix(hcpNamespace, "-"+hcName)
-
-				// TODO(ACM-16170): replace this with an official ACM release image once it's available
-				acmHyperShiftImage := "quay.io/rokejungrh/must-gather:v2.13.0-33-linux"
-				gatherScript := fmt.Sprintf("/usr/bin/gather hosted-cluster-namespace=%s hosted-cluster-name=%s", hcNamespace, hcName)
-				if err := createMustGather(mcRestCfg, mcK8sCli, []string{"--dest-dir=" + destDir, "--image=" + acmHyperShiftImage, gatherScript}); err != nil {
-					fmt.Printf("collected HCP dynatrace logs but failed to gather %s: %v\n", gatherTarget, err.Error())
+				if err := gatherHostedClusterDump(ocmClient, cluster, mcRestCfg, mcK8sCli, destDir); err != nil {
+					fmt.Printf("collected HCP dynatrace logs but %v\n", err)
 				}
 
 			default:
@@ -228,6 +212,31 @@ func (mg *mustGather) Run() error {
 	return nil
 }
 
+// gatherHostedClusterDump runs the ACM must-gather of the hosted cluster on its management cluster,
+// which includes running the hypershift binary for a dump.
+func gatherHostedClusterDump(ocmClient *sdk.Connection, cluster *cmv1.Cluster, mcRestCfg *rest.Config, mcK8sCli *kubernetes.Clientset, destDir string) error {
+	clusterHyperShift, err := ocmClient.ClustersMgmt().V1().Clusters().Cluster(cluster.ID()).Hypershift().Get().Send()
+	if err != nil {
+		return fmt.Errorf("failed to get OCM cluster hypershift info for %s: %v", cluster.ID(), err)
+	}
+
+	hcpNamespace, ok := clusterHyperShift.Body().GetHCPNamespace()
+	if !ok {
+		return fmt.Errorf("failed to get HCP namespace")
+	}
+
+	hcName := cluster.DomainPrefix()
+	hcNamespace := strings.TrimSuffix(hcpNamespace, "-"+hcName)
+
+	// TODO(ACM-16170): replace this with an official ACM release image once it's available
+	acmHyperShiftImage := "quay.io/rokejungrh/must-gather:v2.13.0-33-linux"
+	gatherScript := fmt.Sprintf("/usr/bin/gather hosted-cluster-namespace=%s hosted-cluster-name=%s", hcNamespace, hcName)
+	if err := createMustGather(mcRestCfg, mcK8sCli, []string{"--dest-dir=" + destDir, "--image=" + acmHyperShiftImage, gatherScript}); err != nil {
+		return fmt.Errorf("failed to gather hcp: %v", err)
+	}
+	return nil
+}
+
 func createMustGather(restCfg *rest.Config, k8sCli *kubernetes.Clientset, additionalFlags []string) error {
 	// We used to run this programatically by directly using the must-gather package  (see https://github.com/openshift/osdctl/pull/660)
 	// from the oc cli, but decided to opt for oc.Exec instead.
@@ -0,0 +1,106 @@
+package status
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Suffixes of the condition types which are unhealthy when True, e.g. Degraded or ClusterVersionFailing.
+var negativeConditionSuffixes = []string{"Degraded", "Failing"}
+
+// Suffixes of the condition types which are unhealthy when not True, e.g. Available or AllNodesHealthy.
+// Condition types starting with "Valid" are unhealthy when not True as well.
+var positiveConditionSuffixes = []string{"Available", "Ready", "Healthy", "Succeeding", "Succeeded", "Reachable", "Active", "Found", "Accepted"}
+
+// conditionDegraded returns true if the condition reports a problem. Conditions which do not report
+// the health of the cluster, like Progressing or AutoscalingEnabled, are never degraded.
+func conditionDegraded(c Condition) bool {
+	for _, suffix := range negativeConditionSuffixes {
+		if strings.HasSuffix(c.Type, suffix) {
+			return c.Status == "True"
+		}
+	}
+
+	positive := strings.HasPrefix(c.Type, "Valid")
+	for _, suffix := range positiveConditionSuffixes {
+		if strings.HasSuffix(c.Type, suffix) {
+			positive = true
+		}
+	}
+	return positive && (c.Status == "False" || c.Status == "Unknown")
+}
+
+// CheckStatus returns the problems of the HCP cluster status: degraded conditions, ManifestWorks
+// out of sync and certificates not ready or expiring within certExpiryDays. No problems means healthy.
+func CheckStatus(s *HCPStatus, certExpiryDays int, now time.Time) []string {
+	var problems []string
+	problems = append(problems, outOfSyncManifestWorks(s)...)
+	problems = append(problems, degradedHostedClusterConditions(s)...)
+	for _, np := range s.NodePools {
+		problems = append(problems, degradedNodePoolConditions(np)...)
+	}
+	problems = append(problems, certificateProblems(s, certExpiryDays, now)...)
+	return problems
+}
+
+func outOfSyncManifestWorks(s *HCPStatus) []string {
+	var problems []string
+	for _, mw := range s.ManifestWorks {
+		if !mw.Applied || !mw.Available {
+			problems = append(problems, fmt.Sprintf("ManifestWork %s is out of sync (applied: %s, available: %s)", mw.Name, boolStatus(mw.Applied), boolStatus(mw.Available)))
+		}
+	}
+	return problems
+}
+
+func degradedHostedClusterConditions(s *HCPStatus) []string {
+	var problems []string
+	for _, c := range s.HostedClusterConditions {
+		if conditionDegraded(c) {
+			problems = append(problems, fmt.Sprintf("HostedCluster condition %s is %s: %s", c.Type, c.Status, conditionMessage(c)))
+		}
+	}
+	return problems
+}
+
+func degradedNodePoolConditions(np NodePoolStatus) []string {
+	var problems []string
+	for _, c := range np.Conditions {
+		if conditionDegraded(c) {
+			problems = append(problems, fmt.Sprintf("NodePool %s condition %s is %s: %s", np.Name, c.Type, c.Status, conditionMessage(c)))
+		}
+	}
+	return problems
+}
+
+func certificateProblems(s *HCPStatus, certExpiryDays int, now time.Time) []string {
+	var problems []string
+	problems = append(problems, checkCertificate("API server certificate", s.APIServerCertificate, certExpiryDays, now)...)
+	problems = append(problems, checkCertificate("Ingress certificate", s.IngressCertificate, certExpiryDays, now)...)
+	return problems
+}
+
+func checkCertificate(name string, c *CertificateStatus, certExpiryDays int, now time.Time) []string {
+	if c == nil {
+		return nil
+	}
+
+	var problems []string
+	if c.Ready != nil && !*c.Ready {
+		problems = append(problems, fmt.Sprintf("%s is not ready", name))
+	}
+	if !c.NotAfter.IsZero() && c.NotAfter.Before(now.AddDate(0, 0, certExpiryDays)) {
+		problems = append(problems, fmt.Sprintf("%s expires on %s, within %d days", name, c.NotAfter.Format("2006-01-02"), certExpiryDays))
+	}
+	return problems
+}
+
+// conditionMessage returns the first line of the message of the condition, or its reason.
+func conditionMessage(c Condition) string {
+	msg := c.Message
+	if msg == "" {
+		msg = c.Reason
+	}
+	return strings.SplitN(msg, "\n", 2)[0]
+}
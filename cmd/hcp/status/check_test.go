@@ -0,0 +1,115 @@
+package status
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"gopkg.in/yaml.v2"
+)
+
+func TestConditionDegraded(t *testing.T) {
+	tests := []struct {
+		condition Condition
+		expected  bool
+	}{
+		{Condition{Type: "Available", Status: "True"}, false},
+		{Condition{Type: "Available", Status: "False"}, true},
+		{Condition{Type: "EtcdAvailable", Status: "Unknown"}, true},
+		{Condition{Type: "Degraded", Status: "True"}, true},
+		{Condition{Type: "Degraded", Status: "False"}, false},
+		{Condition{Type: "ClusterVersionFailing", Status: "True"}, true},
+		{Condition{Type: "ValidReleaseImage", Status: "False"}, true},
+		{Condition{Type: "AllNodesHealthy", Status: "False"}, true},
+		{Condition{Type: "Progressing", Status: "True"}, false},
+		{Condition{Type: "AutoscalingEnabled", Status: "False"}, false},
+		{Condition{Type: "ClusterVersionUpgradeable", Status: "False"}, false},
+	}
+
+	for _, tt := range tests {
+		if got := conditionDegraded(tt.condition); got != tt.expected {
+			t.Errorf("condition %s=%s: expected degraded=%v, got %v", tt.condition.Type, tt.condition.Status, tt.expected, got)
+		}
+	}
+}
+
+func TestCheckStatus(t *testing.T) {
+	now := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	ready := true
+	notReady := false
+
+	healthy := &HCPStatus{
+		ManifestWorks:           []ManifestWorkSync{{Name: "abc123", Applied: true, Available: true}},
+		HostedClusterConditions: []Condition{{Type: "Available", Status: "True"}, {Type: "Degraded", Status: "False"}},
+		IngressCertificate:      &CertificateStatus{Ready: &ready, NotAfter: now.AddDate(0, 2, 0)},
+		NodePools:               []NodePoolStatus{{Name: "workers", Conditions: []Condition{{Type: "Ready", Status: "True"}}}},
+	}
+	if problems := CheckStatus(healthy, 30, now); len(problems) != 0 {
+		t.Errorf("expected no problems, got %v", problems)
+	}
+
+	unhealthy := &HCPStatus{
+		ManifestWorks:           []ManifestWorkSync{{Name: "abc123-workers", Applied: true, Available: false}},
+		HostedClusterConditions: []Condition{{Type: "Degraded", Status: "True", Message: "etcd is unavailable\nsee the etcd pods"}},
+		APIServerCertificate:    &CertificateStatus{},
+		IngressCertificate:      &CertificateStatus{Ready: &notReady, NotAfter: now.AddDate(0, 0, 10)},
+		NodePools:               []NodePoolStatus{{Name: "workers", Conditions: []Condition{{Type: "AllMachinesReady", Status: "False", Reason: "Provisioning"}}}},
+	}
+	problems := CheckStatus(unhealthy, 30, now)
+	expected := []string{
+		"ManifestWork abc123-workers is out of sync (applied: True, available: False)",
+		"HostedCluster condition Degraded is True: etcd is unavailable",
+		"NodePool workers condition AllMachinesReady is False: Provisioning",
+		"Ingress certificate is not ready",
+		"Ingress certificate expires on 2025-06-11, within 30 days",
+	}
+	if strings.Join(problems, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("expected problems:\n%s\ngot:\n%s", strings.Join(expected, "\n"), strings.Join(problems, "\n"))
+	}
+
+	if problems := CheckStatus(unhealthy, 5, now); len(problems) != 4 {
+		t.Errorf("expected the certificate expiry to be ignored with 5 days, got %v", problems)
+	}
+}
+
+func TestPrintStatusAs(t *testing.T) {
+	s := &HCPStatus{
+		ClusterID:               "2o9r9r1q4tp0bulsfksdc8fesls54sql",
+		ClusterName:             "my-cluster",
+		HostedClusterConditions: []Condition{{Type: "Available", Status: "True"}},
+	}
+
+	var out bytes.Buffer
+	if err := printStatusAs(&out, s, outputJSON); err != nil {
+		t.Fatal(err)
+	}
+	var fromJSON map[string]interface{}
+	if err := json.Unmarshal(out.Bytes(), &fromJSON); err != nil {
+		t.Fatalf("invalid JSON output: %v", err)
+	}
+	if fromJSON["clusterName"] != "my-cluster" {
+		t.Errorf("expected clusterName my-cluster in JSON, got %v", fromJSON["clusterName"])
+	}
+
+	out.Reset()
+	if err := printStatusAs(&out, s, outputYAML); err != nil {
+		t.Fatal(err)
+	}
+	var fromYAML HCPStatus
+	if err := yaml.Unmarshal(out.Bytes(), &fromYAML); err != nil {
+		t.Fatalf("invalid YAML output: %v", err)
+	}
+	if fromYAML.ClusterID != s.ClusterID || len(fromYAML.HostedClusterConditions) != 1 {
+		t.Errorf("unexpected YAML output:\n%s", out.String())
+	}
+
+	out.Reset()
+	if err := printStatusAs(&out, s, outputText); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(out.String(), "HCP Cluster Status: my-cluster") {
+		t.Errorf("unexpected text output:\n%s", out.String())
+	}
+}
@@ -1,79 +1,110 @@
 package status
 
 import (
+	"encoding/json"
 	"fmt"
+	"io"
 	"math"
-	"os"
 	"strings"
 	"text/tabwriter"
 	"time"
+
+	"gopkg.in/yaml.v2"
 )
 
-// printStatus renders the full HCP cluster status to stdout.
-func printStatus(s *HCPStatus) {
-	fmt.Printf("HCP Cluster Status: %s (%s)\n", s.ClusterName, s.ClusterID)
+// printStatusAs renders the HCP cluster status to out in the output format.
+func printStatusAs(out io.Writer, s *HCPStatus, output string) error {
+	switch output {
+	case outputJSON:
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(s)
+	case outputYAML:
+		data, err := yaml.Marshal(s)
+		if err != nil {
+			return fmt.Errorf("failed to marshal status to YAML: %w", err)
+		}
+		_, err = out.Write(data)
+		return err
+	default:
+		PrintStatus(out, s)
+		return nil
+	}
+}
+
+// printProblems renders the summary of the problems found by CheckStatus.
+func printProblems(out io.Writer, s *HCPStatus, problems []string) {
+	fmt.Fprintf(out, "HCP cluster %s (%s) is unhealthy:\n", s.ClusterName, s.ClusterID)
+	for _, problem := range problems {
+		fmt.Fprintf(out, "  - %s\n", problem)
+	}
+}
+
+// PrintStatus renders the full HCP cluster status to out.
+func PrintStatus(out io.Writer, s *HCPStatus) {
+	fmt.Fprintf(out, "HCP Cluster Status: %s (%s)\n", s.ClusterName, s.ClusterID)
 	if s.ClusterState != "" {
-		fmt.Printf("Cluster State: %s\n", s.ClusterState)
+		fmt.Fprintf(out, "Cluster State: %s\n", s.ClusterState)
 	}
 	if s.ManagementCluster != "" {
-		fmt.Printf("Management Cluster: %s\n", s.ManagementCluster)
+		fmt.Fprintf(out, "Management Cluster: %s\n", s.ManagementCluster)
 	}
-	fmt.Println()
+	fmt.Fprintln(out)
 
 	if len(s.ManifestWorks) == 0 {
-		fmt.Println("MANIFEST WORKS (Service Cluster -> Management Cluster)")
-		fmt.Println("  No ManifestWork resources found")
-		fmt.Println("  (Cluster may not be fully installed yet or may be in a transitional state)")
-		fmt.Println()
+		fmt.Fprintln(out, "MANIFEST WORKS (Service Cluster -> Management Cluster)")
+		fmt.Fprintln(out, "  No ManifestWork resources found")
+		fmt.Fprintln(out, "  (Cluster may not be fully installed yet or may be in a transitional state)")
+		fmt.Fprintln(out)
 	} else {
-		printManifestWorkSync(s.ManifestWorks)
+		printManifestWorkSync(out, s.ManifestWorks)
 	}
 
 	if len(s.HostedClusterConditions) == 0 {
-		fmt.Println("HOSTED CLUSTER")
-		fmt.Println("  No HostedCluster conditions available")
-		fmt.Println("  (Cluster may not be fully installed yet or may be in a transitional state)")
-		fmt.Println()
+		fmt.Fprintln(out, "HOSTED CLUSTER")
+		fmt.Fprintln(out, "  No HostedCluster conditions available")
+		fmt.Fprintln(out, "  (Cluster may not be fully installed yet or may be in a transitional state)")
+		fmt.Fprintln(out)
 	} else {
-		printHostedClusterStatus("HOSTED CLUSTER", s.HostedClusterConditions, s.Version)
+		printHostedClusterStatus(out, "HOSTED CLUSTER", s.HostedClusterConditions, s.Version)
 	}
 
 	// Show cluster API certificate status
 	if s.APIServerCertificate != nil {
-		fmt.Println("CLUSTER KUBE API CERTIFICATE")
-		fmt.Println("  Certificate resource found in ManifestWork")
-		fmt.Println("  (Detailed status not available - ACM feedback rules not yet implemented)")
-		fmt.Println()
+		fmt.Fprintln(out, "CLUSTER KUBE API CERTIFICATE")
+		fmt.Fprintln(out, "  Certificate resource found in ManifestWork")
+		fmt.Fprintln(out, "  (Detailed status not available - ACM feedback rules not yet implemented)")
+		fmt.Fprintln(out)
 	}
 
 	if s.IngressCertificate != nil {
-		printCertificateStatus("DEFAULT INGRESS CERTIFICATE", s.IngressCertificate)
+		printCertificateStatus(out, "DEFAULT INGRESS CERTIFICATE", s.IngressCertificate)
 	} else {
-		fmt.Println("DEFAULT INGRESS CERTIFICATE")
-		fmt.Println("  No certificate information available")
-		fmt.Println("  (Cluster may not be fully installed yet or may be in a transitional state)")
-		fmt.Println()
+		fmt.Fprintln(out, "DEFAULT INGRESS CERTIFICATE")
+		fmt.Fprintln(out, "  No certificate information available")
+		fmt.Fprintln(out, "  (Cluster may not be fully installed yet or may be in a transitional state)")
+		fmt.Fprintln(out)
 	}
 
 	if len(s.NodePools) == 0 {
-		fmt.Println("NODEPOOLS")
-		fmt.Println("  No NodePool resources found")
-		fmt.Println("  (Cluster may not be fully installed yet or may be in a transitional state)")
-		fmt.Println()
+		fmt.Fprintln(out, "NODEPOOLS")
+		fmt.Fprintln(out, "  No NodePool resources found")
+		fmt.Fprintln(out, "  (Cluster may not be fully installed yet or may be in a transitional state)")
+		fmt.Fprintln(out)
 	} else {
 		for _, np := range s.NodePools {
-			printNodePoolStatus(np)
+			printNodePoolStatus(out, np)
 		}
 	}
 }
 
 // printHostedClusterStatus renders the HostedCluster section with version and conditions.
-func printHostedClusterStatus(title string, conditions []Condition, version VersionInfo) {
-	fmt.Println(title)
+func printHostedClusterStatus(out io.Writer, title string, conditions []Condition, version VersionInfo) {
+	fmt.Fprintln(out, title)
 
 	// Print version information first
-	fmt.Println("  CONTROL PLANE VERSION")
-	w := newTabWriter()
+	fmt.Fprintln(out, "  CONTROL PLANE VERSION")
+	w := newTabWriter(out)
 	if version.Current != "" || version.Desired != "" || version.Status != "" {
 		if version.Current != "" {
 			fmt.Fprintf(w, "    Current:\t%s", version.Current)
@@ -100,12 +131,12 @@ func printHostedClusterStatus(title string, conditions []Condition, version Vers
 		fmt.Fprintf(w, "    Version:\t(not available)\n")
 	}
 	w.Flush()
-	fmt.Println()
+	fmt.Fprintln(out)
 
 	// Print conditions
 	if len(conditions) > 0 {
-		fmt.Println("  CONDITIONS")
-		w = newTabWriter()
+		fmt.Fprintln(out, "  CONDITIONS")
+		w = newTabWriter(out)
 		fmt.Fprintf(w, "    CONDITION\tSTATUS\tMESSAGE\n")
 		for _, c := range conditions {
 			msg := c.Message
@@ -127,17 +158,17 @@ func printHostedClusterStatus(title string, conditions []Condition, version Vers
 		}
 		w.Flush()
 	}
-	fmt.Println()
+	fmt.Fprintln(out)
 }
 
 // printManifestWorkSync renders a compact table of ManifestWork sync status.
-func printManifestWorkSync(mws []ManifestWorkSync) {
+func printManifestWorkSync(out io.Writer, mws []ManifestWorkSync) {
 	if len(mws) == 0 {
 		return
 	}
 
-	fmt.Println("MANIFEST WORKS (Service Cluster -> Management Cluster)")
-	w := newTabWriter()
+	fmt.Fprintln(out, "MANIFEST WORKS (Service Cluster -> Management Cluster)")
+	w := newTabWriter(out)
 	fmt.Fprintf(w, "  NAME\tAPPLIED\tAVAILABLE\tLAST SYNC\n")
 	for _, mw := range mws {
 		lastSync := "(unknown)"
@@ -157,13 +188,13 @@ func printManifestWorkSync(mws []ManifestWorkSync) {
 		fmt.Fprintf(w, "  %s\t%s\t%s\t%s\n", mw.Name, boolStatus(mw.Applied), boolStatus(mw.Available), lastSync)
 	}
 	w.Flush()
-	fmt.Println()
+	fmt.Fprintln(out)
 }
 
 // printCertificateStatus renders the certificate block using tables.
-func printCertificateStatus(title string, c *CertificateStatus) {
-	fmt.Println(title)
-	w := newTabWriter()
+func printCertificateStatus(out io.Writer, title string, c *CertificateStatus) {
+	fmt.Fprintln(out, title)
+	w := newTabWriter(out)
 
 	status := "Unknown"
 	if c.Ready != nil {
@@ -192,11 +223,11 @@ func printCertificateStatus(title string, c *CertificateStatus) {
 	}
 
 	w.Flush()
-	fmt.Println()
+	fmt.Fprintln(out)
 }
 
 // printNodePoolStatus renders a single NodePool section.
-func printNodePoolStatus(np NodePoolStatus) {
+func printNodePoolStatus(out io.Writer, np NodePoolStatus) {
 	header := fmt.Sprintf("NODEPOOL: %s", np.Name)
 	details := []string{}
 	if np.Replicas > 0 {
@@ -208,9 +239,9 @@ func printNodePoolStatus(np NodePoolStatus) {
 	if len(details) > 0 {
 		header += " (" + strings.Join(details, ", ") + ")"
 	}
-	fmt.Println(header)
+	fmt.Fprintln(out, header)
 
-	w := newTabWriter()
+	w := newTabWriter(out)
 	fmt.Fprintf(w, "  CONDITION\tSTATUS\tMESSAGE\n")
 	for _, c := range np.Conditions {
 		msg := c.Message
@@ -231,7 +262,7 @@ func printNodePoolStatus(np NodePoolStatus) {
 		}
 	}
 	w.Flush()
-	fmt.Println()
+	fmt.Fprintln(out)
 }
 
 // boolStatus returns "True" or "False" for display.
@@ -243,11 +274,11 @@ func boolStatus(b bool) string {
 }
 
 // newTabWriter creates a tabwriter with intelligent defaults based on content type.
-func newTabWriter() *tabwriter.Writer {
+func newTabWriter(out io.Writer) *tabwriter.Writer {
 	// minwidth: 0 - let content determine minimum width
 	// tabwidth: 4 - reasonable tab stops
 	// padding: 2 - space between columns for readability
 	// padchar: ' ' - spaces for padding
 	// flags: 0 - default behavior
-	return tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	return tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
 }
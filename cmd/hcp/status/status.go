@@ -2,79 +2,196 @@ package status
 
 import (
 	"fmt"
+	"io"
+	"os"
+	"time"
 
+	sdk "github.com/openshift-online/ocm-sdk-go"
 	"github.com/openshift/osdctl/pkg/utils"
 	"github.com/spf13/cobra"
 )
 
+const (
+	outputText = "text"
+	outputJSON = "json"
+	outputYAML = "yaml"
+)
+
 type statusOptions struct {
-	clusterID string
+	clusterID      string
+	mgmtClusterID  string
+	output         string
+	check          bool
+	certExpiryDays int
+	concurrency    int
+	sortBy         string
+	all            bool
+
+	progress io.Writer
 }
 
 // NewCmdStatus creates and returns the status command.
 func NewCmdStatus() *cobra.Command {
-	opts := &statusOptions{}
+	opts := &statusOptions{progress: os.Stderr}
 
 	cmd := &cobra.Command{
 		Use:   "status",
 		Short: "Show HCP cluster health status from OCM live resources",
 		Long: `Display a comprehensive health overview of a ROSA HCP cluster using
 data from the OCM live resources endpoint. Shows ManifestWork sync status,
-HostedCluster conditions, certificate status, and NodePool health.`,
+HostedCluster conditions, certificate status, and NodePool health.
+
+With --check, the command exits non-zero with a summary of the problems when any
+condition is degraded, a ManifestWork is out of sync, or a certificate is not ready
+or expires within --cert-expiry-days.
+
+With --mgmt-cluster-id, the status of every hosted cluster of the management cluster
+is evaluated and the unhealthy ones are listed (all of them with --all). With --check,
+the command then exits non-zero if any hosted cluster is unhealthy.`,
 		Example: `  # Show status by cluster name
   osdctl hcp status --cluster-id my-cluster
 
   # Show status by cluster ID
-  osdctl hcp status --cluster-id 2o9r9r1q4tp0bulsfksdc8fesls54sql`,
+  osdctl hcp status --cluster-id 2o9r9r1q4tp0bulsfksdc8fesls54sql
+
+  # Show status as JSON
+  osdctl hcp status --cluster-id my-cluster -o json
+
+  # Fail if the cluster is unhealthy or a certificate expires within 14 days, e.g. before an upgrade
+  osdctl hcp status --cluster-id my-cluster --check --cert-expiry-days 14
+
+  # List the unhealthy hosted clusters of a management cluster, soonest expiring certificate first
+  osdctl hcp status --mgmt-cluster-id my-mc --sort-by cert-expiry
+
+  # Export the health of every hosted cluster of a management cluster as CSV
+  osdctl hcp status --mgmt-cluster-id my-mc --all --concurrency 20 -o csv > status.csv`,
 		Args:              cobra.NoArgs,
 		DisableAutoGenTag: true,
 		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
 			return opts.run()
 		},
 	}
 
 	cmd.Flags().StringVarP(&opts.clusterID, "cluster-id", "C", "", "Cluster name, ID, or external ID")
-	_ = cmd.MarkFlagRequired("cluster-id")
+	cmd.Flags().StringVar(&opts.mgmtClusterID, "mgmt-cluster-id", "", "Management cluster ID or name, to evaluate all its hosted clusters")
+	cmd.Flags().StringVarP(&opts.output, "output", "o", outputText, "Output format. One of: text, json, yaml, csv (csv only with --mgmt-cluster-id)")
+	cmd.Flags().BoolVar(&opts.check, "check", false, "Exit non-zero with a summary of the problems if the cluster is unhealthy")
+	cmd.Flags().IntVar(&opts.certExpiryDays, "cert-expiry-days", 30, "Number of days within which an expiring certificate is a problem")
+	cmd.Flags().IntVar(&opts.concurrency, "concurrency", 10, "With --mgmt-cluster-id, number of hosted clusters evaluated at a time")
+	cmd.Flags().StringVar(&opts.sortBy, "sort-by", sortByProblems, "With --mgmt-cluster-id, sort the hosted clusters by: problems, name, cert-expiry")
+	cmd.Flags().BoolVar(&opts.all, "all", false, "With --mgmt-cluster-id, list the healthy hosted clusters as well")
+	cmd.MarkFlagsOneRequired("cluster-id", "mgmt-cluster-id")
+	cmd.MarkFlagsMutuallyExclusive("cluster-id", "mgmt-cluster-id")
 
 	return cmd
 }
 
 func (o *statusOptions) run() error {
+	if err := o.validate(); err != nil {
+		return err
+	}
+
 	conn, err := utils.CreateConnection()
 	if err != nil {
 		return fmt.Errorf("failed to create OCM connection: %w", err)
 	}
 	defer conn.Close()
 
-	cluster, err := utils.GetCluster(conn, o.clusterID)
+	if o.mgmtClusterID != "" {
+		return o.runSweep(conn)
+	}
+
+	status, err := FetchStatus(conn, o.clusterID)
+	if err != nil {
+		return err
+	}
+
+	if err := printStatusAs(os.Stdout, status, o.output); err != nil {
+		return err
+	}
+
+	if o.check {
+		problems := CheckStatus(status, o.certExpiryDays, time.Now())
+		if len(problems) > 0 {
+			printProblems(os.Stderr, status, problems)
+			return fmt.Errorf("cluster %s is unhealthy: %d problem(s) found", status.ClusterName, len(problems))
+		}
+	}
+
+	return nil
+}
+
+func (o *statusOptions) validate() error {
+	validOutputs := map[string]bool{outputText: true, outputJSON: true, outputYAML: true}
+	if o.mgmtClusterID != "" {
+		validOutputs[outputCSV] = true
+	}
+	if !validOutputs[o.output] {
+		return fmt.Errorf("invalid output format '%s'. Valid options: text, json, yaml, or csv with --mgmt-cluster-id", o.output)
+	}
+	if o.certExpiryDays < 0 {
+		return fmt.Errorf("--cert-expiry-days cannot be negative")
+	}
+	if o.concurrency < 1 {
+		return fmt.Errorf("--concurrency must be at least 1")
+	}
+	if o.sortBy != sortByProblems && o.sortBy != sortByName && o.sortBy != sortByCertExpiry {
+		return fmt.Errorf("invalid sort-by '%s'. Valid options: problems, name, cert-expiry", o.sortBy)
+	}
+	return nil
+}
+
+// runSweep prints the health of the hosted clusters of the management cluster.
+func (o *statusOptions) runSweep(conn *sdk.Connection) error {
+	results, err := o.sweep(conn)
 	if err != nil {
-		return fmt.Errorf("failed to find cluster: %w", err)
+		return err
+	}
+
+	sortSweepResults(results.Clusters, o.sortBy)
+	if !o.all {
+		results.Clusters = unhealthySweepResults(results.Clusters)
+	}
+	if err := printSweepResults(os.Stdout, results, o.output); err != nil {
+		return err
+	}
+
+	if o.check && results.UnhealthyClusters > 0 {
+		return fmt.Errorf("%d/%d hosted clusters of %s are unhealthy", results.UnhealthyClusters, results.TotalClusters, results.ManagementCluster)
+	}
+	return nil
+}
+
+// FetchStatus returns the status of the HCP cluster from the OCM live resources endpoint.
+func FetchStatus(conn *sdk.Connection, clusterKey string) (*HCPStatus, error) {
+	cluster, err := utils.GetCluster(conn, clusterKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find cluster: %w", err)
 	}
 
 	if !cluster.Hypershift().Enabled() {
-		return fmt.Errorf("cluster %q is not an HCP cluster", o.clusterID)
+		return nil, fmt.Errorf("cluster %q is not an HCP cluster", clusterKey)
 	}
 
 	liveResponse, err := conn.ClustersMgmt().V1().Clusters().Cluster(cluster.ID()).Resources().Live().Get().Send()
 	if err != nil {
-		return fmt.Errorf("failed to get live resources: %w", err)
+		return nil, fmt.Errorf("failed to get live resources: %w", err)
 	}
 
 	resources := liveResponse.Body().Resources()
 	if len(resources) == 0 {
-		return fmt.Errorf("no live resources found for cluster %s", cluster.ID())
+		return nil, fmt.Errorf("no live resources found for cluster %s", cluster.ID())
 	}
 
 	status, err := parseLiveResources(resources, cluster.ID())
 	if err != nil {
-		return fmt.Errorf("failed to parse live resources: %w", err)
+		return nil, fmt.Errorf("failed to parse live resources: %w", err)
 	}
 
 	status.ClusterID = cluster.ExternalID()
 	status.ClusterName = cluster.Name()
 	status.ClusterState = string(cluster.State())
 
-	printStatus(status)
-
-	return nil
+	return status, nil
 }
@@ -0,0 +1,263 @@
+package status
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	sdk "github.com/openshift-online/ocm-sdk-go"
+	getcpautoscalingstatus "github.com/openshift/osdctl/cmd/hcp/get-cp-autoscaling-status"
+	"github.com/openshift/osdctl/pkg/printer"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	outputCSV = "csv"
+
+	sortByProblems   = "problems"
+	sortByName       = "name"
+	sortByCertExpiry = "cert-expiry"
+
+	labelClusterID = "api.openshift.com/id"
+)
+
+// SweepResult is the health of a hosted cluster found by a management cluster sweep.
+type SweepResult struct {
+	ClusterID              string     `json:"clusterId" yaml:"clusterId"`
+	ClusterName            string     `json:"clusterName" yaml:"clusterName"`
+	Namespace              string     `json:"namespace" yaml:"namespace"`
+	Version                string     `json:"version" yaml:"version"`
+	Healthy                bool       `json:"healthy" yaml:"healthy"`
+	DegradedConditions     int        `json:"degradedConditions" yaml:"degradedConditions"`
+	OutOfSyncManifestWorks int        `json:"outOfSyncManifestWorks" yaml:"outOfSyncManifestWorks"`
+	UnhealthyNodePools     int        `json:"unhealthyNodePools" yaml:"unhealthyNodePools"`
+	CertificateExpiry      *time.Time `json:"certificateExpiry,omitempty" yaml:"certificateExpiry,omitempty"`
+	Problems               []string   `json:"problems,omitempty" yaml:"problems,omitempty"`
+	Error                  string     `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// SweepResults are the health of the hosted clusters of a management cluster.
+type SweepResults struct {
+	Timestamp         time.Time     `json:"timestamp" yaml:"timestamp"`
+	ManagementCluster string        `json:"managementCluster" yaml:"managementCluster"`
+	TotalClusters     int           `json:"totalClusters" yaml:"totalClusters"`
+	UnhealthyClusters int           `json:"unhealthyClusters" yaml:"unhealthyClusters"`
+	Clusters          []SweepResult `json:"clusters" yaml:"clusters"`
+}
+
+// sweep evaluates the status of every hosted cluster of the management cluster, at most
+// o.concurrency at a time.
+func (o *statusOptions) sweep(conn *sdk.Connection) (*SweepResults, error) {
+	ctx := context.Background()
+	mgmtClient, mc, err := getcpautoscalingstatus.ManagementClusterClient(conn, o.mgmtClusterID)
+	if err != nil {
+		return nil, err
+	}
+
+	namespaces, err := getcpautoscalingstatus.ListOcmNamespacesWithRetry(ctx, mgmtClient)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the hosted cluster namespaces of %s: %w", mc.Name(), err)
+	}
+	fmt.Fprintf(o.progress, "Checking %d hosted clusters on %s\n", len(namespaces), mc.Name())
+
+	now := time.Now()
+	results := &SweepResults{
+		Timestamp:         now,
+		ManagementCluster: mc.Name(),
+		Clusters:          make([]SweepResult, len(namespaces)),
+	}
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, o.concurrency)
+	for i, ns := range namespaces {
+		wg.Add(1)
+		go func(i int, namespace string) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			result := SweepResult{Namespace: namespace}
+			hc, err := getcpautoscalingstatus.GetHostedClusterInNamespace(ctx, mgmtClient, namespace)
+			if err != nil {
+				results.Clusters[i] = failedSweepResult(result, err)
+				return
+			}
+			result.ClusterName = hc.Name
+			result.ClusterID = hc.Labels[labelClusterID]
+			if result.ClusterID == "" {
+				results.Clusters[i] = failedSweepResult(result, fmt.Errorf("HostedCluster %s has no %s label", hc.Name, labelClusterID))
+				return
+			}
+
+			status, err := FetchStatus(conn, result.ClusterID)
+			if err != nil {
+				results.Clusters[i] = failedSweepResult(result, err)
+				return
+			}
+			results.Clusters[i] = evaluateSweepResult(result, status, o.certExpiryDays, now)
+		}(i, ns.Name)
+	}
+	wg.Wait()
+
+	results.TotalClusters = len(results.Clusters)
+	for _, result := range results.Clusters {
+		if !result.Healthy {
+			results.UnhealthyClusters++
+		}
+	}
+	return results, nil
+}
+
+func failedSweepResult(result SweepResult, err error) SweepResult {
+	result.Healthy = false
+	result.Error = err.Error()
+	result.Problems = []string{fmt.Sprintf("failed to get status: %v", err)}
+	return result
+}
+
+// evaluateSweepResult summarizes the problems of the status of a hosted cluster.
+func evaluateSweepResult(result SweepResult, s *HCPStatus, certExpiryDays int, now time.Time) SweepResult {
+	result.Version = s.Version.Current
+	result.DegradedConditions = len(degradedHostedClusterConditions(s))
+	result.OutOfSyncManifestWorks = len(outOfSyncManifestWorks(s))
+	for _, np := range s.NodePools {
+		if degraded := degradedNodePoolConditions(np); len(degraded) > 0 {
+			result.DegradedConditions += len(degraded)
+			result.UnhealthyNodePools++
+		}
+	}
+	for _, c := range []*CertificateStatus{s.APIServerCertificate, s.IngressCertificate} {
+		if c != nil && !c.NotAfter.IsZero() && (result.CertificateExpiry == nil || c.NotAfter.Before(*result.CertificateExpiry)) {
+			notAfter := c.NotAfter
+			result.CertificateExpiry = &notAfter
+		}
+	}
+
+	result.Problems = CheckStatus(s, certExpiryDays, now)
+	result.Healthy = len(result.Problems) == 0
+	return result
+}
+
+// sortSweepResults sorts the results by number of problems (most first), name or certificate expiry (soonest first).
+func sortSweepResults(results []SweepResult, sortBy string) {
+	sort.SliceStable(results, func(i, j int) bool {
+		a, b := results[i], results[j]
+		switch sortBy {
+		case sortByProblems:
+			if len(a.Problems) != len(b.Problems) {
+				return len(a.Problems) > len(b.Problems)
+			}
+		case sortByCertExpiry:
+			if (a.CertificateExpiry == nil) != (b.CertificateExpiry == nil) {
+				return a.CertificateExpiry != nil
+			}
+			if a.CertificateExpiry != nil && !a.CertificateExpiry.Equal(*b.CertificateExpiry) {
+				return a.CertificateExpiry.Before(*b.CertificateExpiry)
+			}
+		}
+		return a.ClusterName < b.ClusterName
+	})
+}
+
+// unhealthySweepResults returns the results without the healthy clusters.
+func unhealthySweepResults(results []SweepResult) []SweepResult {
+	unhealthy := []SweepResult{}
+	for _, result := range results {
+		if !result.Healthy {
+			unhealthy = append(unhealthy, result)
+		}
+	}
+	return unhealthy
+}
+
+func printSweepResults(out io.Writer, results *SweepResults, output string) error {
+	switch output {
+	case outputJSON:
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(results)
+	case outputYAML:
+		data, err := yaml.Marshal(results)
+		if err != nil {
+			return fmt.Errorf("failed to marshal sweep results to YAML: %w", err)
+		}
+		_, err = out.Write(data)
+		return err
+	case outputCSV:
+		return printSweepCSV(out, results)
+	default:
+		return printSweepTable(out, results)
+	}
+}
+
+func printSweepTable(out io.Writer, results *SweepResults) error {
+	fmt.Fprintf(out, "Management Cluster: %s\n", results.ManagementCluster)
+	fmt.Fprintf(out, "Unhealthy Hosted Clusters: %d/%d\n\n", results.UnhealthyClusters, results.TotalClusters)
+	if len(results.Clusters) == 0 {
+		fmt.Fprintln(out, "No hosted clusters to show")
+		return nil
+	}
+
+	p := printer.NewTablePrinter(out, 20, 1, 3, ' ')
+	p.AddRow([]string{"CLUSTER NAME", "CLUSTER ID", "VERSION", "HEALTHY", "DEGRADED CONDITIONS", "OUT OF SYNC MWS", "UNHEALTHY NODEPOOLS", "CERT EXPIRY", "FIRST PROBLEM"})
+	for _, r := range results.Clusters {
+		firstProblem := ""
+		if len(r.Problems) > 0 {
+			firstProblem = r.Problems[0]
+			if len(r.Problems) > 1 {
+				firstProblem += fmt.Sprintf(" (+%d more)", len(r.Problems)-1)
+			}
+		}
+		p.AddRow([]string{
+			r.ClusterName,
+			r.ClusterID,
+			r.Version,
+			boolStatus(r.Healthy),
+			strconv.Itoa(r.DegradedConditions),
+			strconv.Itoa(r.OutOfSyncManifestWorks),
+			strconv.Itoa(r.UnhealthyNodePools),
+			formatCertificateExpiry(r.CertificateExpiry),
+			firstProblem,
+		})
+	}
+	return p.Flush()
+}
+
+func printSweepCSV(out io.Writer, results *SweepResults) error {
+	w := csv.NewWriter(out)
+	if err := w.Write([]string{"clusterName", "clusterId", "namespace", "version", "healthy", "degradedConditions", "outOfSyncManifestWorks", "unhealthyNodePools", "certificateExpiry", "problems"}); err != nil {
+		return fmt.Errorf("failed to write CSV header: %v", err)
+	}
+	for _, r := range results.Clusters {
+		if err := w.Write([]string{
+			r.ClusterName,
+			r.ClusterID,
+			r.Namespace,
+			r.Version,
+			strconv.FormatBool(r.Healthy),
+			strconv.Itoa(r.DegradedConditions),
+			strconv.Itoa(r.OutOfSyncManifestWorks),
+			strconv.Itoa(r.UnhealthyNodePools),
+			formatCertificateExpiry(r.CertificateExpiry),
+			strings.Join(r.Problems, "; "),
+		}); err != nil {
+			return err
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
+
+func formatCertificateExpiry(expiry *time.Time) string {
+	if expiry == nil {
+		return ""
+	}
+	return expiry.Format("2006-01-02")
+}
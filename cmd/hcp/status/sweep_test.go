@@ -0,0 +1,142 @@
+package status
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEvaluateSweepResult(t *testing.T) {
+	now := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	apiExpiry := now.AddDate(0, 0, 90)
+	ingressExpiry := now.AddDate(0, 0, 7)
+	s := &HCPStatus{
+		Version:                 VersionInfo{Current: "4.17.10"},
+		ManifestWorks:           []ManifestWorkSync{{Name: "abc123", Applied: true, Available: false}},
+		HostedClusterConditions: []Condition{{Type: "Available", Status: "False"}, {Type: "Degraded", Status: "False"}},
+		APIServerCertificate:    &CertificateStatus{NotAfter: apiExpiry},
+		IngressCertificate:      &CertificateStatus{NotAfter: ingressExpiry},
+		NodePools: []NodePoolStatus{
+			{Name: "workers-a", Conditions: []Condition{{Type: "Ready", Status: "False"}, {Type: "AllNodesHealthy", Status: "False"}}},
+			{Name: "workers-b", Conditions: []Condition{{Type: "Ready", Status: "True"}}},
+		},
+	}
+
+	result := evaluateSweepResult(SweepResult{ClusterName: "my-cluster"}, s, 30, now)
+
+	if result.Healthy {
+		t.Errorf("expected the cluster to be unhealthy")
+	}
+	if result.Version != "4.17.10" {
+		t.Errorf("expected version 4.17.10, got %s", result.Version)
+	}
+	if result.DegradedConditions != 3 || result.OutOfSyncManifestWorks != 1 || result.UnhealthyNodePools != 1 {
+		t.Errorf("unexpected counts: %d degraded conditions, %d out of sync manifest works, %d unhealthy node pools",
+			result.DegradedConditions, result.OutOfSyncManifestWorks, result.UnhealthyNodePools)
+	}
+	if result.CertificateExpiry == nil || !result.CertificateExpiry.Equal(ingressExpiry) {
+		t.Errorf("expected the soonest certificate expiry %v, got %v", ingressExpiry, result.CertificateExpiry)
+	}
+	if len(result.Problems) != 5 {
+		t.Errorf("expected 5 problems, got %v", result.Problems)
+	}
+
+	healthy := evaluateSweepResult(SweepResult{}, &HCPStatus{HostedClusterConditions: []Condition{{Type: "Available", Status: "True"}}}, 30, now)
+	if !healthy.Healthy || len(healthy.Problems) != 0 || healthy.CertificateExpiry != nil {
+		t.Errorf("expected a healthy result, got %+v", healthy)
+	}
+}
+
+func TestFailedSweepResult(t *testing.T) {
+	result := failedSweepResult(SweepResult{Namespace: "ocm-production-abc123"}, errors.New("no HostedCluster found"))
+	if result.Healthy || result.Error != "no HostedCluster found" || len(result.Problems) != 1 {
+		t.Errorf("unexpected failed result %+v", result)
+	}
+}
+
+func TestSortSweepResults(t *testing.T) {
+	soon := time.Date(2025, 6, 5, 0, 0, 0, 0, time.UTC)
+	later := time.Date(2025, 8, 1, 0, 0, 0, 0, time.UTC)
+	results := func() []SweepResult {
+		return []SweepResult{
+			{ClusterName: "charlie", Problems: []string{"a"}, CertificateExpiry: &later},
+			{ClusterName: "alpha", Healthy: true},
+			{ClusterName: "bravo", Problems: []string{"a", "b"}, CertificateExpiry: &soon},
+			{ClusterName: "delta", Problems: []string{"a"}},
+		}
+	}
+	names := func(results []SweepResult) string {
+		var names []string
+		for _, r := range results {
+			names = append(names, r.ClusterName)
+		}
+		return strings.Join(names, ",")
+	}
+
+	tests := []struct {
+		sortBy   string
+		expected string
+	}{
+		{sortByProblems, "bravo,charlie,delta,alpha"},
+		{sortByName, "alpha,bravo,charlie,delta"},
+		{sortByCertExpiry, "bravo,charlie,alpha,delta"},
+	}
+	for _, tt := range tests {
+		r := results()
+		sortSweepResults(r, tt.sortBy)
+		if got := names(r); got != tt.expected {
+			t.Errorf("sort by %s: expected %s, got %s", tt.sortBy, tt.expected, got)
+		}
+	}
+
+	if got := names(unhealthySweepResults(results())); got != "charlie,bravo,delta" {
+		t.Errorf("expected only the unhealthy clusters, got %s", got)
+	}
+}
+
+func TestPrintSweepResults(t *testing.T) {
+	expiry := time.Date(2025, 6, 5, 0, 0, 0, 0, time.UTC)
+	results := &SweepResults{
+		ManagementCluster: "hs-mc-1",
+		TotalClusters:     3,
+		UnhealthyClusters: 1,
+		Clusters: []SweepResult{{
+			ClusterName:        "bravo",
+			ClusterID:          "abc123",
+			Namespace:          "ocm-production-abc123",
+			Version:            "4.17.10",
+			DegradedConditions: 1,
+			CertificateExpiry:  &expiry,
+			Problems:           []string{"HostedCluster condition Available is False: etcd", "Ingress certificate is not ready"},
+		}},
+	}
+
+	var out bytes.Buffer
+	if err := printSweepResults(&out, results, outputCSV); err != nil {
+		t.Fatal(err)
+	}
+	rows, err := csv.NewReader(&out).ReadAll()
+	if err != nil {
+		t.Fatalf("invalid CSV output: %v", err)
+	}
+	if len(rows) != 2 {
+		t.Fatalf("expected a header and 1 row, got %d rows", len(rows))
+	}
+	expected := []string{"bravo", "abc123", "ocm-production-abc123", "4.17.10", "false", "1", "0", "0", "2025-06-05", "HostedCluster condition Available is False: etcd; Ingress certificate is not ready"}
+	if strings.Join(rows[1], "|") != strings.Join(expected, "|") {
+		t.Errorf("expected row:\n%v\ngot:\n%v", expected, rows[1])
+	}
+
+	out.Reset()
+	if err := printSweepResults(&out, results, outputText); err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{"Unhealthy Hosted Clusters: 1/3", "bravo", "(+1 more)"} {
+		if !strings.Contains(out.String(), expected) {
+			t.Errorf("expected %q in the table:\n%s", expected, out.String())
+		}
+	}
+}
@@ -4,58 +4,58 @@ import "time"
 
 // HCPStatus holds the parsed status of an HCP cluster from the live endpoint.
 type HCPStatus struct {
-	ClusterID               string
-	ClusterName             string
-	ClusterState            string
-	ManagementCluster       string
-	Version                 VersionInfo
-	APIServerCertificate    *CertificateStatus
-	IngressCertificate      *CertificateStatus
-	ManifestWorks           []ManifestWorkSync
-	HostedClusterConditions []Condition
-	NodePools               []NodePoolStatus
+	ClusterID               string             `json:"clusterId" yaml:"clusterId"`
+	ClusterName             string             `json:"clusterName" yaml:"clusterName"`
+	ClusterState            string             `json:"clusterState" yaml:"clusterState"`
+	ManagementCluster       string             `json:"managementCluster" yaml:"managementCluster"`
+	Version                 VersionInfo        `json:"version" yaml:"version"`
+	APIServerCertificate    *CertificateStatus `json:"apiServerCertificate,omitempty" yaml:"apiServerCertificate,omitempty"`
+	IngressCertificate      *CertificateStatus `json:"ingressCertificate,omitempty" yaml:"ingressCertificate,omitempty"`
+	ManifestWorks           []ManifestWorkSync `json:"manifestWorks" yaml:"manifestWorks"`
+	HostedClusterConditions []Condition        `json:"hostedClusterConditions" yaml:"hostedClusterConditions"`
+	NodePools               []NodePoolStatus   `json:"nodePools" yaml:"nodePools"`
 }
 
 // ManifestWorkSync represents the sync status of a single ManifestWork.
 type ManifestWorkSync struct {
-	Name         string
-	Applied      bool
-	Available    bool
-	LastSyncTime time.Time
+	Name         string    `json:"name" yaml:"name"`
+	Applied      bool      `json:"applied" yaml:"applied"`
+	Available    bool      `json:"available" yaml:"available"`
+	LastSyncTime time.Time `json:"lastSyncTime" yaml:"lastSyncTime"`
 }
 
 // VersionInfo holds cluster version details.
 type VersionInfo struct {
-	Current          string
-	Desired          string
-	Status           string
-	Image            string
-	AvailableUpdates []string
+	Current          string   `json:"current" yaml:"current"`
+	Desired          string   `json:"desired" yaml:"desired"`
+	Status           string   `json:"status" yaml:"status"`
+	Image            string   `json:"image" yaml:"image"`
+	AvailableUpdates []string `json:"availableUpdates,omitempty" yaml:"availableUpdates,omitempty"`
 }
 
 // CertificateStatus holds the certificate details.
 type CertificateStatus struct {
-	Ready       *bool // nil = unknown, true/false = known status
-	NotAfter    time.Time
-	RenewalTime time.Time
-	DNSNames    []string
+	Ready       *bool     `json:"ready,omitempty" yaml:"ready,omitempty"` // nil = unknown, true/false = known status
+	NotAfter    time.Time `json:"notAfter" yaml:"notAfter"`
+	RenewalTime time.Time `json:"renewalTime" yaml:"renewalTime"`
+	DNSNames    []string  `json:"dnsNames,omitempty" yaml:"dnsNames,omitempty"`
 }
 
 // Condition represents a single condition from a HostedCluster or NodePool.
 type Condition struct {
-	Type               string
-	Status             string
-	Reason             string
-	Message            string
-	LastTransitionTime string
+	Type               string `json:"type" yaml:"type"`
+	Status             string `json:"status" yaml:"status"`
+	Reason             string `json:"reason" yaml:"reason"`
+	Message            string `json:"message" yaml:"message"`
+	LastTransitionTime string `json:"lastTransitionTime" yaml:"lastTransitionTime"`
 }
 
 // NodePoolStatus holds the status of a single NodePool.
 type NodePoolStatus struct {
-	Name       string
-	Replicas   int
-	Version    string
-	Conditions []Condition
+	Name       string      `json:"name" yaml:"name"`
+	Replicas   int         `json:"replicas" yaml:"replicas"`
+	Version    string      `json:"version" yaml:"version"`
+	Conditions []Condition `json:"conditions" yaml:"conditions"`
 }
 
 // mainMWResult holds the parsed output from the main ManifestWork.
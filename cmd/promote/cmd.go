@@ -4,9 +4,11 @@ import (
 	"fmt"
 
 	"github.com/openshift/osdctl/cmd/promote/dynatrace"
+	"github.com/openshift/osdctl/cmd/promote/git"
 	"github.com/openshift/osdctl/cmd/promote/pko"
 	"github.com/openshift/osdctl/cmd/promote/saas"
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
 )
 
 // NewCmdPromote implements the promote command to promote services/operators
@@ -18,7 +20,11 @@ func NewCmdPromote() *cobra.Command {
 		DisableAutoGenTag: true,
 	}
 
+	promoteCmd.PersistentFlags().String("git-backend", git.BackendExec, fmt.Sprintf("Git implementation used to fetch the service repositories and commit to app-interface. One of: %s, %s", git.BackendExec, git.BackendGoGit))
+	_ = viper.BindPFlag(git.GitBackendKey, promoteCmd.PersistentFlags().Lookup("git-backend"))
+
 	promoteCmd.AddCommand(saas.NewCmdSaas())
+	promoteCmd.AddCommand(saas.NewCmdStatus())
 	promoteCmd.AddCommand(pko.NewCmdPKO())
 	promoteCmd.AddCommand(dynatrace.NewCmdDynatrace())
 
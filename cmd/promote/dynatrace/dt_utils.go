@@ -133,7 +133,7 @@ func servicePromotion(appInterface git.AppInterface, component, gitHash string)
 
 	fmt.Printf("Current Git Hash: %v\nGit Repo: %v\nComponent path: %v\n", currentGitHash, serviceRepo, serviceFullPath)
 
-	promotionGitHash, commitLog, err := git.CheckoutAndCompareGitHash(appInterface.GitExecutor, serviceRepo, gitHash, currentGitHash, strings.TrimPrefix(serviceFullPath, "/"))
+	promotionGitHash, commitLog, err := appInterface.CheckoutAndCompareGitHash(serviceRepo, gitHash, currentGitHash, strings.TrimPrefix(serviceFullPath, "/"))
 	if err != nil {
 		return fmt.Errorf("failed to checkout and compare git hash: %v", err)
 	} else if promotionGitHash == "" {
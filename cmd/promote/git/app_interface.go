@@ -11,6 +11,7 @@ import (
 
 	"github.com/openshift/osdctl/cmd/promote/iexec"
 	"github.com/openshift/osdctl/cmd/promote/pathutil"
+	"github.com/spf13/viper"
 	kyaml "sigs.k8s.io/kustomize/kyaml/yaml"
 
 	"gopkg.in/yaml.v3"
@@ -39,6 +40,9 @@ type Service struct {
 type AppInterface struct {
 	GitDirectory string
 	GitExecutor  iexec.IExec
+	// Backend performs the service repository checkouts and the commits, the exec backend
+	// running GitExecutor is used if nil.
+	Backend Backend
 }
 
 // replaceTargetSha replaces sha for targets in file whose name matches a given substring
@@ -82,6 +86,11 @@ func DefaultAppInterfaceDirectory() string {
 func BootstrapOsdCtlForAppInterfaceAndServicePromotions(appInterfaceCheckoutDir string, gitExecutor iexec.Exec) AppInterface {
 	a := AppInterface{}
 	a.GitExecutor = gitExecutor
+	backend, err := NewBackend(viper.GetString(GitBackendKey), gitExecutor)
+	if err != nil {
+		log.Fatalf("Invalid git backend: %v", err)
+	}
+	a.Backend = backend
 	if appInterfaceCheckoutDir != "" {
 		a.GitDirectory = appInterfaceCheckoutDir
 		err := a.checkAppInterfaceCheckout()
@@ -239,8 +248,13 @@ func GetCurrentPackageTagFromAppInterface(saasFile string) (string, error) {
 		return "", fmt.Errorf("failed to read file '%s': %w", saasFile, err)
 	}
 
+	return GetPackageTagFromSaasFile(saasData)
+}
+
+// GetPackageTagFromSaasFile returns the package tag of the production targets of a saas file.
+func GetPackageTagFromSaasFile(saasData []byte) (string, error) {
 	service := Service{}
-	err = yaml.Unmarshal(saasData, &service)
+	err := yaml.Unmarshal(saasData, &service)
 	if err != nil {
 		return "", fmt.Errorf("failed to unmarshal service definition: %w", err)
 	}
@@ -265,7 +279,15 @@ func GetCurrentPackageTagFromAppInterface(saasFile string) (string, error) {
 }
 
 func (a *AppInterface) UpdateAppInterface(_, saasFile, currentGitHash, promotionGitHash, branchName string, hotfix bool) error {
+	if err := a.CreatePromotionBranch(branchName); err != nil {
+		return err
+	}
+
+	return a.UpdateSaasFileHash(saasFile, currentGitHash, promotionGitHash, hotfix)
+}
 
+// CreatePromotionBranch (re)creates branchName from master and checks it out.
+func (a *AppInterface) CreatePromotionBranch(branchName string) error {
 	if err := a.GitExecutor.Run(a.GitDirectory, "git", "checkout", "master"); err != nil {
 		return fmt.Errorf("failed to checkout master: branch %v", err)
 	}
@@ -277,6 +299,13 @@ func (a *AppInterface) UpdateAppInterface(_, saasFile, currentGitHash, promotion
 	if err := a.GitExecutor.Run(a.GitDirectory, "git", "checkout", "-b", branchName, "master"); err != nil {
 		return fmt.Errorf("failed to create branch %s: %v, does it already exist? If so, please delete it with `git branch -D %s` first", branchName, err, branchName)
 	}
+
+	return nil
+}
+
+// UpdateSaasFileHash replaces currentGitHash with promotionGitHash in the saas file. Only the
+// canary targets are updated if the saas file has some, unless hotfix is set.
+func (a *AppInterface) UpdateSaasFileHash(saasFile, currentGitHash, promotionGitHash string, hotfix bool) error {
 	// Update the hash in the SAAS file
 	fileContent, err := os.ReadFile(saasFile)
 	if err != nil {
@@ -336,23 +365,30 @@ func (a *AppInterface) UpdatePackageTag(saasFile, oldTag, promotionTag, branchNa
 	return nil
 }
 
-func (a *AppInterface) CommitSaasFile(saasFile, commitMessage string) error {
-	// Commit the change
-	if err := a.GitExecutor.Run(a.GitDirectory, "git", "add", saasFile); err != nil {
-		return fmt.Errorf("failed to add file %s: %v", saasFile, err)
-	}
-	if err := a.GitExecutor.Run(a.GitDirectory, "git", "commit", "-m", commitMessage); err != nil {
-		return fmt.Errorf("failed to commit changes: %v", err)
+// backend returns the git backend of app-interface, defaulting to running GitExecutor.
+func (a *AppInterface) backend() Backend {
+	if a.Backend == nil {
+		return execBackend{gitExecutor: a.GitExecutor}
 	}
+	return a.Backend
+}
 
-	return nil
+// CheckoutAndCompareGitHash returns the hash of the service repository to promote and the commit
+// log since currentGitHash, using the git backend of app-interface.
+func (a *AppInterface) CheckoutAndCompareGitHash(gitURL, gitHash, currentGitHash, serviceFullPath string) (string, string, error) {
+	return a.backend().CheckoutAndCompareGitHash(gitURL, gitHash, currentGitHash, serviceFullPath)
 }
 
-func (a *AppInterface) CommitSaasAndAppYmlFile(saasFile, serviceName, commitMessage string) error {
-	if err := a.GitExecutor.Run(a.GitDirectory, "git", "add", saasFile); err != nil {
-		return fmt.Errorf("failed to add file %s: %v", saasFile, err)
-	}
+// CommitFiles adds the files and commits them with commitMessage.
+func (a *AppInterface) CommitFiles(commitMessage string, files ...string) error {
+	return a.backend().CommitFiles(a.GitDirectory, commitMessage, files...)
+}
+
+func (a *AppInterface) CommitSaasFile(saasFile, commitMessage string) error {
+	return a.backend().CommitFiles(a.GitDirectory, commitMessage, saasFile)
+}
 
+func (a *AppInterface) CommitSaasAndAppYmlFile(saasFile, serviceName, commitMessage string) error {
 	componentName := strings.TrimPrefix(serviceName, "saas-")
 
 	appYmlPath, err := pathutil.DeriveAppYmlPath(a.GitDirectory, saasFile, componentName)
@@ -360,13 +396,5 @@ func (a *AppInterface) CommitSaasAndAppYmlFile(saasFile, serviceName, commitMess
 		return fmt.Errorf("failed to derive app.yml path: %v", err)
 	}
 
-	if err := a.GitExecutor.Run(a.GitDirectory, "git", "add", appYmlPath); err != nil {
-		return fmt.Errorf("failed to add file %s: %v", appYmlPath, err)
-	}
-
-	if err := a.GitExecutor.Run(a.GitDirectory, "git", "commit", "-m", commitMessage); err != nil {
-		return fmt.Errorf("failed to commit changes: %v", err)
-	}
-
-	return nil
+	return a.backend().CommitFiles(a.GitDirectory, commitMessage, saasFile, appYmlPath)
 }
@@ -95,6 +95,23 @@ func TestCommitSaasFile(t *testing.T) {
 	}
 }
 
+func TestCommitFiles(t *testing.T) {
+	mockExec := new(MockExec)
+	mockExec.On("Run", "/app-interface", "git", []string{"add", "a.yaml"}).Return(nil).Once()
+	mockExec.On("Run", "/app-interface", "git", []string{"add", "b.yaml"}).Return(nil).Once()
+	mockExec.On("Run", "/app-interface", "git", []string{"commit", "-m", "promote"}).Return(nil).Once()
+
+	app := AppInterface{GitDirectory: "/app-interface", GitExecutor: mockExec}
+	require.NoError(t, app.CommitFiles("promote", "a.yaml", "b.yaml"))
+	mockExec.AssertExpectations(t)
+
+	failingExec := new(MockExec)
+	failingExec.On("Run", "/app-interface", "git", []string{"add", "a.yaml"}).Return(errors.New("no such file")).Once()
+	app = AppInterface{GitDirectory: "/app-interface", GitExecutor: failingExec}
+	assert.ErrorContains(t, app.CommitFiles("promote", "a.yaml", "b.yaml"), "failed to add file a.yaml")
+	failingExec.AssertExpectations(t)
+}
+
 func TestGetCurrentGitHashFromAppInterface(t *testing.T) {
 	tests := map[string]struct {
 		yamlContent   string
@@ -0,0 +1,68 @@
+package git
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/openshift/osdctl/cmd/promote/iexec"
+)
+
+const (
+	// GitBackendKey is the osdctl config key (and promote flag) selecting the git backend.
+	GitBackendKey = "promote_git_backend"
+
+	BackendExec  = "exec"
+	BackendGoGit = "go-git"
+)
+
+// Backend performs the git operations of a promotion on the service repositories and app-interface.
+type Backend interface {
+	// CheckoutAndCompareGitHash returns the hash to promote - gitHash, or the HEAD of the service
+	// repository (the last commit changing serviceFullPath if set) when empty - and the commit log
+	// since currentGitHash.
+	CheckoutAndCompareGitHash(gitURL, gitHash, currentGitHash, serviceFullPath string) (string, string, error)
+	// CommitFiles adds the files to the index of the repository in dir and commits them.
+	CommitFiles(dir, commitMessage string, files ...string) error
+}
+
+// NewBackend returns the git backend called name. The exec backend runs the git binary through gitExecutor.
+func NewBackend(name string, gitExecutor iexec.IExec) (Backend, error) {
+	switch name {
+	case "", BackendExec:
+		return execBackend{gitExecutor: gitExecutor}, nil
+	case BackendGoGit:
+		cacheDir, err := os.UserCacheDir()
+		if err != nil {
+			return nil, fmt.Errorf("cannot locate the cache directory: %v", err)
+		}
+		return &GoGitBackend{
+			CacheDir: filepath.Join(cacheDir, "osdctl", "promote", "repos"),
+			Depth:    defaultFetchDepth,
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown git backend %q (allowed: %s, %s)", name, BackendExec, BackendGoGit)
+	}
+}
+
+// execBackend shells out to the git binary.
+type execBackend struct {
+	gitExecutor iexec.IExec
+}
+
+func (b execBackend) CheckoutAndCompareGitHash(gitURL, gitHash, currentGitHash, serviceFullPath string) (string, string, error) {
+	return CheckoutAndCompareGitHash(b.gitExecutor, gitURL, gitHash, currentGitHash, serviceFullPath)
+}
+
+func (b execBackend) CommitFiles(dir, commitMessage string, files ...string) error {
+	for _, file := range files {
+		if err := b.gitExecutor.Run(dir, "git", "add", file); err != nil {
+			return fmt.Errorf("failed to add file %s: %v", file, err)
+		}
+	}
+	if err := b.gitExecutor.Run(dir, "git", "commit", "-m", commitMessage); err != nil {
+		return fmt.Errorf("failed to commit changes: %v", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,402 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+const (
+	// defaultFetchDepth is the number of commits first fetched from a service repository.
+	defaultFetchDepth = 200
+	// maxShallowDepth is the depth above which the full history is fetched.
+	maxShallowDepth = 20000
+	// fullDepth deepens a shallow repository to its full history, like git fetch --unshallow.
+	fullDepth = 1<<31 - 1
+)
+
+var (
+	cacheNameSanitizer = regexp.MustCompile(`[^a-zA-Z0-9._-]+`)
+	// gitHashPattern matches full and abbreviated commit hashes, like git rev-parse.
+	gitHashPattern = regexp.MustCompile(`^[0-9a-fA-F]{4,40}$`)
+
+	// errHistoryTooShallow is returned when the fetched history does not reach a commit.
+	errHistoryTooShallow = errors.New("commit not found in the fetched history")
+)
+
+// GoGitBackend implements the git operations with go-git. It keeps a bare clone of every service
+// repository in CacheDir, which is fetched shallowly and deepened only when needed, and never
+// changes the working directory.
+type GoGitBackend struct {
+	CacheDir string
+	// Depth is the number of commits first fetched from the service repositories, 0 fetches the full history.
+	Depth int
+}
+
+func (b *GoGitBackend) CheckoutAndCompareGitHash(gitURL, gitHash, currentGitHash, serviceFullPath string) (string, string, error) {
+	if gitHash != "" && !gitHashPattern.MatchString(gitHash) {
+		return "", "", fmt.Errorf("invalid git hash %q: expected a full or abbreviated commit hash", gitHash)
+	}
+
+	repo, err := b.openCache(gitURL)
+	if err != nil {
+		return "", "", err
+	}
+
+	depth := b.Depth
+	for {
+		head, err := fetchServiceRepo(repo, depth)
+		if err != nil {
+			return "", "", fmt.Errorf("failed to fetch git repository %s: %v", gitURL, err)
+		}
+
+		promotionGitHash, commitLog, err := compareGitHash(repo, head, gitHash, currentGitHash, serviceFullPath)
+		if err == nil || !errors.Is(err, errHistoryTooShallow) || depth == 0 || depth == fullDepth {
+			return promotionGitHash, commitLog, err
+		}
+
+		depth *= 4
+		if depth > maxShallowDepth {
+			depth = fullDepth
+			fmt.Printf("Fetching the full history of %s\n", gitURL)
+		} else {
+			fmt.Printf("Fetching the last %d commits of %s\n", depth, gitURL)
+		}
+	}
+}
+
+// openCache opens the cached bare clone of gitURL, creating it if needed.
+func (b *GoGitBackend) openCache(gitURL string) (*gogit.Repository, error) {
+	name := cacheNameSanitizer.ReplaceAllString(strings.TrimSuffix(gitURL, ".git"), "_")
+	path := filepath.Join(b.CacheDir, name)
+
+	repo, err := gogit.PlainOpen(path)
+	if err == nil {
+		return repo, nil
+	}
+	if !errors.Is(err, gogit.ErrRepositoryNotExists) {
+		return nil, fmt.Errorf("failed to open cached repository %s: %v", path, err)
+	}
+
+	if err := os.MkdirAll(path, 0750); err != nil {
+		return nil, fmt.Errorf("failed to create cache directory: %v", err)
+	}
+	repo, err = gogit.PlainInit(path, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize cached repository %s: %v", path, err)
+	}
+	if _, err := repo.CreateRemote(&config.RemoteConfig{Name: "origin", URLs: []string{gitURL}}); err != nil {
+		return nil, fmt.Errorf("failed to configure cached repository %s: %v", path, err)
+	}
+
+	return repo, nil
+}
+
+// fetchServiceRepo fetches the branches of the origin remote up to depth commits, and returns the hash of its HEAD.
+func fetchServiceRepo(repo *gogit.Repository, depth int) (plumbing.Hash, error) {
+	remote, err := repo.Remote("origin")
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+
+	refs, err := remote.List(&gogit.ListOptions{})
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+	head, err := remoteHead(refs)
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+
+	err = remote.Fetch(&gogit.FetchOptions{
+		RefSpecs: []config.RefSpec{"+refs/heads/*:refs/remotes/origin/*"},
+		Depth:    depth,
+		Tags:     gogit.NoTags,
+	})
+	if err != nil && !errors.Is(err, gogit.NoErrAlreadyUpToDate) {
+		return plumbing.ZeroHash, err
+	}
+
+	return head, nil
+}
+
+// remoteHead returns the hash of HEAD among the references advertised by a remote.
+func remoteHead(refs []*plumbing.Reference) (plumbing.Hash, error) {
+	byName := map[plumbing.ReferenceName]*plumbing.Reference{}
+	for _, ref := range refs {
+		byName[ref.Name()] = ref
+	}
+
+	ref, ok := byName[plumbing.HEAD]
+	for i := 0; ok && ref.Type() == plumbing.SymbolicReference && i < 10; i++ {
+		ref, ok = byName[ref.Target()]
+	}
+	if !ok || ref.Type() != plumbing.HashReference {
+		return plumbing.ZeroHash, errors.New("remote HEAD not found")
+	}
+
+	return ref.Hash(), nil
+}
+
+// compareGitHash mirrors CheckoutAndCompareGitHash on a fetched repository.
+func compareGitHash(repo *gogit.Repository, head plumbing.Hash, gitHash, currentGitHash, serviceFullPath string) (string, string, error) {
+	if gitHash == "" {
+		fmt.Printf("No git hash provided. Using HEAD.\n")
+		promotionHash := head
+		if serviceFullPath != "" {
+			var err error
+			promotionHash, err = lastCommitChangingPath(repo, head, serviceFullPath)
+			if err != nil {
+				return "", "", err
+			}
+		}
+		gitHash = promotionHash.String()
+		fmt.Printf("The head githash is %s\n", gitHash)
+	} else {
+		var err error
+		gitHash, err = resolveGitHash(repo, gitHash)
+		if err != nil {
+			return "", "", err
+		}
+	}
+
+	if currentGitHash == gitHash {
+		return "", "", fmt.Errorf("git hash %s is already at HEAD", gitHash)
+	}
+
+	commitLog, err := commitLogBetween(repo, currentGitHash, gitHash, serviceFullPath)
+	if err != nil {
+		return "", "", err
+	}
+	return gitHash, commitLog, nil
+}
+
+// resolveGitHash returns the full hash of a full or abbreviated commit hash. An abbreviated hash
+// which is not in the fetched history is reported as errHistoryTooShallow, so that it is deepened.
+func resolveGitHash(repo *gogit.Repository, gitHash string) (string, error) {
+	if len(gitHash) == 40 {
+		return strings.ToLower(gitHash), nil
+	}
+
+	hash, err := repo.ResolveRevision(plumbing.Revision(strings.ToLower(gitHash)))
+	if errors.Is(err, plumbing.ErrReferenceNotFound) {
+		return "", fmt.Errorf("%w: %s", errHistoryTooShallow, gitHash)
+	} else if err != nil {
+		return "", fmt.Errorf("failed to resolve git hash %s: %v", gitHash, err)
+	}
+	return hash.String(), nil
+}
+
+// lastCommitChangingPath returns the last commit reachable from head which changes path.
+func lastCommitChangingPath(repo *gogit.Repository, head plumbing.Hash, path string) (plumbing.Hash, error) {
+	found := plumbing.ZeroHash
+	err := walkFirstParents(repo, head, func(commit *object.Commit) (bool, error) {
+		changed, err := changesPath(commit, path)
+		if err != nil || !changed {
+			return false, err
+		}
+		found = commit.Hash
+		return true, nil
+	})
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+	if found.IsZero() {
+		return plumbing.ZeroHash, fmt.Errorf("no commit changes %s", path)
+	}
+	return found, nil
+}
+
+// commitLogBetween returns the log of the non-merge commits reachable from to but not from from,
+// like git log --no-merges from..to, only keeping the commits changing path if set.
+func commitLogBetween(repo *gogit.Repository, from, to, path string) (string, error) {
+	fromHash, toHash := plumbing.NewHash(from), plumbing.NewHash(to)
+	if _, err := repo.CommitObject(fromHash); err != nil {
+		return "", fmt.Errorf("%w: %s", errHistoryTooShallow, from)
+	}
+
+	// The ancestors of from missing from a shallow history are beyond its boundary
+	excluded := map[plumbing.Hash]bool{}
+	queue := []plumbing.Hash{fromHash}
+	for len(queue) > 0 {
+		hash := queue[0]
+		queue = queue[1:]
+		if excluded[hash] {
+			continue
+		}
+		excluded[hash] = true
+		commit, err := repo.CommitObject(hash)
+		if errors.Is(err, plumbing.ErrObjectNotFound) {
+			continue
+		} else if err != nil {
+			return "", err
+		}
+		queue = append(queue, commit.ParentHashes...)
+	}
+
+	var commits []*object.Commit
+	visited := map[plumbing.Hash]bool{}
+	queue = []plumbing.Hash{toHash}
+	for len(queue) > 0 {
+		hash := queue[0]
+		queue = queue[1:]
+		if excluded[hash] || visited[hash] {
+			continue
+		}
+		visited[hash] = true
+		commit, err := repo.CommitObject(hash)
+		if errors.Is(err, plumbing.ErrObjectNotFound) {
+			return "", fmt.Errorf("%w: %s", errHistoryTooShallow, hash)
+		} else if err != nil {
+			return "", err
+		}
+		queue = append(queue, commit.ParentHashes...)
+
+		if commit.NumParents() > 1 {
+			continue
+		}
+		if path != "" {
+			changed, err := changesPath(commit, path)
+			if err != nil {
+				return "", err
+			}
+			if !changed {
+				continue
+			}
+		}
+		commits = append(commits, commit)
+	}
+
+	sort.SliceStable(commits, func(i, j int) bool {
+		return commits[i].Committer.When.After(commits[j].Committer.When)
+	})
+	entries := make([]string, 0, len(commits))
+	for _, commit := range commits {
+		entries = append(entries, commit.String())
+	}
+	return strings.Join(entries, "\n"), nil
+}
+
+// walkFirstParents calls visit on start and its first parents until visit returns true, or the
+// root commit is reached. Missing commits are reported as errHistoryTooShallow.
+func walkFirstParents(repo *gogit.Repository, start plumbing.Hash, visit func(*object.Commit) (bool, error)) error {
+	hash := start
+	for {
+		commit, err := repo.CommitObject(hash)
+		if errors.Is(err, plumbing.ErrObjectNotFound) {
+			return fmt.Errorf("%w: %s", errHistoryTooShallow, hash)
+		} else if err != nil {
+			return err
+		}
+
+		done, err := visit(commit)
+		if err != nil || done {
+			return err
+		}
+		if commit.NumParents() == 0 {
+			return nil
+		}
+		hash = commit.ParentHashes[0]
+	}
+}
+
+// changesPath reports whether commit changes path compared to its first parent.
+func changesPath(commit *object.Commit, path string) (bool, error) {
+	current, err := pathHash(commit, path)
+	if err != nil {
+		return false, err
+	}
+	if commit.NumParents() == 0 {
+		return !current.IsZero(), nil
+	}
+
+	parent, err := commit.Parent(0)
+	if errors.Is(err, plumbing.ErrObjectNotFound) {
+		return false, fmt.Errorf("%w: %s", errHistoryTooShallow, commit.ParentHashes[0])
+	} else if err != nil {
+		return false, err
+	}
+	previous, err := pathHash(parent, path)
+	if err != nil {
+		return false, err
+	}
+
+	return current != previous, nil
+}
+
+// pathHash returns the hash of the file or directory at path in commit, or the zero hash if it does not exist.
+func pathHash(commit *object.Commit, path string) (plumbing.Hash, error) {
+	tree, err := commit.Tree()
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+	entry, err := tree.FindEntry(strings.Trim(path, "/"))
+	if errors.Is(err, object.ErrEntryNotFound) || errors.Is(err, object.ErrDirectoryNotFound) {
+		return plumbing.ZeroHash, nil
+	} else if err != nil {
+		return plumbing.ZeroHash, err
+	}
+	return entry.Hash, nil
+}
+
+// CommitFiles adds the files to the index of the repository in dir and commits them, with the
+// author of the git configuration.
+func (b *GoGitBackend) CommitFiles(dir, commitMessage string, files ...string) error {
+	repo, err := gogit.PlainOpenWithOptions(dir, &gogit.PlainOpenOptions{DetectDotGit: true})
+	if err != nil {
+		return fmt.Errorf("failed to open git repository %s: %v", dir, err)
+	}
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return fmt.Errorf("failed to open worktree of %s: %v", dir, err)
+	}
+
+	for _, file := range files {
+		path, err := worktreePath(worktree.Filesystem.Root(), file)
+		if err == nil {
+			// Skip the status of the whole worktree, which is slow on app-interface
+			err = worktree.AddWithOptions(&gogit.AddOptions{Path: path, SkipStatus: true})
+		}
+		if err != nil {
+			return fmt.Errorf("failed to add file %s: %v", file, err)
+		}
+	}
+
+	if _, err := worktree.Commit(commitMessage, &gogit.CommitOptions{}); err != nil {
+		return fmt.Errorf("failed to commit changes: %v", err)
+	}
+
+	return nil
+}
+
+// worktreePath returns the path of file relative to the worktree root.
+func worktreePath(root, file string) (string, error) {
+	absFile, err := filepath.Abs(file)
+	if err != nil {
+		return "", err
+	}
+	if resolved, err := filepath.EvalSymlinks(root); err == nil {
+		root = resolved
+	}
+	if resolved, err := filepath.EvalSymlinks(absFile); err == nil {
+		absFile = resolved
+	}
+
+	path, err := filepath.Rel(root, absFile)
+	if err != nil {
+		return "", err
+	}
+	if strings.HasPrefix(path, "..") {
+		return "", fmt.Errorf("%s is outside of the repository %s", file, root)
+	}
+	return filepath.ToSlash(path), nil
+}
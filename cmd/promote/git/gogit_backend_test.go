@@ -0,0 +1,207 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testSignature = &object.Signature{Name: "Test", Email: "test@example.com"}
+
+// commitFile writes content to path in the worktree of repo and commits it.
+func commitFile(t *testing.T, repo *gogit.Repository, path, content, message string, when time.Time) plumbing.Hash {
+	t.Helper()
+	worktree, err := repo.Worktree()
+	require.NoError(t, err)
+
+	fullPath := filepath.Join(worktree.Filesystem.Root(), path)
+	require.NoError(t, os.MkdirAll(filepath.Dir(fullPath), 0o755))
+	require.NoError(t, os.WriteFile(fullPath, []byte(content), 0o600))
+	_, err = worktree.Add(path)
+	require.NoError(t, err)
+
+	signature := *testSignature
+	signature.When = when
+	hash, err := worktree.Commit(message, &gogit.CommitOptions{Author: &signature})
+	require.NoError(t, err)
+	return hash
+}
+
+func TestGoGitBackendCheckoutAndCompareGitHash(t *testing.T) {
+	sourceDir := t.TempDir()
+	source, err := gogit.PlainInit(sourceDir, false)
+	require.NoError(t, err)
+
+	now := time.Now()
+	first := commitFile(t, source, "README.md", "v1", "first commit", now.Add(-4*time.Hour))
+	second := commitFile(t, source, "deploy/operator.yaml", "v1", "change the operator", now.Add(-3*time.Hour))
+	third := commitFile(t, source, "README.md", "v2", "update the readme", now.Add(-2*time.Hour))
+
+	backend := &GoGitBackend{CacheDir: t.TempDir(), Depth: 1}
+
+	t.Run("head", func(t *testing.T) {
+		gitHash, commitLog, err := backend.CheckoutAndCompareGitHash(sourceDir, "", first.String(), "")
+		require.NoError(t, err)
+		assert.Equal(t, third.String(), gitHash)
+		assert.Contains(t, commitLog, "commit "+third.String())
+		assert.Contains(t, commitLog, "commit "+second.String())
+		assert.NotContains(t, commitLog, "first commit")
+		assert.Less(t, strings.Index(commitLog, third.String()), strings.Index(commitLog, second.String()))
+	})
+
+	t.Run("path_filter", func(t *testing.T) {
+		gitHash, commitLog, err := backend.CheckoutAndCompareGitHash(sourceDir, "", first.String(), "deploy")
+		require.NoError(t, err)
+		assert.Equal(t, second.String(), gitHash)
+		assert.Contains(t, commitLog, "change the operator")
+		assert.NotContains(t, commitLog, "update the readme")
+	})
+
+	t.Run("explicit_hash", func(t *testing.T) {
+		gitHash, commitLog, err := backend.CheckoutAndCompareGitHash(sourceDir, second.String(), first.String(), "")
+		require.NoError(t, err)
+		assert.Equal(t, second.String(), gitHash)
+		assert.NotContains(t, commitLog, "update the readme")
+	})
+
+	t.Run("short_hash", func(t *testing.T) {
+		gitHash, commitLog, err := backend.CheckoutAndCompareGitHash(sourceDir, second.String()[:7], first.String(), "")
+		require.NoError(t, err)
+		assert.Equal(t, second.String(), gitHash)
+		assert.Contains(t, commitLog, "change the operator")
+	})
+
+	t.Run("malformed_hash", func(t *testing.T) {
+		_, _, err := backend.CheckoutAndCompareGitHash(sourceDir, "not-a-hash", first.String(), "")
+		assert.ErrorContains(t, err, `invalid git hash "not-a-hash"`)
+	})
+
+	t.Run("already_at_head", func(t *testing.T) {
+		_, _, err := backend.CheckoutAndCompareGitHash(sourceDir, "", third.String(), "")
+		assert.ErrorContains(t, err, "is already at HEAD")
+	})
+
+	t.Run("reuses_cache", func(t *testing.T) {
+		fourth := commitFile(t, source, "README.md", "v3", "another readme update", now.Add(-time.Hour))
+
+		gitHash, commitLog, err := backend.CheckoutAndCompareGitHash(sourceDir, "", third.String(), "")
+		require.NoError(t, err)
+		assert.Equal(t, fourth.String(), gitHash)
+		assert.Contains(t, commitLog, "another readme update")
+		assert.NotContains(t, commitLog, "change the operator")
+
+		entries, err := os.ReadDir(backend.CacheDir)
+		require.NoError(t, err)
+		assert.Len(t, entries, 1)
+	})
+
+	t.Run("unknown_hash", func(t *testing.T) {
+		_, _, err := backend.CheckoutAndCompareGitHash(sourceDir, "0123456789abcdef0123456789abcdef01234567", first.String(), "")
+		assert.ErrorContains(t, err, "commit not found in the fetched history")
+	})
+}
+
+func TestCommitLogBetweenIncludesMergedCommits(t *testing.T) {
+	dir := t.TempDir()
+	repo, err := gogit.PlainInit(dir, false)
+	require.NoError(t, err)
+
+	now := time.Now()
+	base := commitFile(t, repo, "README.md", "v1", "base", now.Add(-3*time.Hour))
+	feature := commitFile(t, repo, "feature.txt", "v1", "feature work", now.Add(-2*time.Hour))
+
+	// Merge commit with the base as first parent and the feature commit as second parent
+	baseCommit, err := repo.CommitObject(base)
+	require.NoError(t, err)
+	merge := &object.Commit{
+		Author:       *testSignature,
+		Committer:    *testSignature,
+		Message:      "Merge feature",
+		TreeHash:     mustTreeHash(t, repo, feature),
+		ParentHashes: []plumbing.Hash{baseCommit.Hash, feature},
+	}
+	merge.Author.When, merge.Committer.When = now.Add(-time.Hour), now.Add(-time.Hour)
+	obj := repo.Storer.NewEncodedObject()
+	require.NoError(t, merge.Encode(obj))
+	mergeHash, err := repo.Storer.SetEncodedObject(obj)
+	require.NoError(t, err)
+
+	commitLog, err := commitLogBetween(repo, base.String(), mergeHash.String(), "")
+	require.NoError(t, err)
+	assert.Contains(t, commitLog, "feature work")
+	assert.NotContains(t, commitLog, "Merge feature")
+	assert.NotContains(t, commitLog, "    base")
+}
+
+func mustTreeHash(t *testing.T, repo *gogit.Repository, hash plumbing.Hash) plumbing.Hash {
+	t.Helper()
+	commit, err := repo.CommitObject(hash)
+	require.NoError(t, err)
+	return commit.TreeHash
+}
+
+func TestGoGitBackendCommitFiles(t *testing.T) {
+	dir := t.TempDir()
+	repo, err := gogit.PlainInit(dir, false)
+	require.NoError(t, err)
+	cfg, err := repo.Config()
+	require.NoError(t, err)
+	cfg.User.Name, cfg.User.Email = testSignature.Name, testSignature.Email
+	require.NoError(t, repo.SetConfig(cfg))
+	commitFile(t, repo, "data/services/saas.yaml", "ref: old", "initial", time.Now())
+
+	saasFile := filepath.Join(dir, "data", "services", "saas.yaml")
+	require.NoError(t, os.WriteFile(saasFile, []byte("ref: new"), 0o600))
+
+	backend := &GoGitBackend{}
+	require.NoError(t, backend.CommitFiles(dir, "Promote saas", saasFile))
+
+	head, err := repo.Head()
+	require.NoError(t, err)
+	commit, err := repo.CommitObject(head.Hash())
+	require.NoError(t, err)
+	assert.Equal(t, "Promote saas", commit.Message)
+	assert.Equal(t, testSignature.Email, commit.Author.Email)
+	file, err := commit.File("data/services/saas.yaml")
+	require.NoError(t, err)
+	content, err := file.Contents()
+	require.NoError(t, err)
+	assert.Equal(t, "ref: new", content)
+
+	err = backend.CommitFiles(dir, "outside", filepath.Join(t.TempDir(), "other.yaml"))
+	assert.ErrorContains(t, err, "failed to add file")
+}
+
+func TestRemoteHead(t *testing.T) {
+	hash := plumbing.NewHash("0123456789abcdef0123456789abcdef01234567")
+	head, err := remoteHead([]*plumbing.Reference{
+		plumbing.NewSymbolicReference(plumbing.HEAD, "refs/heads/main"),
+		plumbing.NewHashReference("refs/heads/main", hash),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, hash, head)
+
+	_, err = remoteHead([]*plumbing.Reference{plumbing.NewHashReference("refs/heads/main", hash)})
+	assert.Error(t, err)
+}
+
+func TestNewBackend(t *testing.T) {
+	backend, err := NewBackend("", nil)
+	require.NoError(t, err)
+	assert.IsType(t, execBackend{}, backend)
+
+	backend, err = NewBackend(BackendGoGit, nil)
+	require.NoError(t, err)
+	assert.IsType(t, &GoGitBackend{}, backend)
+
+	_, err = NewBackend("libgit2", nil)
+	assert.ErrorContains(t, err, "unknown git backend")
+}
@@ -0,0 +1,141 @@
+package git
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	kyaml "sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// maxHistoryCommits is the number of app-interface commits searched for previous refs.
+const maxHistoryCommits = 200
+
+// RefRevision is a ref of a saas file and the app-interface commit which introduced it.
+type RefRevision struct {
+	Ref    string
+	Commit string
+}
+
+// RefHistory returns the last count distinct refs of the saas file on the master branch of
+// app-interface, most recent first. extract returns the ref of a revision of the saas file.
+func (a *AppInterface) RefHistory(saasFile string, count int, extract func([]byte) (string, error)) ([]RefRevision, error) {
+	relPath := saasFile
+	if filepath.IsAbs(saasFile) {
+		var err error
+		relPath, err = filepath.Rel(a.GitDirectory, saasFile)
+		if err != nil {
+			return nil, fmt.Errorf("saas file %s is not in app-interface: %v", saasFile, err)
+		}
+	}
+	relPath = filepath.ToSlash(relPath)
+
+	output, err := a.GitExecutor.Output(a.GitDirectory, "git", "log", "--format=%H", "-n", strconv.Itoa(maxHistoryCommits), "master", "--", relPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the history of %s: %v", relPath, err)
+	}
+
+	var history []RefRevision
+	for _, commit := range strings.Fields(output) {
+		content, err := a.GitExecutor.Output(a.GitDirectory, "git", "show", commit+":"+relPath)
+		if err != nil {
+			// The saas file was moved or created by this commit
+			break
+		}
+		ref, err := extract([]byte(content))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the ref of %s at commit %s: %v", relPath, commit, err)
+		}
+		if ref == "" {
+			return nil, fmt.Errorf("%s has no ref at commit %s", relPath, commit)
+		}
+
+		last := len(history) - 1
+		if last >= 0 && history[last].Ref == ref {
+			// The ref was introduced by an older commit
+			history[last].Commit = commit
+			continue
+		}
+		if len(history) == count {
+			break
+		}
+		history = append(history, RefRevision{Ref: ref, Commit: commit})
+	}
+
+	return history, nil
+}
+
+// RevertSaasFileRef replaces faultyRef with restoredRef in the targets of the saas file, either as
+// their ref or as their package tag. The rest of the saas file is left untouched.
+func (a *AppInterface) RevertSaasFileRef(saasFile, faultyRef, restoredRef string) error {
+	if faultyRef == "" || restoredRef == "" {
+		return fmt.Errorf("cannot replace ref %q with %q in %s, both refs must be set", faultyRef, restoredRef, saasFile)
+	}
+
+	fileContent, err := os.ReadFile(saasFile)
+	if err != nil {
+		return fmt.Errorf("failed to read file %s: %v", saasFile, err)
+	}
+
+	newContent, replaced, err := replaceTargetRefs(string(fileContent), faultyRef, restoredRef)
+	if err != nil {
+		return fmt.Errorf("error modifying %s: %v", saasFile, err)
+	}
+	if replaced == 0 {
+		return fmt.Errorf("%s is not referenced by the targets of %s", faultyRef, saasFile)
+	}
+
+	if err := os.WriteFile(saasFile, []byte(newContent), 0600); err != nil {
+		return fmt.Errorf("failed to write to file %s: %v", saasFile, err)
+	}
+	return nil
+}
+
+// targetRefFields are the fields of a saas target holding a promoted ref.
+var targetRefFields = [][]string{{"ref"}, {"parameters", "PACKAGE_TAG"}}
+
+// replaceTargetRefs replaces faultyRef with restoredRef in the ref fields of every target of the saas
+// file and returns the updated yaml with the number of fields replaced.
+func replaceTargetRefs(fileContent, faultyRef, restoredRef string) (string, int, error) {
+	node, err := kyaml.Parse(fileContent)
+	if err != nil {
+		return "", 0, fmt.Errorf("error parsing saas YAML: %v", err)
+	}
+	rts, err := kyaml.Lookup("resourceTemplates").Filter(node)
+	if err != nil {
+		return "", 0, fmt.Errorf("error querying resource templates: %v", err)
+	}
+	if rts == nil {
+		return "", 0, nil
+	}
+
+	replaced := 0
+	for i := range len(rts.Content()) {
+		targets, err := kyaml.Lookup("resourceTemplates", strconv.Itoa(i), "targets").Filter(node)
+		if err != nil {
+			return "", 0, fmt.Errorf("error querying saas YAML: %v", err)
+		}
+		if targets == nil {
+			continue
+		}
+		err = targets.VisitElements(func(target *kyaml.RNode) error {
+			for _, path := range targetRefFields {
+				field, err := target.Pipe(kyaml.Lookup(path...))
+				if err != nil {
+					return err
+				}
+				if field != nil && field.YNode().Value == faultyRef {
+					field.YNode().Value = restoredRef
+					replaced++
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return "", 0, fmt.Errorf("error querying saas YAML: %v", err)
+		}
+	}
+	return node.MustString(), replaced, nil
+}
@@ -0,0 +1,117 @@
+package git
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRefHistory(t *testing.T) {
+	dir := "/app-interface"
+	saasFile := filepath.Join(dir, "data", "saas.yaml")
+	extract := func(data []byte) (string, error) {
+		if strings.HasPrefix(string(data), "invalid") {
+			return "", errors.New("invalid saas file")
+		}
+		return strings.TrimPrefix(string(data), "ref: "), nil
+	}
+
+	newMock := func(revisions map[string]string, order ...string) *MockExec {
+		m := new(MockExec)
+		m.On("Output", dir, "git", []string{"log", "--format=%H", "-n", "200", "master", "--", "data/saas.yaml"}).Return(strings.Join(order, "\n")+"\n", nil)
+		for commit, content := range revisions {
+			if content == "" {
+				m.On("Output", dir, "git", []string{"show", commit + ":data/saas.yaml"}).Return("", errors.New("path does not exist"))
+				continue
+			}
+			m.On("Output", dir, "git", []string{"show", commit + ":data/saas.yaml"}).Return(content, nil)
+		}
+		return m
+	}
+
+	t.Run("distinct_refs_with_introducing_commits", func(t *testing.T) {
+		m := newMock(map[string]string{"c5": "ref: bbb", "c4": "ref: bbb", "c3": "ref: aaa", "c2": "ref: zzz", "c1": "ref: yyy"}, "c5", "c4", "c3", "c2", "c1")
+		a := AppInterface{GitDirectory: dir, GitExecutor: m}
+
+		history, err := a.RefHistory(saasFile, 3, extract)
+		require.NoError(t, err)
+		assert.Equal(t, []RefRevision{{Ref: "bbb", Commit: "c4"}, {Ref: "aaa", Commit: "c3"}, {Ref: "zzz", Commit: "c2"}}, history)
+	})
+
+	t.Run("stops_when_the_file_did_not_exist", func(t *testing.T) {
+		m := newMock(map[string]string{"c2": "ref: bbb", "c1": ""}, "c2", "c1")
+		a := AppInterface{GitDirectory: dir, GitExecutor: m}
+
+		history, err := a.RefHistory(saasFile, 3, extract)
+		require.NoError(t, err)
+		assert.Equal(t, []RefRevision{{Ref: "bbb", Commit: "c2"}}, history)
+	})
+
+	t.Run("invalid_revision", func(t *testing.T) {
+		m := newMock(map[string]string{"c2": "ref: bbb", "c1": "invalid"}, "c2", "c1")
+		a := AppInterface{GitDirectory: dir, GitExecutor: m}
+
+		_, err := a.RefHistory(saasFile, 3, extract)
+		assert.ErrorContains(t, err, "at commit c1")
+	})
+
+	t.Run("empty_ref", func(t *testing.T) {
+		m := newMock(map[string]string{"c2": "ref: bbb", "c1": "ref: "}, "c2", "c1")
+		a := AppInterface{GitDirectory: dir, GitExecutor: m}
+
+		_, err := a.RefHistory(saasFile, 3, extract)
+		assert.ErrorContains(t, err, "data/saas.yaml has no ref at commit c1")
+	})
+
+	t.Run("git_log_fails", func(t *testing.T) {
+		m := new(MockExec)
+		m.On("Output", dir, "git", []string{"log", "--format=%H", "-n", "200", "master", "--", "data/saas.yaml"}).Return("", errors.New("not a git repository"))
+		a := AppInterface{GitDirectory: dir, GitExecutor: m}
+
+		_, err := a.RefHistory(saasFile, 3, extract)
+		assert.ErrorContains(t, err, "failed to read the history")
+	})
+}
+
+func TestRevertSaasFileRef(t *testing.T) {
+	saasFile := filepath.Join(t.TempDir(), "saas.yaml")
+	original := `name: saas-bad-service
+resourceTemplates:
+- name: bad-templates
+  targets:
+  - name: production-bad
+    ref: bad
+  - name: stage
+    ref: good
+- name: package
+  targets:
+  - name: production
+    ref: main
+    parameters:
+      PACKAGE_TAG: bad
+`
+	require.NoError(t, os.WriteFile(saasFile, []byte(original), 0o600))
+
+	a := AppInterface{}
+	require.NoError(t, a.RevertSaasFileRef(saasFile, "bad", "restored"))
+
+	// Only the refs and package tags of the targets are replaced, not the names
+	content, err := os.ReadFile(saasFile)
+	require.NoError(t, err)
+	expected := strings.NewReplacer("ref: bad", "ref: restored", "PACKAGE_TAG: bad", "PACKAGE_TAG: restored").Replace(original)
+	assert.Equal(t, expected, string(content))
+
+	assert.ErrorContains(t, a.RevertSaasFileRef(saasFile, "bad", "restored"), "bad is not referenced")
+
+	// Empty refs would delete the restored ref, or insert it between every byte of the saas file
+	assert.ErrorContains(t, a.RevertSaasFileRef(saasFile, "restored", ""), "both refs must be set")
+	assert.ErrorContains(t, a.RevertSaasFileRef(saasFile, "", "restored"), "both refs must be set")
+	content, err = os.ReadFile(saasFile)
+	require.NoError(t, err)
+	assert.Equal(t, expected, string(content))
+}
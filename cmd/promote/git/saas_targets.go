@@ -0,0 +1,126 @@
+package git
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/openshift/osdctl/cmd/promote/iexec"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	EnvironmentIntegration = "integration"
+	EnvironmentStage       = "stage"
+	EnvironmentProduction  = "production"
+	EnvironmentUnknown     = "unknown"
+)
+
+// SaasTarget is a deployment target of a saas file and the ref it is pinned to.
+type SaasTarget struct {
+	ResourceTemplate string `json:"resourceTemplate"`
+	Repo             string `json:"repo"`
+	Name             string `json:"name"`
+	NamespaceRef     string `json:"namespaceRef"`
+	Environment      string `json:"environment"`
+	Ref              string `json:"ref"`
+}
+
+// ListSaasTargets returns every target of every resource template of a saas file.
+func ListSaasTargets(saasYamlFile []byte) ([]SaasTarget, error) {
+	var service Service
+	if err := yaml.Unmarshal(saasYamlFile, &service); err != nil {
+		return nil, fmt.Errorf("cannot unmarshal saas file: %v", err)
+	}
+
+	var targets []SaasTarget
+	for _, resourceTemplate := range service.ResourceTemplates {
+		for _, target := range resourceTemplate.Targets {
+			saasTarget := SaasTarget{
+				ResourceTemplate: resourceTemplate.Name,
+				Repo:             resourceTemplate.URL,
+				Name:             target.Name,
+				NamespaceRef:     target.Namespace["$ref"],
+				Ref:              target.Ref,
+			}
+			saasTarget.Environment = TargetEnvironment(saasTarget.Name, saasTarget.NamespaceRef)
+			targets = append(targets, saasTarget)
+		}
+	}
+
+	return targets, nil
+}
+
+// TargetEnvironment guesses the environment of a target from its name and namespace, following
+// the app-interface naming conventions, i.e. hivei01ue1 (integration), hives02ue1 (stage) or hivep01ue1 (production).
+func TargetEnvironment(targetName, namespaceRef string) string {
+	id := strings.ToLower(targetName + " " + namespaceRef)
+	switch {
+	case strings.Contains(id, "hivei") || strings.Contains(id, "integration") || containsToken(id, "int"):
+		return EnvironmentIntegration
+	case strings.Contains(id, "hives") || strings.Contains(id, "stage") || containsToken(id, "stg"):
+		return EnvironmentStage
+	case strings.Contains(id, prodHiveStr) || strings.Contains(id, "prod"):
+		return EnvironmentProduction
+	default:
+		return EnvironmentUnknown
+	}
+}
+
+// containsToken reports whether s contains token delimited by non-alphanumeric characters.
+func containsToken(s, token string) bool {
+	fields := strings.FieldsFunc(s, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9')
+	})
+	for _, field := range fields {
+		if field == token {
+			return true
+		}
+	}
+	return false
+}
+
+// CloneServiceRepo clones the service repository into a temporary directory, without changing
+// the working directory. The returned function removes the clone.
+func CloneServiceRepo(gitExecutor iexec.IExec, gitURL string) (string, func(), error) {
+	tempDir, err := os.MkdirTemp("", "osdctl-promote-")
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to create temporary directory: %v", err)
+	}
+	cleanup := func() { os.RemoveAll(tempDir) }
+
+	if err := gitExecutor.Run(tempDir, "git", "clone", "--quiet", gitURL, "source-dir"); err != nil {
+		cleanup()
+		return "", nil, fmt.Errorf("failed to clone git repository %s: %v", gitURL, err)
+	}
+
+	return filepath.Join(tempDir, "source-dir"), cleanup, nil
+}
+
+// ResolveRef returns the commit hash of ref in the repository, looking up remote branches too.
+func ResolveRef(gitExecutor iexec.IExec, repoDir, ref string) (string, error) {
+	var lastErr error
+	for _, candidate := range []string{ref, "origin/" + ref} {
+		output, err := gitExecutor.Output(repoDir, "git", "rev-parse", "--verify", "--quiet", candidate+"^{commit}")
+		if err == nil && strings.TrimSpace(output) != "" {
+			return strings.TrimSpace(output), nil
+		}
+		lastErr = err
+	}
+	return "", fmt.Errorf("ref %s not found: %v", ref, lastErr)
+}
+
+// CountCommitsBehind returns the number of commits reachable from head but not from ref.
+func CountCommitsBehind(gitExecutor iexec.IExec, repoDir, ref, head string) (int, error) {
+	output, err := gitExecutor.Output(repoDir, "git", "rev-list", "--count", ref+".."+head)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count commits between %s and %s: %v", ref, head, err)
+	}
+	count, err := strconv.Atoi(strings.TrimSpace(output))
+	if err != nil {
+		return 0, fmt.Errorf("unexpected output of git rev-list: %q", output)
+	}
+	return count, nil
+}
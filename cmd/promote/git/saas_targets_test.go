@@ -0,0 +1,102 @@
+package git
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListSaasTargets(t *testing.T) {
+	saasFile := `
+name: saas-example
+resourceTemplates:
+  - name: example
+    url: https://github.com/openshift/example
+    targets:
+      - name: example-int
+        namespace:
+          $ref: /services/osd-operators/namespaces/hivei01ue1/example.yml
+        ref: master
+      - name: example-stage
+        namespace:
+          $ref: /services/osd-operators/namespaces/hives02ue1/example.yml
+        ref: 0123456789abcdef0123456789abcdef01234567
+      - name: example-prod
+        namespace:
+          $ref: /services/osd-operators/namespaces/hivep01ue1/example.yml
+        ref: 0123456789abcdef0123456789abcdef01234567
+`
+	targets, err := ListSaasTargets([]byte(saasFile))
+	require.NoError(t, err)
+	require.Len(t, targets, 3)
+
+	assert.Equal(t, SaasTarget{
+		ResourceTemplate: "example",
+		Repo:             "https://github.com/openshift/example",
+		Name:             "example-int",
+		NamespaceRef:     "/services/osd-operators/namespaces/hivei01ue1/example.yml",
+		Environment:      EnvironmentIntegration,
+		Ref:              "master",
+	}, targets[0])
+	assert.Equal(t, EnvironmentStage, targets[1].Environment)
+	assert.Equal(t, EnvironmentProduction, targets[2].Environment)
+
+	_, err = ListSaasTargets([]byte("resourceTemplates: {"))
+	assert.Error(t, err)
+}
+
+func TestTargetEnvironment(t *testing.T) {
+	tests := []struct {
+		targetName   string
+		namespaceRef string
+		expected     string
+	}{
+		{"", "/services/osd-operators/namespaces/hivei01ue1/foo.yml", EnvironmentIntegration},
+		{"foo-int", "", EnvironmentIntegration},
+		{"hypershift-integration", "", EnvironmentIntegration},
+		{"", "/services/osd-operators/namespaces/hives02ue1/foo.yml", EnvironmentStage},
+		{"foo-stg", "", EnvironmentStage},
+		{"", "/services/osd-operators/namespaces/hivep04ew2/foo.yml", EnvironmentProduction},
+		{"foo-prod-canary", "", EnvironmentProduction},
+		{"internal", "", EnvironmentUnknown},
+		{"foo", "/services/foo/namespaces/bar.yml", EnvironmentUnknown},
+	}
+	for _, test := range tests {
+		assert.Equal(t, test.expected, TargetEnvironment(test.targetName, test.namespaceRef), "%s %s", test.targetName, test.namespaceRef)
+	}
+}
+
+func TestResolveRef(t *testing.T) {
+	mockExec := new(MockExec)
+	mockExec.On("Output", "repo", "git", []string{"rev-parse", "--verify", "--quiet", "release^{commit}"}).Return("", errors.New("exit status 1"))
+	mockExec.On("Output", "repo", "git", []string{"rev-parse", "--verify", "--quiet", "origin/release^{commit}"}).Return("abc123\n", nil)
+	mockExec.On("Output", "repo", "git", []string{"rev-parse", "--verify", "--quiet", "missing^{commit}"}).Return("", errors.New("exit status 1"))
+	mockExec.On("Output", "repo", "git", []string{"rev-parse", "--verify", "--quiet", "origin/missing^{commit}"}).Return("", errors.New("exit status 1"))
+
+	hash, err := ResolveRef(mockExec, "repo", "release")
+	require.NoError(t, err)
+	assert.Equal(t, "abc123", hash)
+
+	_, err = ResolveRef(mockExec, "repo", "missing")
+	assert.ErrorContains(t, err, "ref missing not found")
+}
+
+func TestCountCommitsBehind(t *testing.T) {
+	mockExec := new(MockExec)
+	mockExec.On("Output", "repo", "git", []string{"rev-list", "--count", "abc..def"}).Return("12\n", nil)
+	mockExec.On("Output", "repo", "git", []string{"rev-list", "--count", "abc..bad"}).Return("fatal", nil)
+	mockExec.On("Output", "repo", "git", mock.Anything).Return("", errors.New("exit status 128"))
+
+	count, err := CountCommitsBehind(mockExec, "repo", "abc", "def")
+	require.NoError(t, err)
+	assert.Equal(t, 12, count)
+
+	_, err = CountCommitsBehind(mockExec, "repo", "abc", "bad")
+	assert.ErrorContains(t, err, "unexpected output")
+
+	_, err = CountCommitsBehind(mockExec, "repo", "abc", "unknown")
+	assert.ErrorContains(t, err, "failed to count commits")
+}
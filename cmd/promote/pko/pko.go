@@ -20,7 +20,10 @@ func NewCmdPKO() *cobra.Command {
 		DisableAutoGenTag: true,
 		Example: `
  # Promote a package-operator service
- osdctl promote package --serviceName <serviceName> --gitHash <git-hash>`,
+ osdctl promote package --serviceName <serviceName> --tag <package-tag>
+
+ # Roll a package-operator service back to its previously promoted package tag
+ osdctl promote package --serviceName <serviceName> --rollback`,
 		Run: func(cmd *cobra.Command, args []string) {
 			// Set default directory if not provided
 			if ops.appInterfaceCheckoutDir == "" {
@@ -29,6 +32,10 @@ func NewCmdPKO() *cobra.Command {
 
 			cmdutil.CheckErr(ops.ValidatePKOOptions())
 			appInterface := git.BootstrapOsdCtlForAppInterfaceAndServicePromotions(ops.appInterfaceCheckoutDir, iexec.Exec{})
+			if ops.rollback {
+				cmdutil.CheckErr(RollbackPackage(appInterface, ops.serviceName, ops.hcp))
+				return
+			}
 			cmdutil.CheckErr(PromotePackage(appInterface, ops.serviceName, ops.packageTag, ops.hcp))
 		},
 	}
@@ -37,6 +44,7 @@ func NewCmdPKO() *cobra.Command {
 	pkoCmd.Flags().StringVarP(&ops.packageTag, "tag", "t", "", "Package tag being promoted to")
 	pkoCmd.Flags().StringVarP(&ops.appInterfaceCheckoutDir, "appInterfaceDir", "", "", "location of app-interface checkout. Falls back to current working directory")
 	pkoCmd.Flags().BoolVar(&ops.hcp, "hcp", false, "The service being promoted conforms to the HyperShift progressive delivery definition")
+	pkoCmd.Flags().BoolVar(&ops.rollback, "rollback", false, "Roll the service back to the package tag promoted before the current one, found in the app-interface history")
 
 	return pkoCmd
 }
@@ -47,12 +55,19 @@ type pkoOptions struct {
 	packageTag              string
 	appInterfaceCheckoutDir string
 	hcp                     bool
+	rollback                bool
 }
 
 func (p pkoOptions) ValidatePKOOptions() error {
 	if p.serviceName == "" {
 		return fmt.Errorf("the service name must be specified with --serviceName/-s")
 	}
+	if p.rollback {
+		if p.packageTag != "" {
+			return fmt.Errorf("'--tag' cannot be used with '--rollback'")
+		}
+		return nil
+	}
 	if p.packageTag == "" {
 		return fmt.Errorf("a new package tag must be provided with '--tag' or '-t'")
 	}
@@ -79,6 +94,7 @@ func PromotePackage(appInterface git.AppInterface, serviceName string, packageTa
 	if currentTag == packageTag {
 		return fmt.Errorf("current hash is already at '%s'. Nothing to do", packageTag)
 	}
+	saas.WarnIfBadRef(serviceName, packageTag)
 	branchName := fmt.Sprintf("promote-%s-package-%s", serviceName, packageTag)
 	err = appInterface.UpdatePackageTag(saasFile, currentTag, packageTag, branchName)
 	if err != nil {
@@ -98,6 +114,29 @@ func PromotePackage(appInterface git.AppInterface, serviceName string, packageTa
 	return nil
 }
 
+// RollbackPackage rolls the package tag of a service back to the previously promoted one.
+func RollbackPackage(appInterface git.AppInterface, serviceName string, hcp bool) error {
+	services, err := saas.GetServiceNames(appInterface, saas.OSDSaasDir, saas.BPSaasDir, saas.CADSaasDir)
+	if err != nil {
+		return err
+	}
+	serviceName, err = saas.ValidateServiceName(services, serviceName)
+	if err != nil {
+		return err
+	}
+	saasFile, err := saas.GetSaasDir(serviceName, !hcp, hcp)
+	if err != nil {
+		return err
+	}
+
+	return saas.RollbackSaasFile(appInterface, saas.RollbackTarget{
+		ServiceName: serviceName,
+		SaasFile:    saasFile,
+		RefKind:     "package tag",
+		Extract:     git.GetPackageTagFromSaasFile,
+	})
+}
+
 func updatePackageHash(gitHash, saasFile string) error {
 	return nil
 }
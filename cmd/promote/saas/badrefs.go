@@ -0,0 +1,105 @@
+package saas
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// BadRef is a ref of a service marked as bad, i.e. after being rolled back.
+type BadRef struct {
+	Service  string    `json:"service"`
+	Ref      string    `json:"ref"`
+	MarkedAt time.Time `json:"markedAt"`
+	Reason   string    `json:"reason"`
+}
+
+// BadRefs is the list of the refs marked as bad, stored in the osdctl cache directory.
+type BadRefs struct {
+	Refs []BadRef `json:"refs"`
+
+	path string
+}
+
+// DefaultBadRefsPath returns the location of the bad refs in the osdctl cache directory.
+// The osdctl config directory cannot be used, its osdctl entry is the config file itself.
+func DefaultBadRefsPath() (string, error) {
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(cacheDir, "osdctl", "promote", "bad-refs.json"), nil
+}
+
+// LoadBadRefs reads the bad refs at path. No refs are marked as bad if the file does not exist.
+func LoadBadRefs(path string) (*BadRefs, error) {
+	badRefs := &BadRefs{path: path}
+
+	data, err := os.ReadFile(path) //#nosec G304 -- path is in the osdctl cache directory
+	if errors.Is(err, os.ErrNotExist) {
+		return badRefs, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("cannot read bad refs %s: %w", path, err)
+	}
+
+	if err := json.Unmarshal(data, badRefs); err != nil {
+		return nil, fmt.Errorf("invalid bad refs %s: %w", path, err)
+	}
+	return badRefs, nil
+}
+
+// Find returns the bad ref of the service, if it was marked.
+func (b *BadRefs) Find(service, ref string) (BadRef, bool) {
+	for _, badRef := range b.Refs {
+		if badRef.Service == service && badRef.Ref == ref {
+			return badRef, true
+		}
+	}
+	return BadRef{}, false
+}
+
+// Mark records ref as bad for the service, updating the reason if it was already marked.
+func (b *BadRefs) Mark(service, ref, reason string, now time.Time) {
+	for i, badRef := range b.Refs {
+		if badRef.Service == service && badRef.Ref == ref {
+			b.Refs[i].Reason = reason
+			b.Refs[i].MarkedAt = now.UTC()
+			return
+		}
+	}
+	b.Refs = append(b.Refs, BadRef{Service: service, Ref: ref, MarkedAt: now.UTC(), Reason: reason})
+}
+
+// Save writes the bad refs to their path.
+func (b *BadRefs) Save() error {
+	if err := os.MkdirAll(filepath.Dir(b.path), 0750); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(b.path, data, 0600); err != nil {
+		return fmt.Errorf("cannot write bad refs %s: %w", b.path, err)
+	}
+	return nil
+}
+
+// WarnIfBadRef prints a warning if the ref of the service about to be promoted was marked as bad.
+func WarnIfBadRef(service, ref string) {
+	path, err := DefaultBadRefsPath()
+	if err != nil {
+		return
+	}
+	badRefs, err := LoadBadRefs(path)
+	if err != nil {
+		fmt.Printf("Cannot check for bad refs: %v\n", err)
+		return
+	}
+	if badRef, ok := badRefs.Find(service, ref); ok {
+		fmt.Printf("WARNING: %s of %s was marked as bad on %s: %s\n", ref, service, badRef.MarkedAt.Format(time.RFC3339), badRef.Reason)
+	}
+}
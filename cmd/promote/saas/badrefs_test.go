@@ -0,0 +1,60 @@
+package saas
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBadRefs(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "promote", "bad-refs.json")
+
+	badRefs, err := LoadBadRefs(path)
+	require.NoError(t, err)
+	_, found := badRefs.Find("saas-example", "abc123")
+	assert.False(t, found)
+
+	markedAt := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	badRefs.Mark("saas-example", "abc123", "rolled back to def456", markedAt)
+	badRefs.Mark("saas-example", "abc123", "rolled back to 789abc", markedAt.Add(time.Hour))
+	badRefs.Mark("saas-other", "abc123", "broken", markedAt)
+	require.NoError(t, badRefs.Save())
+
+	loaded, err := LoadBadRefs(path)
+	require.NoError(t, err)
+	require.Len(t, loaded.Refs, 2)
+
+	badRef, found := loaded.Find("saas-example", "abc123")
+	require.True(t, found)
+	assert.Equal(t, "rolled back to 789abc", badRef.Reason)
+	assert.Equal(t, markedAt.Add(time.Hour), badRef.MarkedAt)
+
+	_, found = loaded.Find("saas-example", "def456")
+	assert.False(t, found)
+}
+
+func TestDefaultBadRefsPathWithConfigFile(t *testing.T) {
+	// The osdctl config file takes the osdctl entry of the config directory
+	configDir, cacheDir := t.TempDir(), t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(configDir, "osdctl"), []byte("prod_jumprole_account_id: 123\n"), 0600))
+	t.Setenv("XDG_CONFIG_HOME", configDir)
+	t.Setenv("XDG_CACHE_HOME", cacheDir)
+	t.Setenv("HOME", t.TempDir())
+
+	path, err := DefaultBadRefsPath()
+	require.NoError(t, err)
+
+	badRefs, err := LoadBadRefs(path)
+	require.NoError(t, err)
+	badRefs.Mark("saas-example", "abc123", "rolled back to def456", time.Now())
+	require.NoError(t, badRefs.Save())
+
+	loaded, err := LoadBadRefs(path)
+	require.NoError(t, err)
+	_, found := loaded.Find("saas-example", "abc123")
+	assert.True(t, found)
+}
@@ -0,0 +1,201 @@
+package saas
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/openshift/osdctl/cmd/promote/git"
+	"gopkg.in/yaml.v3"
+)
+
+// PromotionPlan lists the services to promote together in a single app-interface commit, i.e.
+//
+//	promotions:
+//	  - serviceName: saas-managed-cluster-config
+//	    gitHash: 0123456789abcdef0123456789abcdef01234567
+//	    osd: true
+//	  - serviceName: configure-alertmanager-operator
+//	    hcp: true
+type PromotionPlan struct {
+	Promotions []PlanEntry `yaml:"promotions"`
+}
+
+// PlanEntry is a single service promotion of a plan. The service is promoted to the HEAD of its
+// repository if GitHash is empty.
+type PlanEntry struct {
+	ServiceName  string `yaml:"serviceName"`
+	GitHash      string `yaml:"gitHash"`
+	NamespaceRef string `yaml:"namespaceRef"`
+	OSD          bool   `yaml:"osd"`
+	HCP          bool   `yaml:"hcp"`
+}
+
+// plannedPromotion is a plan entry resolved against app-interface and the service repository.
+type plannedPromotion struct {
+	serviceName      string
+	saasFile         string
+	serviceRepo      string
+	currentGitHash   string
+	promotionGitHash string
+	commitLog        string
+}
+
+var branchNameSanitizer = regexp.MustCompile(`[^a-zA-Z0-9._-]+`)
+
+// ReadPromotionPlan reads the promotion plan at path and checks its entries are well formed.
+func ReadPromotionPlan(path string) (PromotionPlan, error) {
+	data, err := os.ReadFile(path) //#nosec G304 -- path is provided by the user
+	if err != nil {
+		return PromotionPlan{}, fmt.Errorf("failed to read promotion plan: %v", err)
+	}
+
+	var plan PromotionPlan
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&plan); err != nil {
+		return PromotionPlan{}, fmt.Errorf("failed to parse promotion plan %s: %v", path, err)
+	}
+
+	if len(plan.Promotions) == 0 {
+		return PromotionPlan{}, fmt.Errorf("promotion plan %s has no promotions", path)
+	}
+	for i, entry := range plan.Promotions {
+		if entry.ServiceName == "" {
+			return PromotionPlan{}, fmt.Errorf("promotion %d of the plan has no serviceName", i+1)
+		}
+		if entry.OSD == entry.HCP {
+			return PromotionPlan{}, fmt.Errorf("promotion %d of the plan (%s) must set exactly one of osd or hcp", i+1, entry.ServiceName)
+		}
+	}
+
+	return plan, nil
+}
+
+// planBranchName returns the app-interface branch of the plan, derived from its file name and
+// suffixed with the time of the promotion so that a plan file name can be used more than once.
+func planBranchName(planPath string, now time.Time) string {
+	name := strings.TrimSuffix(filepath.Base(planPath), filepath.Ext(planPath))
+	return fmt.Sprintf("promote-plan-%s-%s", strings.Trim(branchNameSanitizer.ReplaceAllString(name, "-"), "-"), now.UTC().Format("20060102-150405"))
+}
+
+// resolvePlan validates every entry of the plan against the services of app-interface and
+// resolves the hashes to promote, before app-interface is modified.
+func resolvePlan(appInterface git.AppInterface, plan PromotionPlan) ([]plannedPromotion, error) {
+	_, err := GetServiceNames(appInterface, OSDSaasDir, BPSaasDir, CADSaasDir)
+	if err != nil {
+		return nil, err
+	}
+
+	var promotions []plannedPromotion
+	saasFiles := map[string]bool{}
+	for _, entry := range plan.Promotions {
+		serviceName, err := ValidateServiceName(ServicesSlice, entry.ServiceName)
+		if err != nil {
+			return nil, err
+		}
+
+		saasFile, err := GetSaasDir(serviceName, entry.OSD, entry.HCP)
+		if err != nil {
+			return nil, err
+		}
+		if saasFiles[saasFile] {
+			return nil, fmt.Errorf("service %s is promoted more than once in %s", serviceName, saasFile)
+		}
+		saasFiles[saasFile] = true
+
+		serviceData, err := os.ReadFile(saasFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read SAAS file: %v", err)
+		}
+
+		currentGitHash, serviceRepo, err := git.GetCurrentGitHashFromAppInterface(serviceData, serviceName, entry.NamespaceRef)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get current git hash or service repo of %s: %v", serviceName, err)
+		}
+
+		promotionGitHash, commitLog, err := appInterface.CheckoutAndCompareGitHash(serviceRepo, entry.GitHash, currentGitHash, "")
+		if err != nil {
+			return nil, fmt.Errorf("failed to checkout and compare git hash of %s: %v", serviceName, err)
+		}
+
+		fmt.Printf("Service: %s will be promoted from %s to %s\n", serviceName, currentGitHash, promotionGitHash)
+		WarnIfBadRef(serviceName, promotionGitHash)
+		promotions = append(promotions, plannedPromotion{
+			serviceName:      serviceName,
+			saasFile:         saasFile,
+			serviceRepo:      serviceRepo,
+			currentGitHash:   currentGitHash,
+			promotionGitHash: promotionGitHash,
+			commitLog:        commitLog,
+		})
+	}
+
+	return promotions, nil
+}
+
+// planCommitMessage returns the commit message of a plan, listing the compare link and commit log of each promotion.
+func planCommitMessage(promotions []plannedPromotion) string {
+	names := make([]string, 0, len(promotions))
+	for _, promotion := range promotions {
+		names = append(names, promotion.serviceName)
+	}
+
+	commitMessage := fmt.Sprintf("Promote %d services: %s\n\n", len(promotions), strings.Join(names, ", "))
+	commitMessage += "## Changes\n\n"
+	for _, promotion := range promotions {
+		commitMessage += fmt.Sprintf("### %s\n\n", promotion.serviceName)
+		commitMessage += fmt.Sprintf("Promote from %s to %s\n\n", promotion.currentGitHash, promotion.promotionGitHash)
+		commitMessage += fmt.Sprintf("[Compare changes on GitHub](%s/compare/%s...%s)\n\n", promotion.serviceRepo, promotion.currentGitHash, promotion.promotionGitHash)
+		commitMessage += "```\n"
+		commitMessage += strings.TrimSpace(promotion.commitLog)
+		commitMessage += "\n```\n\n"
+	}
+
+	return strings.TrimSpace(commitMessage)
+}
+
+// planPromotion applies every promotion of the plan to app-interface in a single branch and commit.
+func planPromotion(appInterface git.AppInterface, planPath string) error {
+	plan, err := ReadPromotionPlan(planPath)
+	if err != nil {
+		return err
+	}
+
+	promotions, err := resolvePlan(appInterface, plan)
+	if err != nil {
+		return err
+	}
+
+	branchName := planBranchName(planPath, time.Now())
+	if err := appInterface.CreatePromotionBranch(branchName); err != nil {
+		return err
+	}
+
+	saasFiles := make([]string, 0, len(promotions))
+	for _, promotion := range promotions {
+		if err := appInterface.UpdateSaasFileHash(promotion.saasFile, promotion.currentGitHash, promotion.promotionGitHash, false); err != nil {
+			return fmt.Errorf("failed to update %s: %v", promotion.serviceName, err)
+		}
+		saasFiles = append(saasFiles, promotion.saasFile)
+	}
+
+	commitMessage := planCommitMessage(promotions)
+	fmt.Printf("commitMessage: %s\n", commitMessage)
+
+	if err := appInterface.CommitFiles(commitMessage, saasFiles...); err != nil {
+		return fmt.Errorf("failed to commit changes to app-interface; manual commit may still succeed: %w", err)
+	}
+
+	fmt.Printf("The branch %s is ready to be pushed\n", branchName)
+	fmt.Println("")
+	for _, promotion := range promotions {
+		fmt.Printf("service: %s from: %s to: %s\n", promotion.serviceName, promotion.currentGitHash, promotion.promotionGitHash)
+	}
+	fmt.Println("READY TO PUSH,", len(promotions), "service promotions are ready locally")
+	return nil
+}
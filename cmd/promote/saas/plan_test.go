@@ -0,0 +1,96 @@
+package saas
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadPromotionPlan(t *testing.T) {
+	tests := []struct {
+		name       string
+		content    string
+		expectErr  string
+		promotions []PlanEntry
+	}{
+		{
+			name: "valid_plan",
+			content: `promotions:
+  - serviceName: saas-managed-cluster-config
+    gitHash: abc123
+    osd: true
+  - serviceName: configure-alertmanager-operator
+    namespaceRef: hivep01
+    hcp: true
+`,
+			promotions: []PlanEntry{
+				{ServiceName: "saas-managed-cluster-config", GitHash: "abc123", OSD: true},
+				{ServiceName: "configure-alertmanager-operator", NamespaceRef: "hivep01", HCP: true},
+			},
+		},
+		{
+			name:      "empty_plan",
+			content:   "promotions: []\n",
+			expectErr: "has no promotions",
+		},
+		{
+			name:      "missing_service_name",
+			content:   "promotions:\n  - gitHash: abc123\n    osd: true\n",
+			expectErr: "promotion 1 of the plan has no serviceName",
+		},
+		{
+			name:      "neither_osd_nor_hcp",
+			content:   "promotions:\n  - serviceName: foo\n",
+			expectErr: "must set exactly one of osd or hcp",
+		},
+		{
+			name:      "both_osd_and_hcp",
+			content:   "promotions:\n  - serviceName: foo\n    osd: true\n    hcp: true\n",
+			expectErr: "must set exactly one of osd or hcp",
+		},
+		{
+			name:      "unknown_field",
+			content:   "promotions:\n  - serviceName: foo\n    osd: true\n    hash: abc\n",
+			expectErr: "failed to parse promotion plan",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "plan.yaml")
+			require.NoError(t, os.WriteFile(path, []byte(tc.content), 0o600))
+
+			plan, err := ReadPromotionPlan(path)
+			if tc.expectErr != "" {
+				assert.ErrorContains(t, err, tc.expectErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.promotions, plan.Promotions)
+		})
+	}
+}
+
+func TestPlanBranchName(t *testing.T) {
+	now := time.Date(2024, 5, 1, 10, 30, 0, 0, time.UTC)
+	assert.Equal(t, "promote-plan-release-2024-05-01-20240501-103000", planBranchName("/tmp/plans/release 2024-05-01.yaml", now))
+	assert.Equal(t, "promote-plan-weekly-20240501-103000", planBranchName("weekly.yml", now))
+	assert.NotEqual(t, planBranchName("weekly.yml", now), planBranchName("weekly.yml", now.Add(time.Second)))
+}
+
+func TestPlanCommitMessage(t *testing.T) {
+	message := planCommitMessage([]plannedPromotion{
+		{serviceName: "saas-foo", serviceRepo: "https://github.com/openshift/foo", currentGitHash: "aaa", promotionGitHash: "bbb", commitLog: "commit bbb\n\n    Fix foo\n"},
+		{serviceName: "saas-bar", serviceRepo: "https://github.com/openshift/bar", currentGitHash: "ccc", promotionGitHash: "ddd", commitLog: "commit ddd"},
+	})
+
+	assert.Contains(t, message, "Promote 2 services: saas-foo, saas-bar\n")
+	assert.Contains(t, message, "### saas-foo")
+	assert.Contains(t, message, "[Compare changes on GitHub](https://github.com/openshift/foo/compare/aaa...bbb)")
+	assert.Contains(t, message, "[Compare changes on GitHub](https://github.com/openshift/bar/compare/ccc...ddd)")
+	assert.Contains(t, message, "```\ncommit bbb\n\n    Fix foo\n```")
+}
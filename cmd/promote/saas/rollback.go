@@ -0,0 +1,163 @@
+package saas
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/openshift/osdctl/cmd/promote/git"
+	ocmutils "github.com/openshift/osdctl/pkg/utils"
+)
+
+// RollbackTarget is the ref of a saas file to roll back to its previously promoted value.
+type RollbackTarget struct {
+	ServiceName string
+	SaasFile    string
+	// ServiceRepo is used to link the faulty and restored changes, if set.
+	ServiceRepo string
+	// RefKind names the ref in the messages, i.e. "git hash" or "package tag".
+	RefKind string
+	// Extract returns the ref of a revision of the saas file.
+	Extract func(saasData []byte) (string, error)
+}
+
+// RollbackSaasFile finds the previously promoted ref of the saas file in the app-interface history
+// and prepares a branch reverting the current ref to it.
+func RollbackSaasFile(appInterface git.AppInterface, target RollbackTarget) error {
+	history, err := appInterface.RefHistory(target.SaasFile, 3, target.Extract)
+	if err != nil {
+		return err
+	}
+	if len(history) < 2 {
+		return fmt.Errorf("no previous %s of %s found in the app-interface history", target.RefKind, target.ServiceName)
+	}
+	faulty, restored := history[0], history[1]
+	if err := checkRollbackRefs(target, faulty, restored); err != nil {
+		return err
+	}
+	fmt.Printf("Service: %s will be rolled back from %s to %s\n", target.ServiceName, faulty.Ref, restored.Ref)
+
+	branchName := fmt.Sprintf("rollback-%s-%s", target.ServiceName, restored.Ref)
+	if err := appInterface.CreatePromotionBranch(branchName); err != nil {
+		return err
+	}
+	if err := appInterface.RevertSaasFileRef(target.SaasFile, faulty.Ref, restored.Ref); err != nil {
+		return err
+	}
+
+	commitMessage := rollbackCommitMessage(target, history)
+	fmt.Printf("commitMessage: %s\n", commitMessage)
+	if err := appInterface.CommitSaasFile(target.SaasFile, commitMessage); err != nil {
+		return fmt.Errorf("failed to commit changes to app-interface; manual commit may still succeed: %w", err)
+	}
+
+	fmt.Printf("The branch %s is ready to be pushed\n", branchName)
+	fmt.Println("")
+	fmt.Println("service:", target.ServiceName)
+	fmt.Println("from:", faulty.Ref)
+	fmt.Println("to:", restored.Ref)
+	fmt.Println("READY TO PUSH,", target.ServiceName, "rollback commit is ready locally")
+	fmt.Println("")
+
+	fmt.Printf("Mark %s as bad, so that later promotions of %s warn about it?\n", faulty.Ref, target.ServiceName)
+	if ocmutils.ConfirmPrompt() {
+		return markBadRef(target.ServiceName, faulty.Ref, fmt.Sprintf("rolled back to %s", restored.Ref))
+	}
+	return nil
+}
+
+// checkRollbackRefs refuses to roll back from or to an empty ref, which would corrupt the saas file.
+func checkRollbackRefs(target RollbackTarget, faulty, restored git.RefRevision) error {
+	if faulty.Ref == "" {
+		return fmt.Errorf("cannot roll back %s: the current %s (app-interface commit %s) is empty", target.ServiceName, target.RefKind, faulty.Commit)
+	}
+	if restored.Ref == "" {
+		return fmt.Errorf("cannot roll back %s: the previous %s (app-interface commit %s) is empty", target.ServiceName, target.RefKind, restored.Commit)
+	}
+	return nil
+}
+
+// rollbackCommitMessage returns the commit message of a rollback, linking the faulty and restored changes.
+// history lists the faulty ref, the restored ref and optionally the ref promoted before the restored one.
+func rollbackCommitMessage(target RollbackTarget, history []git.RefRevision) string {
+	faulty, restored := history[0], history[1]
+
+	commitMessage := fmt.Sprintf("Rollback %s to %s\n\n", target.ServiceName, restored.Ref)
+	commitMessage += fmt.Sprintf("Reverts the promotion of %s from %s to %s (app-interface commit %s).\n\n", target.RefKind, restored.Ref, faulty.Ref, faulty.Commit)
+
+	commitMessage += "## Faulty changes\n\n"
+	commitMessage += rangeLink(target.ServiceRepo, restored.Ref, faulty.Ref) + "\n\n"
+
+	commitMessage += "## Restored changes\n\n"
+	if len(history) > 2 {
+		commitMessage += rangeLink(target.ServiceRepo, history[2].Ref, restored.Ref) + "\n"
+	} else {
+		commitMessage += fmt.Sprintf("%s (app-interface commit %s)\n", restored.Ref, restored.Commit)
+	}
+
+	return commitMessage
+}
+
+// rangeLink returns a link comparing the changes between from and to, or the range if repo is unknown.
+func rangeLink(repo, from, to string) string {
+	if repo == "" {
+		return fmt.Sprintf("%s...%s", from, to)
+	}
+	return fmt.Sprintf("[Compare %s...%s on GitHub](%s/compare/%s...%s)", from, to, repo, from, to)
+}
+
+func markBadRef(serviceName, ref, reason string) error {
+	path, err := DefaultBadRefsPath()
+	if err != nil {
+		return err
+	}
+	badRefs, err := LoadBadRefs(path)
+	if err != nil {
+		return err
+	}
+	badRefs.Mark(serviceName, ref, reason, time.Now())
+	if err := badRefs.Save(); err != nil {
+		return err
+	}
+	fmt.Printf("%s of %s marked as bad in %s\n", ref, serviceName, path)
+	return nil
+}
+
+// serviceRollback rolls the git hash of a SaaS service back to the previously promoted one.
+func serviceRollback(appInterface git.AppInterface, serviceName, namespaceRef string, osd, hcp bool) error {
+	_, err := GetServiceNames(appInterface, OSDSaasDir, BPSaasDir, CADSaasDir)
+	if err != nil {
+		return err
+	}
+
+	serviceName, err = ValidateServiceName(ServicesSlice, serviceName)
+	if err != nil {
+		return err
+	}
+
+	saasDir, err := GetSaasDir(serviceName, osd, hcp)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("SAAS Directory: %v\n", saasDir)
+
+	serviceData, err := os.ReadFile(saasDir)
+	if err != nil {
+		return fmt.Errorf("failed to read SAAS file: %v", err)
+	}
+	_, serviceRepo, err := git.GetCurrentGitHashFromAppInterface(serviceData, serviceName, namespaceRef)
+	if err != nil {
+		return fmt.Errorf("failed to get current git hash or service repo: %v", err)
+	}
+
+	return RollbackSaasFile(appInterface, RollbackTarget{
+		ServiceName: serviceName,
+		SaasFile:    saasDir,
+		ServiceRepo: serviceRepo,
+		RefKind:     "git hash",
+		Extract: func(saasData []byte) (string, error) {
+			gitHash, _, err := git.GetCurrentGitHashFromAppInterface(saasData, serviceName, namespaceRef)
+			return gitHash, err
+		},
+	})
+}
@@ -0,0 +1,44 @@
+package saas
+
+import (
+	"testing"
+
+	"github.com/openshift/osdctl/cmd/promote/git"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRollbackCommitMessage(t *testing.T) {
+	history := []git.RefRevision{
+		{Ref: "bad", Commit: "c3"},
+		{Ref: "good", Commit: "c2"},
+		{Ref: "older", Commit: "c1"},
+	}
+
+	t.Run("with_service_repo", func(t *testing.T) {
+		target := RollbackTarget{ServiceName: "saas-example", ServiceRepo: "https://github.com/openshift/example", RefKind: "git hash"}
+		message := rollbackCommitMessage(target, history)
+
+		assert.Contains(t, message, "Rollback saas-example to good\n\n")
+		assert.Contains(t, message, "Reverts the promotion of git hash from good to bad (app-interface commit c3).")
+		assert.Contains(t, message, "## Faulty changes\n\n[Compare good...bad on GitHub](https://github.com/openshift/example/compare/good...bad)")
+		assert.Contains(t, message, "## Restored changes\n\n[Compare older...good on GitHub](https://github.com/openshift/example/compare/older...good)")
+	})
+
+	t.Run("without_service_repo_nor_older_ref", func(t *testing.T) {
+		target := RollbackTarget{ServiceName: "saas-example", RefKind: "package tag"}
+		message := rollbackCommitMessage(target, history[:2])
+
+		assert.Contains(t, message, "## Faulty changes\n\ngood...bad")
+		assert.Contains(t, message, "## Restored changes\n\ngood (app-interface commit c2)")
+	})
+}
+
+func TestCheckRollbackRefs(t *testing.T) {
+	target := RollbackTarget{ServiceName: "saas-example", RefKind: "package tag"}
+
+	assert.NoError(t, checkRollbackRefs(target, git.RefRevision{Ref: "bad", Commit: "c2"}, git.RefRevision{Ref: "good", Commit: "c1"}))
+	assert.EqualError(t, checkRollbackRefs(target, git.RefRevision{Commit: "c2"}, git.RefRevision{Ref: "good", Commit: "c1"}),
+		"cannot roll back saas-example: the current package tag (app-interface commit c2) is empty")
+	assert.EqualError(t, checkRollbackRefs(target, git.RefRevision{Ref: "bad", Commit: "c2"}, git.RefRevision{Commit: "c1"}),
+		"cannot roll back saas-example: the previous package tag (app-interface commit c1) is empty")
+}
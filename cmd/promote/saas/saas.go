@@ -17,6 +17,8 @@ type saasOptions struct {
 	gitHash                 string
 	namespaceRef            string
 	hotfix                  bool
+	plan                    string
+	rollback                bool
 }
 
 // newCmdSaas implementes the saas command to interact with promoting SaaS services/operators
@@ -34,12 +36,27 @@ func NewCmdSaas() *cobra.Command {
 		# Promote a SaaS service/operator
 		osdctl promote saas --serviceName <service-name> --gitHash <git-hash> --osd
 		or
-		osdctl promote saas --serviceName <service-name> --gitHash <git-hash> --hcp`,
+		osdctl promote saas --serviceName <service-name> --gitHash <git-hash> --hcp
+
+		# Roll a SaaS service/operator back to its previously promoted git hash
+		osdctl promote saas --serviceName <service-name> --osd --rollback
+
+		# Promote several SaaS services/operators in a single commit
+		osdctl promote saas --plan release.yaml
+
+		# with release.yaml:
+		promotions:
+		  - serviceName: saas-managed-cluster-config
+		    gitHash: <git-hash>
+		    osd: true
+		  - serviceName: configure-alertmanager-operator
+		    namespaceRef: <namespace-ref>
+		    hcp: true`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			ops.validateSaasFlow()
 			appInterface := git.BootstrapOsdCtlForAppInterfaceAndServicePromotions(ops.appInterfaceCheckoutDir, iexec.Exec{})
 			if ops.list {
-				if ops.serviceName != "" || ops.gitHash != "" || ops.osd || ops.hcp {
+				if ops.serviceName != "" || ops.gitHash != "" || ops.osd || ops.hcp || ops.plan != "" || ops.rollback {
 					fmt.Printf("Error: --list cannot be used with any other flags\n\n")
 
 					return cmd.Help()
@@ -47,12 +64,40 @@ func NewCmdSaas() *cobra.Command {
 				return listServiceNames(appInterface)
 			}
 
+			if ops.plan != "" {
+				if ops.serviceName != "" || ops.gitHash != "" || ops.namespaceRef != "" || ops.osd || ops.hcp || ops.hotfix || ops.rollback {
+					fmt.Printf("Error: --plan cannot be used with --serviceName, --gitHash, --namespaceRef, --osd, --hcp, --hotfix or --rollback\n\n")
+
+					return cmd.Help()
+				}
+				err := planPromotion(appInterface, ops.plan)
+				if err != nil {
+					fmt.Printf("Error while promoting services: %v\n", err)
+				}
+
+				return nil
+			}
+
 			if !(ops.osd || ops.hcp) && ops.serviceName != "" {
 				fmt.Printf("Error: --serviceName cannot be used without either --osd or --hcp\n\n")
 
 				return cmd.Help()
 			}
 
+			if ops.rollback {
+				if ops.serviceName == "" || ops.gitHash != "" || ops.hotfix {
+					fmt.Printf("Error: --rollback requires --serviceName and cannot be used with --gitHash or --hotfix\n\n")
+
+					return cmd.Help()
+				}
+				err := serviceRollback(appInterface, ops.serviceName, ops.namespaceRef, ops.osd, ops.hcp)
+				if err != nil {
+					fmt.Printf("Error while rolling back service: %v\n", err)
+				}
+
+				return nil
+			}
+
 			if ops.hotfix && ops.gitHash == "" {
 				fmt.Printf("Error: --hotfix requires --gitHash to be specified\n\n")
 
@@ -76,12 +121,14 @@ func NewCmdSaas() *cobra.Command {
 	saasCmd.Flags().BoolVarP(&ops.hcp, "hcp", "", false, "HCP service/operator getting promoted")
 	saasCmd.Flags().StringVarP(&ops.appInterfaceCheckoutDir, "appInterfaceDir", "", "", "location of app-interface checkout. Falls back to current working directory")
 	saasCmd.Flags().BoolVarP(&ops.hotfix, "hotfix", "", false, "Add gitHash to hotfixVersions in app.yml to bypass progressive delivery (requires --gitHash)")
+	saasCmd.Flags().BoolVarP(&ops.rollback, "rollback", "", false, "Roll the service back to the git hash promoted before the current one, found in the app-interface history")
+	saasCmd.Flags().StringVarP(&ops.plan, "plan", "", "", "YAML promotion plan listing the serviceName, gitHash, namespaceRef and osd or hcp of each service to promote in a single commit")
 
 	return saasCmd
 }
 
 func (o *saasOptions) validateSaasFlow() {
-	if o.serviceName == "" && o.gitHash == "" {
+	if o.serviceName == "" && o.gitHash == "" && o.plan == "" {
 		fmt.Printf("Usage: For SaaS services/operators, please provide --serviceName and (optional) --gitHash\n")
 		fmt.Printf("--serviceName is the name of the service, i.e. saas-managed-cluster-config\n")
 		fmt.Printf("--gitHash is the target git commit in the service, if not specified defaults to HEAD of master\n\n")
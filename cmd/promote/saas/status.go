@@ -0,0 +1,333 @@
+package saas
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/openshift/osdctl/cmd/promote/git"
+	"github.com/openshift/osdctl/cmd/promote/iexec"
+	"github.com/openshift/osdctl/pkg/printer"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+const (
+	statusOutputText = "text"
+	statusOutputJSON = "json"
+)
+
+type statusOptions struct {
+	serviceName             string
+	osd                     bool
+	hcp                     bool
+	appInterfaceCheckoutDir string
+	output                  string
+}
+
+// TargetStatus is the promotion status of a single saas target.
+type TargetStatus struct {
+	git.SaasTarget
+	SaasFile string `json:"saasFile"`
+	// GitHash is the commit the ref resolves to in the service repository.
+	GitHash string `json:"gitHash,omitempty"`
+	// CommitsBehind is the number of commits the target trails the HEAD of the service repository.
+	CommitsBehind *int   `json:"commitsBehind,omitempty"`
+	Drift         bool   `json:"drift"`
+	Error         string `json:"error,omitempty"`
+}
+
+// ServiceStatus is the promotion status of every target of a service.
+type ServiceStatus struct {
+	ServiceName string `json:"serviceName"`
+	// Heads maps the service repositories to the hash of their HEAD.
+	Heads   map[string]string `json:"heads"`
+	Targets []TargetStatus    `json:"targets"`
+	// DriftedEnvironments lists the environments whose targets are pinned to different hashes.
+	DriftedEnvironments []string `json:"driftedEnvironments"`
+}
+
+// NewCmdStatus implements the status command to report where a service is deployed
+func NewCmdStatus() *cobra.Command {
+	ops := &statusOptions{}
+	statusCmd := &cobra.Command{
+		Use:   "status",
+		Short: "Show the ref each saas target of a service is pinned to",
+		Long: `Show the ref each target of the saas file(s) of a service is pinned to, how many commits
+it trails the HEAD of the service repository, and whether targets of the same environment
+are pinned to different hashes (drift).
+
+The service repositories are always cloned with the git binary, --git-backend go-git is not supported.`,
+		Args:              cobra.NoArgs,
+		DisableAutoGenTag: true,
+		Example: `
+		# Show the status of every target of a service
+		osdctl promote status --serviceName <service-name>
+
+		# Only the HCP targets, as JSON
+		osdctl promote status --serviceName <service-name> --hcp -o json`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if ops.output != statusOutputText && ops.output != statusOutputJSON {
+				return fmt.Errorf("invalid output format: %s (allowed: text, json)", ops.output)
+			}
+			if backend := viper.GetString(git.GitBackendKey); backend == git.BackendGoGit {
+				return fmt.Errorf("--git-backend %s is not supported by promote status, use %s", backend, git.BackendExec)
+			}
+
+			appInterface := git.BootstrapOsdCtlForAppInterfaceAndServicePromotions(ops.appInterfaceCheckoutDir, iexec.Exec{})
+			status, err := serviceStatus(appInterface, ops.serviceName, ops.osd, ops.hcp)
+			if err != nil {
+				return err
+			}
+
+			if ops.output == statusOutputJSON {
+				data, err := json.MarshalIndent(status, "", "  ")
+				if err != nil {
+					return err
+				}
+				fmt.Println(string(data))
+				return nil
+			}
+			return printServiceStatus(os.Stdout, status)
+		},
+	}
+
+	statusCmd.Flags().StringVarP(&ops.serviceName, "serviceName", "", "", "SaaS service/operator to report on")
+	statusCmd.Flags().BoolVarP(&ops.osd, "osd", "", false, "Only report the OSD saas file")
+	statusCmd.Flags().BoolVarP(&ops.hcp, "hcp", "", false, "Only report the HCP saas file")
+	statusCmd.Flags().StringVarP(&ops.appInterfaceCheckoutDir, "appInterfaceDir", "", "", "location of app-interface checkout. Falls back to current working directory")
+	statusCmd.Flags().StringVarP(&ops.output, "output", "o", statusOutputText, "Output format. One of: text, json")
+	_ = statusCmd.MarkFlagRequired("serviceName")
+
+	return statusCmd
+}
+
+// statusSaasFiles returns the existing saas files of the service. Both the OSD and the HCP
+// saas files are returned if neither osd nor hcp is set.
+func statusSaasFiles(serviceName string, osd, hcp bool) ([]string, error) {
+	if !osd && !hcp {
+		osd, hcp = true, true
+	}
+
+	var saasFiles []string
+	for _, flavour := range []struct{ osd, hcp bool }{{osd, false}, {false, hcp}} {
+		if !flavour.osd && !flavour.hcp {
+			continue
+		}
+		saasFile, err := GetSaasDir(serviceName, flavour.osd, flavour.hcp)
+		if err != nil {
+			continue
+		}
+		if info, err := os.Stat(saasFile); err == nil && !info.IsDir() {
+			saasFiles = append(saasFiles, saasFile)
+		}
+	}
+
+	if len(saasFiles) == 0 {
+		return nil, fmt.Errorf("no saas file found for service %s", serviceName)
+	}
+	return saasFiles, nil
+}
+
+// lookupServiceName returns the name of the service in app-interface, with or without the saas- prefix.
+func lookupServiceName(serviceName string) (string, error) {
+	for _, name := range []string{serviceName, "saas-" + serviceName} {
+		if _, ok := ServicesFilesMap[name]; ok {
+			return name, nil
+		}
+	}
+	return "", fmt.Errorf("service %s not found", serviceName)
+}
+
+// serviceStatus reads the saas files of the service and compares the ref of every target with
+// the HEAD of its service repository.
+func serviceStatus(appInterface git.AppInterface, serviceName string, osd, hcp bool) (*ServiceStatus, error) {
+	if _, err := GetServiceNames(appInterface, OSDSaasDir, BPSaasDir, CADSaasDir); err != nil {
+		return nil, err
+	}
+	serviceName, err := lookupServiceName(serviceName)
+	if err != nil {
+		return nil, err
+	}
+
+	saasFiles, err := statusSaasFiles(serviceName, osd, hcp)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &ServiceStatus{ServiceName: serviceName, Heads: map[string]string{}}
+	for _, saasFile := range saasFiles {
+		data, err := os.ReadFile(saasFile) //#nosec G304 -- saasFile is located in app-interface
+		if err != nil {
+			return nil, fmt.Errorf("failed to read SAAS file: %v", err)
+		}
+		targets, err := git.ListSaasTargets(data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %v", saasFile, err)
+		}
+		for _, target := range targets {
+			status.Targets = append(status.Targets, TargetStatus{SaasTarget: target, SaasFile: saasFile})
+		}
+	}
+
+	resolveTargets(appInterface.GitExecutor, status)
+	status.DriftedEnvironments = markDrift(status.Targets)
+
+	return status, nil
+}
+
+// resolveTargets clones each service repository once and resolves the hash and the number
+// of commits behind HEAD of every target. Failures are recorded on the targets.
+func resolveTargets(gitExecutor iexec.IExec, status *ServiceStatus) {
+	repoDirs := map[string]string{}
+	repoErrors := map[string]error{}
+	var cleanups []func()
+	defer func() {
+		for _, cleanup := range cleanups {
+			cleanup()
+		}
+	}()
+
+	for i := range status.Targets {
+		target := &status.Targets[i]
+
+		if _, ok := repoDirs[target.Repo]; !ok && repoErrors[target.Repo] == nil {
+			log.Printf("Cloning %s\n", target.Repo)
+			dir, cleanup, err := git.CloneServiceRepo(gitExecutor, target.Repo)
+			if err != nil {
+				repoErrors[target.Repo] = err
+			} else {
+				cleanups = append(cleanups, cleanup)
+				head, err := git.ResolveRef(gitExecutor, dir, "HEAD")
+				if err != nil {
+					repoErrors[target.Repo] = err
+				} else {
+					repoDirs[target.Repo] = dir
+					status.Heads[target.Repo] = head
+				}
+			}
+		}
+		if err := repoErrors[target.Repo]; err != nil {
+			target.Error = err.Error()
+			continue
+		}
+
+		dir, head := repoDirs[target.Repo], status.Heads[target.Repo]
+		hash, err := git.ResolveRef(gitExecutor, dir, target.Ref)
+		if err != nil {
+			target.Error = err.Error()
+			continue
+		}
+		target.GitHash = hash
+
+		behind, err := git.CountCommitsBehind(gitExecutor, dir, hash, head)
+		if err != nil {
+			target.Error = err.Error()
+			continue
+		}
+		target.CommitsBehind = &behind
+	}
+}
+
+// markDrift flags the targets of a resource template pinned to a different hash than other
+// targets of the same environment, and returns the drifted environments.
+func markDrift(targets []TargetStatus) []string {
+	groups := map[string][]int{}
+	for i, target := range targets {
+		if target.Environment == git.EnvironmentUnknown {
+			continue
+		}
+		key := strings.Join([]string{target.SaasFile, target.ResourceTemplate, target.Environment}, "|")
+		groups[key] = append(groups[key], i)
+	}
+
+	drifted := map[string]bool{}
+	for _, indexes := range groups {
+		hashes := map[string]bool{}
+		for _, i := range indexes {
+			hashes[pinnedHash(targets[i])] = true
+		}
+		if len(hashes) < 2 {
+			continue
+		}
+		for _, i := range indexes {
+			targets[i].Drift = true
+			drifted[targets[i].Environment] = true
+		}
+	}
+
+	environments := []string{}
+	for environment := range drifted {
+		environments = append(environments, environment)
+	}
+	sort.Strings(environments)
+	return environments
+}
+
+// pinnedHash returns the hash the target is pinned to, or its ref if it could not be resolved.
+func pinnedHash(target TargetStatus) string {
+	if target.GitHash != "" {
+		return target.GitHash
+	}
+	return target.Ref
+}
+
+func shortHash(ref string) string {
+	if len(ref) == 40 {
+		return ref[:7]
+	}
+	return ref
+}
+
+func printServiceStatus(out io.Writer, status *ServiceStatus) error {
+	fmt.Fprintf(out, "Service: %s\n", status.ServiceName)
+	repos := make([]string, 0, len(status.Heads))
+	for repo := range status.Heads {
+		repos = append(repos, repo)
+	}
+	sort.Strings(repos)
+	for _, repo := range repos {
+		fmt.Fprintf(out, "HEAD of %s: %s\n", repo, status.Heads[repo])
+	}
+	fmt.Fprintln(out)
+
+	table := printer.NewTablePrinter(out, 20, 1, 3, ' ')
+	table.AddRow([]string{"TARGET", "ENV", "NAMESPACE", "REF", "BEHIND", "DRIFT"})
+	for _, target := range status.Targets {
+		behind := "?"
+		if target.CommitsBehind != nil {
+			behind = strconv.Itoa(*target.CommitsBehind)
+		}
+		drift := ""
+		if target.Drift {
+			drift = "DRIFT"
+		}
+		table.AddRow([]string{
+			target.Name,
+			target.Environment,
+			target.NamespaceRef,
+			shortHash(target.Ref),
+			behind,
+			drift,
+		})
+	}
+	if err := table.Flush(); err != nil {
+		return err
+	}
+	fmt.Fprintln(out)
+
+	for _, target := range status.Targets {
+		if target.Error != "" {
+			fmt.Fprintf(out, "Error on target %s: %s\n", target.Name, target.Error)
+		}
+	}
+	if len(status.DriftedEnvironments) > 0 {
+		fmt.Fprintf(out, "Targets pinned to different hashes in: %s\n", strings.Join(status.DriftedEnvironments, ", "))
+	}
+	return nil
+}
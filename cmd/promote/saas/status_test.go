@@ -0,0 +1,89 @@
+package saas
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/openshift/osdctl/cmd/promote/git"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTargetStatus(name, environment, gitHash string) TargetStatus {
+	return TargetStatus{
+		SaasTarget: git.SaasTarget{ResourceTemplate: "example", Name: name, Environment: environment, Ref: gitHash},
+		SaasFile:   "saas-example.yaml",
+		GitHash:    gitHash,
+	}
+}
+
+func TestMarkDrift(t *testing.T) {
+	targets := []TargetStatus{
+		newTargetStatus("int", git.EnvironmentIntegration, "aaa"),
+		newTargetStatus("stage", git.EnvironmentStage, "bbb"),
+		newTargetStatus("prod-hivep01", git.EnvironmentProduction, "bbb"),
+		newTargetStatus("prod-hivep02", git.EnvironmentProduction, "ccc"),
+		newTargetStatus("other-a", git.EnvironmentUnknown, "ddd"),
+		newTargetStatus("other-b", git.EnvironmentUnknown, "eee"),
+	}
+	// Targets of another resource template do not drift from the ones of the first template
+	other := newTargetStatus("stage-other", git.EnvironmentStage, "fff")
+	other.ResourceTemplate = "other"
+	targets = append(targets, other)
+
+	drifted := markDrift(targets)
+
+	assert.Equal(t, []string{git.EnvironmentProduction}, drifted)
+	for _, target := range targets {
+		assert.Equal(t, target.Environment == git.EnvironmentProduction, target.Drift, target.Name)
+	}
+}
+
+func TestMarkDriftUsesRefWhenUnresolved(t *testing.T) {
+	targets := []TargetStatus{
+		newTargetStatus("stage-a", git.EnvironmentStage, ""),
+		newTargetStatus("stage-b", git.EnvironmentStage, ""),
+	}
+	targets[0].Ref = "master"
+	targets[1].Ref = "0123456789abcdef0123456789abcdef01234567"
+
+	assert.Equal(t, []string{git.EnvironmentStage}, markDrift(targets))
+}
+
+func TestPrintServiceStatus(t *testing.T) {
+	behind := 3
+	target := newTargetStatus("example-prod", git.EnvironmentProduction, "0123456789abcdef0123456789abcdef01234567")
+	target.CommitsBehind = &behind
+	target.Drift = true
+	failed := newTargetStatus("example-int", git.EnvironmentIntegration, "")
+	failed.Ref = "master"
+	failed.Error = "ref master not found"
+
+	var out bytes.Buffer
+	err := printServiceStatus(&out, &ServiceStatus{
+		ServiceName:         "saas-example",
+		Heads:               map[string]string{"https://github.com/openshift/example": "fedcba"},
+		Targets:             []TargetStatus{target, failed},
+		DriftedEnvironments: []string{git.EnvironmentProduction},
+	})
+	require.NoError(t, err)
+
+	output := out.String()
+	assert.Contains(t, output, "HEAD of https://github.com/openshift/example: fedcba")
+	assert.Regexp(t, `example-prod\s+production\s+0123456\s+3\s+DRIFT`, output)
+	assert.Regexp(t, `example-int\s+integration\s+master\s+\?`, output)
+	assert.Contains(t, output, "Error on target example-int: ref master not found")
+	assert.Contains(t, output, "Targets pinned to different hashes in: production")
+}
+
+func TestStatusRejectsGoGitBackend(t *testing.T) {
+	viper.Set(git.GitBackendKey, git.BackendGoGit)
+	t.Cleanup(func() { viper.Set(git.GitBackendKey, git.BackendExec) })
+
+	cmd := NewCmdStatus()
+	cmd.SetArgs([]string{"--serviceName", "saas-example"})
+	cmd.SetOut(&bytes.Buffer{})
+	cmd.SetErr(&bytes.Buffer{})
+	assert.ErrorContains(t, cmd.Execute(), "not supported by promote status")
+}
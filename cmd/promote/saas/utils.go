@@ -162,7 +162,7 @@ func servicePromotion(appInterface git.AppInterface, serviceName, gitHash string
 	}
 	fmt.Printf("Current Git Hash: %v\nGit Repo: %v\n\n", currentGitHash, serviceRepo)
 
-	promotionGitHash, commitLog, err := git.CheckoutAndCompareGitHash(appInterface.GitExecutor, serviceRepo, gitHash, currentGitHash, "")
+	promotionGitHash, commitLog, err := appInterface.CheckoutAndCompareGitHash(serviceRepo, gitHash, currentGitHash, "")
 	if err != nil {
 		return fmt.Errorf("failed to checkout and compare git hash: %v", err)
 	} else if promotionGitHash == "" {
@@ -170,6 +170,7 @@ func servicePromotion(appInterface git.AppInterface, serviceName, gitHash string
 		os.Exit(6)
 	}
 	fmt.Printf("Service: %s will be promoted to %s\n", serviceName, promotionGitHash)
+	WarnIfBadRef(serviceName, promotionGitHash)
 
 	branchName := fmt.Sprintf("promote-%s-%s", serviceName, promotionGitHash)
 	err = appInterface.UpdateAppInterface(serviceName, saasDir, currentGitHash, promotionGitHash, branchName, hotfix)
@@ -20,6 +20,7 @@ func NewCmdServiceLog() *cobra.Command {
 
 	servicelogCmd.AddCommand(newListCmd())
 	servicelogCmd.AddCommand(newPostCmd())
+	servicelogCmd.AddCommand(newTemplateCmd())
 
 	return servicelogCmd
 }
@@ -3,6 +3,7 @@ package servicelog
 import (
 	"encoding/json"
 	"fmt"
+	"strings"
 	"time"
 
 	sdk "github.com/openshift-online/ocm-sdk-go"
@@ -20,6 +21,9 @@ const (
 	// in case you want to see the swagger code gen, you can look at
 	// https://api.openshift.com/?urls.primaryName=Service%20logs#/default/post_api_service_logs_v1_cluster_logs
 	targetAPIPath = "/api/service_logs/v1/cluster_logs"
+
+	// serviceLogsPageSize is the number of service logs requested per page.
+	serviceLogsPageSize = 100
 )
 
 func validateGoodResponse(body []byte, clusterMessage servicelog.Message) (goodReply *servicelog.GoodReply, err error) {
@@ -95,12 +99,10 @@ func FetchServiceLogs(clusterID string, allMessages bool, internalOnly bool) (*v
 		}
 	}()
 
-	// Use the OCM client to retrieve clusters
-	clusters := utils.GetClusters(ocmClient, []string{clusterID})
-	if len(clusters) != 1 {
-		return nil, fmt.Errorf("GetClusters expected to return 1 cluster, got: %d", len(clusters))
+	cluster, err := getServiceLogCluster(ocmClient, clusterID)
+	if err != nil {
+		return nil, err
 	}
-	cluster := clusters[0]
 
 	// Now get the SLs for the cluster
 	clusterLogsListResponse, err := sendClusterLogsListRequest(ocmClient, cluster, allMessages, internalOnly)
@@ -110,25 +112,72 @@ func FetchServiceLogs(clusterID string, allMessages bool, internalOnly bool) (*v
 	return clusterLogsListResponse, nil
 }
 
-func sendClusterLogsListRequest(ocmClient *sdk.Connection, cluster *cmv1.Cluster, allMessages bool, internalMessages bool) (*v1.ClustersClusterLogsListResponse, error) {
-	request := ocmClient.ServiceLogs().V1().Clusters().ClusterLogs().List().
-		ClusterID(cluster.ID()).
-		ClusterUUID(cluster.ExternalID()).
-		Parameter("orderBy", "timestamp desc")
+// FetchAllServiceLogs returns every service log of the cluster matching the OCM search query,
+// newest first, going through all the pages of the response.
+func FetchAllServiceLogs(clusterID string, searchQuery string) ([]*v1.LogEntry, error) {
+	ocmClient, err := utils.CreateConnection()
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := ocmClient.Close(); err != nil {
+			fmt.Printf("Cannot close the ocmClient (possible memory leak): %q", err)
+		}
+	}()
+
+	cluster, err := getServiceLogCluster(ocmClient, clusterID)
+	if err != nil {
+		return nil, err
+	}
 
-	var searchQuery string
+	var entries []*v1.LogEntry
+	for page := 1; ; page++ {
+		response, err := clusterLogsListRequest(ocmClient, cluster, searchQuery).
+			Size(serviceLogsPageSize).
+			Page(page).
+			Send()
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch service logs for cluster %v: %w", clusterID, err)
+		}
+		entries = append(entries, response.Items().Slice()...)
+		if response.Items().Len() < serviceLogsPageSize || len(entries) >= response.Total() {
+			return entries, nil
+		}
+	}
+}
+
+func getServiceLogCluster(ocmClient *sdk.Connection, clusterID string) (*cmv1.Cluster, error) {
+	// Use the OCM client to retrieve clusters
+	clusters := utils.GetClusters(ocmClient, []string{clusterID})
+	if len(clusters) != 1 {
+		return nil, fmt.Errorf("GetClusters expected to return 1 cluster, got: %d", len(clusters))
+	}
+	return clusters[0], nil
+}
+
+// clusterLogsSearchQuery returns the OCM search query of the SRE (unless allMessages) and internal
+// (if internalMessages) service logs.
+func clusterLogsSearchQuery(allMessages bool, internalMessages bool) string {
+	var clauses []string
 	if !allMessages {
-		searchQuery = "service_name='SREManualAction'"
+		clauses = append(clauses, "service_name='SREManualAction'")
 	}
 	if internalMessages {
-		if searchQuery != "" {
-			searchQuery += " and "
-		}
-		searchQuery += "internal_only='true'"
+		clauses = append(clauses, "internal_only='true'")
 	}
-	request.Search(searchQuery)
+	return strings.Join(clauses, " and ")
+}
 
-	response, err := request.Send()
+func clusterLogsListRequest(ocmClient *sdk.Connection, cluster *cmv1.Cluster, searchQuery string) *v1.ClustersClusterLogsListRequest {
+	return ocmClient.ServiceLogs().V1().Clusters().ClusterLogs().List().
+		ClusterID(cluster.ID()).
+		ClusterUUID(cluster.ExternalID()).
+		Parameter("orderBy", "timestamp desc").
+		Search(searchQuery)
+}
+
+func sendClusterLogsListRequest(ocmClient *sdk.Connection, cluster *cmv1.Cluster, allMessages bool, internalMessages bool) (*v1.ClustersClusterLogsListResponse, error) {
+	response, err := clusterLogsListRequest(ocmClient, cluster, clusterLogsSearchQuery(allMessages, internalMessages)).Send()
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch service logs: %w", err)
 	}
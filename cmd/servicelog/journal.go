@@ -0,0 +1,116 @@
+package servicelog
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+const (
+	journalStatusSuccess = "success"
+	journalStatusFailure = "failure"
+)
+
+// JournalEntry is the outcome of posting a service log to a single cluster.
+type JournalEntry struct {
+	Timestamp  time.Time `json:"timestamp"`
+	ClusterID  string    `json:"cluster_id"`
+	ExternalID string    `json:"external_id"`
+	Status     string    `json:"status"`
+	Message    string    `json:"message"`
+}
+
+// Journal records the outcome of a bulk post on disk, one JSON document per line,
+// so that an interrupted post can be resumed with --resume.
+type Journal struct {
+	mu   sync.Mutex
+	path string
+	file *os.File
+}
+
+// OpenJournal opens the journal at path for appending, creating it if needed.
+func OpenJournal(path string) (*Journal, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+		return nil, err
+	}
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600) //#nosec G304 -- path is provided by the user
+	if err != nil {
+		return nil, fmt.Errorf("cannot open journal %s: %w", path, err)
+	}
+	return &Journal{path: path, file: file}, nil
+}
+
+// defaultJournalPath returns a new journal path in the osdctl cache directory.
+func defaultJournalPath(now time.Time) (string, error) {
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(cacheDir, "osdctl", "servicelog", fmt.Sprintf("post-%s.jsonl", now.UTC().Format("20060102T150405Z"))), nil
+}
+
+// Path returns the location of the journal.
+func (j *Journal) Path() string {
+	return j.path
+}
+
+// Record appends an entry to the journal and syncs it to disk.
+func (j *Journal) Record(entry JournalEntry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if _, err := j.file.Write(append(data, '\n')); err != nil {
+		return err
+	}
+	return j.file.Sync()
+}
+
+// Close closes the journal file.
+func (j *Journal) Close() error {
+	return j.file.Close()
+}
+
+// ReadJournalSucceeded returns the internal and external IDs of the clusters whose
+// most recent entry in the journal is a success.
+func ReadJournalSucceeded(path string) (map[string]bool, error) {
+	file, err := os.Open(path) //#nosec G304 -- path is provided by the user
+	if err != nil {
+		return nil, fmt.Errorf("cannot open journal %s: %w", path, err)
+	}
+	defer file.Close()
+
+	succeeded := map[string]bool{}
+	scanner := bufio.NewScanner(file)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var entry JournalEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return nil, fmt.Errorf("invalid journal entry at %s:%d: %w", path, line, err)
+		}
+		for _, id := range []string{entry.ClusterID, entry.ExternalID} {
+			if id != "" {
+				succeeded[id] = entry.Status == journalStatusSuccess
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	for id, ok := range succeeded {
+		if !ok {
+			delete(succeeded, id)
+		}
+	}
+	return succeeded, nil
+}
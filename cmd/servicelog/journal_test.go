@@ -0,0 +1,148 @@
+package servicelog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	v1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJournalResume(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "journal", "post.jsonl")
+
+	journal, err := OpenJournal(path)
+	assert.NoError(t, err)
+	now := time.Now().UTC()
+	assert.NoError(t, journal.Record(JournalEntry{Timestamp: now, ClusterID: "id-1", ExternalID: "uuid-1", Status: journalStatusSuccess}))
+	assert.NoError(t, journal.Record(JournalEntry{Timestamp: now, ClusterID: "id-2", ExternalID: "uuid-2", Status: journalStatusFailure, Message: "boom"}))
+	assert.NoError(t, journal.Record(JournalEntry{Timestamp: now, ClusterID: "id-3", ExternalID: "uuid-3", Status: journalStatusFailure, Message: "boom"}))
+	assert.NoError(t, journal.Close())
+
+	// A resumed post appends to the same journal, the latest entry of a cluster wins.
+	journal, err = OpenJournal(path)
+	assert.NoError(t, err)
+	assert.NoError(t, journal.Record(JournalEntry{Timestamp: now, ClusterID: "id-3", ExternalID: "uuid-3", Status: journalStatusSuccess}))
+	assert.NoError(t, journal.Close())
+
+	succeeded, err := ReadJournalSucceeded(path)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]bool{"id-1": true, "uuid-1": true, "id-3": true, "uuid-3": true}, succeeded)
+
+	var clusters []*v1.Cluster
+	for i := 1; i <= 3; i++ {
+		cluster, _ := v1.NewCluster().ID(fmt.Sprintf("id-%d", i)).ExternalID(fmt.Sprintf("uuid-%d", i)).Build()
+		clusters = append(clusters, cluster)
+	}
+	remaining := skipSucceededClusters(clusters, succeeded)
+	assert.Len(t, remaining, 1)
+	assert.Equal(t, "id-2", remaining[0].ID())
+}
+
+func TestReadJournalSucceededInvalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "post.jsonl")
+	assert.NoError(t, os.WriteFile(path, []byte("not json\n"), 0600))
+
+	_, err := ReadJournalSucceeded(path)
+	assert.Error(t, err)
+
+	_, err = ReadJournalSucceeded(filepath.Join(t.TempDir(), "missing.jsonl"))
+	assert.Error(t, err)
+}
+
+func TestPostConcurrently(t *testing.T) {
+	var clusters []*v1.Cluster
+	for i := 0; i < 20; i++ {
+		cluster, _ := v1.NewCluster().ID(fmt.Sprintf("id-%d", i)).ExternalID(fmt.Sprintf("uuid-%d", i)).Build()
+		clusters = append(clusters, cluster)
+	}
+
+	var inFlight, maxInFlight int32
+	options := &PostCmdOptions{
+		Workers:            4,
+		successfulClusters: map[string]string{},
+		failedClusters:     map[string]string{},
+	}
+	results := options.postConcurrently(context.Background(), clusters, func(cluster *v1.Cluster) error {
+		current := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		for {
+			seen := atomic.LoadInt32(&maxInFlight)
+			if current <= seen || atomic.CompareAndSwapInt32(&maxInFlight, seen, current) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+		if cluster.ID() == "id-3" {
+			return errors.New("boom")
+		}
+		return nil
+	})
+
+	var posted []string
+	for result := range results {
+		posted = append(posted, result.cluster.ID())
+		options.recordResult(result.cluster, result.err)
+	}
+
+	assert.Len(t, posted, 20)
+	assert.LessOrEqual(t, maxInFlight, int32(4))
+	assert.Len(t, options.successfulClusters, 19)
+	assert.Equal(t, "boom", options.failedClusters["uuid-3"])
+}
+
+func TestPostConcurrentlyInterrupted(t *testing.T) {
+	var clusters []*v1.Cluster
+	for i := 0; i < 20; i++ {
+		cluster, _ := v1.NewCluster().ID(fmt.Sprintf("id-%d", i)).ExternalID(fmt.Sprintf("uuid-%d", i)).Build()
+		clusters = append(clusters, cluster)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var started int32
+	options := &PostCmdOptions{
+		Workers:            2,
+		successfulClusters: map[string]string{},
+		failedClusters:     map[string]string{},
+	}
+	results := options.postConcurrently(ctx, clusters, func(cluster *v1.Cluster) error {
+		// Interrupt while the first posts are in flight
+		if atomic.AddInt32(&started, 1) == 2 {
+			cancel()
+		}
+		time.Sleep(10 * time.Millisecond)
+		return nil
+	})
+
+	for result := range results {
+		options.recordResult(result.cluster, result.err)
+	}
+	options.cleanUp(clusters)
+
+	// Every post started is recorded as successful, the rest as interrupted
+	assert.Len(t, options.successfulClusters, int(atomic.LoadInt32(&started)))
+	assert.Less(t, len(options.successfulClusters), 20)
+	assert.Len(t, options.failedClusters, 20-len(options.successfulClusters))
+}
+
+func TestPostConcurrentlyRateLimit(t *testing.T) {
+	var clusters []*v1.Cluster
+	for i := 0; i < 5; i++ {
+		cluster, _ := v1.NewCluster().ID(fmt.Sprintf("id-%d", i)).Build()
+		clusters = append(clusters, cluster)
+	}
+
+	options := &PostCmdOptions{Workers: 5, RateLimit: 50}
+	start := time.Now()
+	for range options.postConcurrently(context.Background(), clusters, func(*v1.Cluster) error { return nil }) {
+	}
+	// The first post is immediate, the 4 others are spaced by 20ms.
+	assert.GreaterOrEqual(t, time.Since(start), 70*time.Millisecond)
+}
@@ -1,22 +1,58 @@
 package servicelog
 
 import (
+	"encoding/csv"
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
+	"regexp"
 	"slices"
+	"strconv"
+	"strings"
 	"time"
 
 	slv1 "github.com/openshift-online/ocm-sdk-go/servicelogs/v1"
 
 	"github.com/openshift-online/ocm-cli/pkg/dump"
+	"github.com/openshift/osdctl/pkg/printer"
 	"github.com/spf13/cobra"
 )
 
+const (
+	listOutputJSON  = "json"
+	listOutputTable = "table"
+	listOutputCSV   = "csv"
+
+	visibilityAll      = "all"
+	visibilityInternal = "internal"
+	visibilityCustomer = "customer"
+)
+
 type listCmdOptions struct {
 	allMessages bool
 	internal    bool
 	clusterID   string
+
+	severities   []string
+	serviceNames []string
+	since        string
+	until        string
+	contains     string
+	regex        string
+	visibility   string
+	output       string
+}
+
+// logEntryFilter selects the service logs to print.
+type logEntryFilter struct {
+	severities   []string
+	serviceNames []string
+	since        time.Time
+	until        time.Time
+	contains     string
+	regex        *regexp.Regexp
+	visibility   string
 }
 
 func newListCmd() *cobra.Command {
@@ -33,6 +69,12 @@ osdctl servicelog list --cluster-id=my-cluster-id --all-messages
 
 # To return all service logs, as well as internal service logs
 osdctl servicelog list --cluster-id=my-cluster-id --all-messages --internal
+
+# To return the customer visible warnings and errors of the last 30 days as a table
+osdctl servicelog list --cluster-id=my-cluster-id -A --severity Warning,Error --since 720h --visibility customer -o table
+
+# To export the service logs of 2024 mentioning an upgrade as CSV
+osdctl servicelog list --cluster-id=my-cluster-id -A --since 2024-01-01 --until 2025-01-01 --contains upgrade -o csv
 `,
 		Short: "Get service logs for a given cluster identifier.",
 		Args:  cobra.NoArgs,
@@ -42,35 +84,202 @@ osdctl servicelog list --cluster-id=my-cluster-id --all-messages --internal
 	}
 
 	cmd.Flags().BoolVarP(&opts.allMessages, "all-messages", "A", false, "Toggle if we should see all of the messages or only SRE-P specific ones")
-	cmd.Flags().BoolVarP(&opts.internal, "internal", "i", false, "Toggle if we should see internal messages")
+	cmd.Flags().BoolVarP(&opts.internal, "internal", "i", false, "Toggle if we should see internal messages, same as --visibility internal")
 	cmd.Flags().StringVarP(&opts.clusterID, "cluster-id", "C", "", "Internal Cluster identifier (required)")
+	cmd.Flags().StringSliceVar(&opts.severities, "severity", nil, "Only show service logs of these severities (i.e. Info,Warning,Error)")
+	cmd.Flags().StringSliceVar(&opts.serviceNames, "service-name", nil, "Only show service logs of these service names (i.e. SREManualAction), replaces the SREManualAction default without --all-messages")
+	cmd.Flags().StringVar(&opts.since, "since", "", "Only show service logs created after this time. Either a duration (i.e. 72h) or a date (2006-01-02 or RFC3339)")
+	cmd.Flags().StringVar(&opts.until, "until", "", "Only show service logs created before this time. Either a duration (i.e. 24h) or a date (2006-01-02 or RFC3339)")
+	cmd.Flags().StringVar(&opts.contains, "contains", "", "Only show service logs whose summary or description contains this text (case insensitive)")
+	cmd.Flags().StringVar(&opts.regex, "regex", "", "Only show service logs whose summary or description matches this regular expression")
+	cmd.Flags().StringVar(&opts.visibility, "visibility", visibilityAll, "Only show internal or customer visible service logs. One of: all, internal, customer")
+	cmd.Flags().StringVarP(&opts.output, "output", "o", listOutputJSON, "Output format. One of: json, table, csv")
 	_ = cmd.MarkFlagRequired("cluster-id")
 
 	return cmd
 }
 
 func listServiceLogs(clusterID string, opts *listCmdOptions) error {
-	response, err := FetchServiceLogs(clusterID, opts.allMessages, opts.internal)
+	filter, err := opts.filter(time.Now().UTC())
+	if err != nil {
+		return err
+	}
+	if !slices.Contains([]string{listOutputJSON, listOutputTable, listOutputCSV}, opts.output) {
+		return fmt.Errorf("invalid output format: %s (allowed: json, table, csv)", opts.output)
+	}
+
+	// Only the text filters are applied after fetching, so every page has to be fetched
+	entries, err := FetchAllServiceLogs(clusterID, filter.searchQuery(opts.allMessages))
 	if err != nil {
 		return fmt.Errorf("failed to fetch service logs: %w", err)
 	}
 
-	if err = printServiceLogResponse(response); err != nil {
+	if err = printServiceLogEntries(os.Stdout, entries, filter, opts.output); err != nil {
 		return fmt.Errorf("failed to print service logs: %w", err)
 	}
 
 	return nil
 }
 
-func printServiceLogResponse(response *slv1.ClustersClusterLogsListResponse) error {
-	entryViews := logEntryToView(response.Items().Slice())
+// filter validates the filter flags.
+func (o *listCmdOptions) filter(now time.Time) (logEntryFilter, error) {
+	filter := logEntryFilter{
+		serviceNames: o.serviceNames,
+		contains:     strings.ToLower(o.contains),
+		visibility:   o.visibility,
+	}
+
+	// The severities are matched by the OCM search query, which is case sensitive
+	for _, severity := range o.severities {
+		i := slices.IndexFunc(validSeverities, func(s string) bool { return strings.EqualFold(s, severity) })
+		if i < 0 {
+			return filter, fmt.Errorf("invalid --severity %q (allowed: %s)", severity, strings.Join(validSeverities, ", "))
+		}
+		filter.severities = append(filter.severities, validSeverities[i])
+	}
+
+	var err error
+	if filter.since, err = parseListTime(o.since, now); err != nil {
+		return filter, fmt.Errorf("invalid --since: %w", err)
+	}
+	if filter.until, err = parseListTime(o.until, now); err != nil {
+		return filter, fmt.Errorf("invalid --until: %w", err)
+	}
+	if !filter.since.IsZero() && !filter.until.IsZero() && !filter.since.Before(filter.until) {
+		return filter, fmt.Errorf("--since must be before --until")
+	}
+
+	if o.regex != "" {
+		if filter.regex, err = regexp.Compile(o.regex); err != nil {
+			return filter, fmt.Errorf("invalid --regex: %w", err)
+		}
+	}
+
+	switch o.visibility {
+	case "", visibilityAll, visibilityInternal, visibilityCustomer:
+	default:
+		return filter, fmt.Errorf("invalid --visibility %q (allowed: all, internal, customer)", o.visibility)
+	}
+	if o.internal {
+		if o.visibility == visibilityCustomer {
+			return filter, fmt.Errorf("--internal only shows internal service logs and cannot be used with --visibility %s", visibilityCustomer)
+		}
+		filter.visibility = visibilityInternal
+	}
+
+	return filter, nil
+}
+
+// searchQuery returns the OCM search query of the service logs selected by the filter, apart from
+// the text filters. Without service names, only SREManualAction service logs are selected unless allMessages.
+func (f logEntryFilter) searchQuery(allMessages bool) string {
+	var clauses []string
+	if len(f.serviceNames) > 0 {
+		clauses = append(clauses, fmt.Sprintf("service_name in (%s)", searchValues(f.serviceNames)))
+	} else if !allMessages {
+		clauses = append(clauses, "service_name='SREManualAction'")
+	}
+	if len(f.severities) > 0 {
+		clauses = append(clauses, fmt.Sprintf("severity in (%s)", searchValues(f.severities)))
+	}
+	if !f.since.IsZero() {
+		clauses = append(clauses, fmt.Sprintf("created_at >= '%s'", f.since.UTC().Format(time.RFC3339)))
+	}
+	if !f.until.IsZero() {
+		clauses = append(clauses, fmt.Sprintf("created_at < '%s'", f.until.UTC().Format(time.RFC3339)))
+	}
+	switch f.visibility {
+	case visibilityInternal:
+		clauses = append(clauses, "internal_only='true'")
+	case visibilityCustomer:
+		clauses = append(clauses, "internal_only='false'")
+	}
+	return strings.Join(clauses, " and ")
+}
+
+// searchValues quotes the values for an OCM search query "in" clause.
+func searchValues(values []string) string {
+	quoted := make([]string, 0, len(values))
+	for _, value := range values {
+		quoted = append(quoted, "'"+strings.ReplaceAll(value, "'", "''")+"'")
+	}
+	return strings.Join(quoted, ", ")
+}
+
+// parseListTime parses a duration before now, or a date in the 2006-01-02 or RFC3339 format.
+func parseListTime(value string, now time.Time) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if duration, err := time.ParseDuration(value); err == nil {
+		return now.Add(-duration), nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse(time.DateOnly, value); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("%q is neither a duration nor a date (2006-01-02 or RFC3339)", value)
+}
+
+// matches reports whether the service log is selected by the filter.
+func (f logEntryFilter) matches(entry *LogEntryView) bool {
+	if len(f.severities) > 0 && !slices.ContainsFunc(f.severities, func(s string) bool { return strings.EqualFold(s, entry.Severity) }) {
+		return false
+	}
+	if len(f.serviceNames) > 0 && !slices.ContainsFunc(f.serviceNames, func(s string) bool { return strings.EqualFold(s, entry.ServiceName) }) {
+		return false
+	}
+	if !f.since.IsZero() && entry.CreatedAt.Before(f.since) {
+		return false
+	}
+	if !f.until.IsZero() && !entry.CreatedAt.Before(f.until) {
+		return false
+	}
+	if f.contains != "" && !strings.Contains(strings.ToLower(entry.Summary+"\n"+entry.Description), f.contains) {
+		return false
+	}
+	if f.regex != nil && !f.regex.MatchString(entry.Summary) && !f.regex.MatchString(entry.Description) {
+		return false
+	}
+	switch f.visibility {
+	case visibilityInternal:
+		return entry.InternalOnly
+	case visibilityCustomer:
+		return !entry.InternalOnly
+	}
+	return true
+}
+
+// filterLogEntries returns the service logs selected by the filter.
+func filterLogEntries(entries []*LogEntryView, filter logEntryFilter) []*LogEntryView {
+	filtered := make([]*LogEntryView, 0, len(entries))
+	for _, entry := range entries {
+		if filter.matches(entry) {
+			filtered = append(filtered, entry)
+		}
+	}
+	return filtered
+}
+
+func printServiceLogEntries(out io.Writer, entries []*slv1.LogEntry, filter logEntryFilter, output string) error {
+	entryViews := filterLogEntries(logEntryToView(entries), filter)
 	slices.Reverse(entryViews)
+
+	switch output {
+	case listOutputTable:
+		return printLogEntryTable(out, entryViews)
+	case listOutputCSV:
+		return printLogEntryCSV(out, entryViews)
+	}
+
 	view := LogEntryResponseView{
 		Items: entryViews,
 		Kind:  "ClusterLogList",
-		Page:  response.Page(),
-		Size:  response.Size(),
-		Total: response.Total(),
+		Page:  1,
+		Size:  len(entryViews),
+		Total: len(entryViews),
 	}
 
 	viewBytes, err := json.Marshal(view)
@@ -78,7 +287,43 @@ func printServiceLogResponse(response *slv1.ClustersClusterLogsListResponse) err
 		return fmt.Errorf("failed to marshal response for output: %w", err)
 	}
 
-	return dump.Pretty(os.Stdout, viewBytes)
+	return dump.Pretty(out, viewBytes)
+}
+
+// printLogEntryTable prints one line per service log, the description is omitted.
+func printLogEntryTable(out io.Writer, entries []*LogEntryView) error {
+	table := printer.NewTablePrinter(out, 20, 1, 3, ' ')
+	table.AddRow([]string{"CREATED", "SEVERITY", "SERVICE", "INTERNAL", "USERNAME", "SUMMARY"})
+	for _, entry := range entries {
+		table.AddRow([]string{
+			entry.CreatedAt.UTC().Format(time.RFC3339),
+			entry.Severity,
+			entry.ServiceName,
+			strconv.FormatBool(entry.InternalOnly),
+			entry.Username,
+			entry.Summary,
+		})
+	}
+	return table.Flush()
+}
+
+func printLogEntryCSV(out io.Writer, entries []*LogEntryView) error {
+	writer := csv.NewWriter(out)
+	_ = writer.Write([]string{"id", "created_at", "severity", "service_name", "internal_only", "username", "summary", "description"})
+	for _, entry := range entries {
+		_ = writer.Write([]string{
+			entry.ID,
+			entry.CreatedAt.UTC().Format(time.RFC3339),
+			entry.Severity,
+			entry.ServiceName,
+			strconv.FormatBool(entry.InternalOnly),
+			entry.Username,
+			entry.Summary,
+			entry.Description,
+		})
+	}
+	writer.Flush()
+	return writer.Error()
 }
 
 type LogEntryResponseView struct {
@@ -0,0 +1,143 @@
+package servicelog
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	slv1 "github.com/openshift-online/ocm-sdk-go/servicelogs/v1"
+	"github.com/stretchr/testify/assert"
+)
+
+func testLogEntries(now time.Time) []*LogEntryView {
+	return []*LogEntryView{
+		{ID: "1", CreatedAt: now.Add(-48 * time.Hour), Severity: "Info", ServiceName: "SREManualAction", Summary: "Upgrade scheduled", Description: "The cluster will be upgraded"},
+		{ID: "2", CreatedAt: now.Add(-2 * time.Hour), Severity: "Warning", ServiceName: "SREManualAction", Summary: "Action required", Description: "Egress is blocked, see https://docs.openshift.com", InternalOnly: true},
+		{ID: "3", CreatedAt: now.Add(-time.Hour), Severity: "Error", ServiceName: "OCM", Summary: "Cluster unreachable", Description: "Contact support"},
+	}
+}
+
+func filteredIDs(entries []*LogEntryView) []string {
+	ids := []string{}
+	for _, entry := range entries {
+		ids = append(ids, entry.ID)
+	}
+	return ids
+}
+
+func TestFilterLogEntries(t *testing.T) {
+	now := time.Date(2025, 7, 15, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		opts     listCmdOptions
+		expected []string
+		wantErr  bool
+	}{
+		{name: "no filter", opts: listCmdOptions{}, expected: []string{"1", "2", "3"}},
+		{name: "severity", opts: listCmdOptions{severities: []string{"warning", "error"}}, expected: []string{"2", "3"}},
+		{name: "service name", opts: listCmdOptions{serviceNames: []string{"OCM"}}, expected: []string{"3"}},
+		{name: "since duration", opts: listCmdOptions{since: "3h"}, expected: []string{"2", "3"}},
+		{name: "date range", opts: listCmdOptions{since: "2025-07-13", until: "2025-07-15T10:30:00Z"}, expected: []string{"1", "2"}},
+		{name: "contains", opts: listCmdOptions{contains: "UPGRADE"}, expected: []string{"1"}},
+		{name: "regex", opts: listCmdOptions{regex: `(?i)egress|support`}, expected: []string{"2", "3"}},
+		{name: "internal", opts: listCmdOptions{visibility: visibilityInternal}, expected: []string{"2"}},
+		{name: "customer", opts: listCmdOptions{visibility: visibilityCustomer}, expected: []string{"1", "3"}},
+		{name: "invalid regex", opts: listCmdOptions{regex: "("}, wantErr: true},
+		{name: "invalid since", opts: listCmdOptions{since: "last week"}, wantErr: true},
+		{name: "since after until", opts: listCmdOptions{since: "1h", until: "2h"}, wantErr: true},
+		{name: "internal flag", opts: listCmdOptions{internal: true}, expected: []string{"2"}},
+		{name: "invalid visibility", opts: listCmdOptions{visibility: "public"}, wantErr: true},
+		{name: "invalid severity", opts: listCmdOptions{severities: []string{"Critical"}}, wantErr: true},
+		{name: "internal flag with customer visibility", opts: listCmdOptions{internal: true, visibility: visibilityCustomer}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter, err := tt.opts.filter(now)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, filteredIDs(filterLogEntries(testLogEntries(now), filter)))
+		})
+	}
+}
+
+func TestPrintLogEntries(t *testing.T) {
+	now := time.Date(2025, 7, 15, 12, 0, 0, 0, time.UTC)
+
+	var out bytes.Buffer
+	assert.NoError(t, printLogEntryTable(&out, testLogEntries(now)))
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	assert.Len(t, lines, 4)
+	assert.Contains(t, lines[0], "SEVERITY")
+	assert.Contains(t, lines[2], "Action required")
+	assert.NotContains(t, out.String(), "Egress is blocked")
+
+	out.Reset()
+	assert.NoError(t, printLogEntryCSV(&out, testLogEntries(now)))
+	lines = strings.Split(strings.TrimSpace(out.String()), "\n")
+	assert.Len(t, lines, 4)
+	assert.Equal(t, "id,created_at,severity,service_name,internal_only,username,summary,description", lines[0])
+	assert.Equal(t, "2,2025-07-15T10:00:00Z,Warning,SREManualAction,true,,Action required,\"Egress is blocked, see https://docs.openshift.com\"", lines[2])
+}
+
+func TestLogEntryFilterSearchQuery(t *testing.T) {
+	now := time.Date(2025, 7, 15, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		opts        listCmdOptions
+		allMessages bool
+		expected    string
+	}{
+		{name: "SRE service logs by default", expected: "service_name='SREManualAction'"},
+		{name: "all messages", allMessages: true, expected: ""},
+		{
+			name:     "service names replace the default",
+			opts:     listCmdOptions{serviceNames: []string{"Foo", "O'Brien"}},
+			expected: "service_name in ('Foo', 'O''Brien')",
+		},
+		{
+			name:        "severities, time range and visibility",
+			opts:        listCmdOptions{severities: []string{"warning", "Error"}, since: "24h", until: "2025-07-15", visibility: visibilityCustomer},
+			allMessages: true,
+			expected:    "severity in ('Warning', 'Error') and created_at >= '2025-07-14T12:00:00Z' and created_at < '2025-07-15T00:00:00Z' and internal_only='false'",
+		},
+		{
+			name:     "internal flag",
+			opts:     listCmdOptions{internal: true, contains: "upgrade"},
+			expected: "service_name='SREManualAction' and internal_only='true'",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter, err := tt.opts.filter(now)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, filter.searchQuery(tt.allMessages))
+		})
+	}
+}
+
+func TestPrintServiceLogEntriesJSONTotal(t *testing.T) {
+	var entries []*slv1.LogEntry
+	for _, summary := range []string{"Upgrade scheduled", "Cluster unreachable", "Upgrade completed"} {
+		entry, err := slv1.NewLogEntry().Summary(summary).Build()
+		assert.NoError(t, err)
+		entries = append(entries, entry)
+	}
+
+	var out bytes.Buffer
+	assert.NoError(t, printServiceLogEntries(&out, entries, logEntryFilter{contains: "upgrade"}, listOutputJSON))
+
+	var view LogEntryResponseView
+	assert.NoError(t, json.Unmarshal(out.Bytes(), &view))
+	assert.Len(t, view.Items, 2)
+	assert.Equal(t, 2, view.Size)
+	assert.Equal(t, 2, view.Total)
+}
@@ -1,6 +1,7 @@
 package servicelog
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -12,8 +13,10 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
+	"golang.org/x/time/rate"
 	"k8s.io/utils/strings/slices"
 
 	"github.com/openshift-online/ocm-cli/pkg/arguments"
@@ -46,6 +49,12 @@ type PostCmdOptions struct {
 	InternalOnly    bool
 	ClusterId       string
 	SkipLinkCheck   bool
+	Workers         int
+	RateLimit       float64
+	JournalPath     string
+	Resume          string
+
+	journal *Journal
 
 	// Messaged clusters
 	successfulClusters map[string]string
@@ -78,6 +87,12 @@ func newPostCmd() *cobra.Command {
   # Post a service log to a group of clusters, determined by an OCM query
   ocm list cluster -p search="cloud_provider.id is 'gcp' and managed='true' and state is 'ready'"
   osdctl servicelog post -q "cloud_provider.id is 'gcp' and managed='true' and state is 'ready'" -t file.json
+
+  # Post to a fleet with 10 workers and at most 5 service logs per second, recording the results in a journal
+  osdctl servicelog post -c clusters.json -t file.json --workers 10 --rate-limit 5 --journal ~/fleet-notification.jsonl
+
+  # Resume an interrupted post, skipping the clusters that already received the service log
+  osdctl servicelog post -c clusters.json -t file.json --resume ~/fleet-notification.jsonl
 `,
 		Args: cobra.NoArgs,
 		RunE: func(cmd *cobra.Command, args []string) error {
@@ -97,6 +112,10 @@ func newPostCmd() *cobra.Command {
 	postCmd.Flags().StringVarP(&opts.clustersFile, "clusters-file", "c", "", `Read a list of clusters to post the servicelog to. the format of the file is: {"clusters":["$CLUSTERID"]}`)
 	postCmd.Flags().BoolVarP(&opts.InternalOnly, "internal", "i", false, "Internal only service log. Use MESSAGE for template parameter (eg. -p MESSAGE='My super secret message').")
 	postCmd.Flags().BoolVar(&opts.SkipLinkCheck, "skip-link-check", false, "Skip validating if links in Service Log are valid")
+	postCmd.Flags().IntVar(&opts.Workers, "workers", 1, "Number of clusters to post the service log to concurrently, 0 is the same as 1")
+	postCmd.Flags().Float64Var(&opts.RateLimit, "rate-limit", 10, "Maximum number of service logs posted per second, 0 disables the limit")
+	postCmd.Flags().StringVar(&opts.JournalPath, "journal", "", "File to record the outcome for each cluster in. Defaults to a new file in the osdctl cache directory when posting to more than one cluster")
+	postCmd.Flags().StringVar(&opts.Resume, "resume", "", "Journal of a previous post. Clusters that already received the service log are skipped and new results are appended to it")
 
 	return postCmd
 }
@@ -113,6 +132,12 @@ func (o *PostCmdOptions) Validate() error {
 	if o.ClusterId == "" && len(o.filterParams) == 0 && o.clustersFile == "" && len(o.filterFiles) == 0 {
 		return fmt.Errorf("no cluster identifier has been found, please specify --cluster-id, -q, -c or -f")
 	}
+	if o.Workers < 0 {
+		return fmt.Errorf("--workers cannot be negative")
+	}
+	if o.RateLimit < 0 {
+		return fmt.Errorf("--rate-limit must not be negative")
+	}
 	return nil
 }
 
@@ -220,6 +245,18 @@ func (o *PostCmdOptions) Run() error {
 		return fmt.Errorf("no clusters match the given filters (%v)", o.filterParams)
 	}
 
+	if o.Resume != "" {
+		succeeded, err := ReadJournalSucceeded(o.Resume)
+		if err != nil {
+			return err
+		}
+		clusters = skipSucceededClusters(clusters, succeeded)
+		if len(clusters) == 0 {
+			log.Infof("All matching clusters already received the service log according to %s", o.Resume)
+			return nil
+		}
+	}
+
 	log.Infoln("The following clusters match the given parameters:")
 	if err := o.printClusters(clusters); err != nil {
 		return fmt.Errorf("could not print matching clusters: %v", err)
@@ -265,28 +302,12 @@ func (o *PostCmdOptions) Run() error {
 		}
 	}
 
-	// Handler if the program terminates abruptly
-	go func() {
-		sigchan := make(chan os.Signal, 1)
-		signal.Notify(sigchan, os.Interrupt)
-		<-sigchan
-
-		// perform final cleanup actions
-		log.Error("program abruptly terminated, performing clean-up...")
-		o.cleanUp(clusters)
-		log.Fatal("servicelog post command terminated")
-	}()
-
 	// cluster type for which documentation link is provided in servicelog description
 	docClusterType := getDocClusterType(o.Message.Description)
 
+	// Confirm documentation mismatches up front, the clusters are posted to concurrently
+	var targets []*v1.Cluster
 	for _, cluster := range clusters {
-		request, err := o.createPostRequest(ocmClient, cluster)
-		if err != nil {
-			o.failedClusters[cluster.ExternalID()] = err.Error()
-			continue
-		}
-
 		// if servicelog description contains a documentation link, verify that
 		// documentation link matches the cluster product (rosa, dedicated)
 		if !o.skipPrompts && docClusterType != "" {
@@ -300,20 +321,168 @@ func (o *PostCmdOptions) Run() error {
 				}
 			}
 		}
+		targets = append(targets, cluster)
+	}
 
-		response, err := ocmutils.SendRequest(request)
-		if err != nil {
-			o.failedClusters[cluster.ExternalID()] = err.Error()
-			continue
-		}
+	if err := o.openJournal(len(targets)); err != nil {
+		return err
+	}
+	if o.journal != nil {
+		defer o.journal.Close()
+		log.Infof("Recording the results in %s, use '--resume %s' to resume an interrupted post", o.journal.Path(), o.journal.Path())
+	}
+
+	// Stop handing out clusters on interruption, the posts in flight still complete and are recorded
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
 
-		o.check(response, o.Message)
+	for result := range o.postConcurrently(ctx, targets, func(cluster *v1.Cluster) error {
+		return o.postToCluster(ocmClient, cluster)
+	}) {
+		o.recordResult(result.cluster, result.err)
+	}
+
+	if ctx.Err() != nil {
+		log.Error("program interrupted, the clusters not posted to yet are skipped")
+		o.cleanUp(targets)
+		return errors.New("servicelog post command interrupted")
 	}
 
 	o.printPostOutput()
 	return nil
 }
 
+// postResult is the outcome of posting the service log to a cluster.
+type postResult struct {
+	cluster *v1.Cluster
+	err     error
+}
+
+// postConcurrently calls post for every cluster from a pool of o.Workers workers (at least one),
+// limited to o.RateLimit calls per second. The results are sent on the returned
+// channel, which is closed once all clusters have been posted to. Once ctx is done,
+// no new post is started and the channel is closed when the posts in flight complete.
+func (o *PostCmdOptions) postConcurrently(ctx context.Context, clusters []*v1.Cluster, post func(cluster *v1.Cluster) error) <-chan postResult {
+	workers := max(o.Workers, 1)
+	limit := rate.Inf
+	if o.RateLimit > 0 {
+		limit = rate.Limit(o.RateLimit)
+	}
+	limiter := rate.NewLimiter(limit, 1)
+
+	jobs := make(chan *v1.Cluster)
+	results := make(chan postResult)
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for cluster := range jobs {
+				// Only fails when ctx is done, the cluster is left for cleanUp
+				if err := limiter.Wait(ctx); err != nil {
+					continue
+				}
+				results <- postResult{cluster: cluster, err: post(cluster)}
+			}
+		}()
+	}
+
+	go func() {
+	feed:
+		for _, cluster := range clusters {
+			select {
+			case jobs <- cluster:
+			case <-ctx.Done():
+				break feed
+			}
+		}
+		close(jobs)
+		wg.Wait()
+		close(results)
+	}()
+
+	return results
+}
+
+// postToCluster posts the service log to a single cluster and validates the response.
+func (o *PostCmdOptions) postToCluster(ocmClient *sdk.Connection, cluster *v1.Cluster) error {
+	request, message, err := o.createPostRequest(ocmClient, cluster)
+	if err != nil {
+		return err
+	}
+
+	response, err := ocmutils.SendRequest(request)
+	if err != nil {
+		return err
+	}
+
+	return checkResponse(response, message)
+}
+
+// recordResult stores the outcome of posting to a cluster and appends it to the journal, if any.
+func (o *PostCmdOptions) recordResult(cluster *v1.Cluster, postErr error) {
+	entry := JournalEntry{
+		Timestamp:  time.Now().UTC(),
+		ClusterID:  cluster.ID(),
+		ExternalID: cluster.ExternalID(),
+	}
+	if postErr != nil {
+		entry.Status, entry.Message = journalStatusFailure, postErr.Error()
+		o.failedClusters[cluster.ExternalID()] = postErr.Error()
+	} else {
+		entry.Status, entry.Message = journalStatusSuccess, fmt.Sprintf("Message has been successfully sent to %s", cluster.ExternalID())
+		o.successfulClusters[cluster.ExternalID()] = entry.Message
+	}
+
+	if o.journal != nil {
+		if err := o.journal.Record(entry); err != nil {
+			log.Errorf("Cannot record the result of cluster %s in the journal: %v", cluster.ID(), err)
+		}
+	}
+}
+
+// openJournal opens the journal given by --journal or --resume. Posts to more than one
+// cluster are always recorded, in a new journal if none was given.
+func (o *PostCmdOptions) openJournal(clusterCount int) error {
+	path := o.JournalPath
+	if path == "" {
+		path = o.Resume
+	}
+	if path == "" {
+		if clusterCount <= 1 {
+			return nil
+		}
+		var err error
+		if path, err = defaultJournalPath(time.Now()); err != nil {
+			return fmt.Errorf("cannot determine the journal location, use --journal: %w", err)
+		}
+	}
+
+	journal, err := OpenJournal(path)
+	if err != nil {
+		return err
+	}
+	o.journal = journal
+	return nil
+}
+
+// skipSucceededClusters removes the clusters that already received the service log.
+func skipSucceededClusters(clusters []*v1.Cluster, succeeded map[string]bool) []*v1.Cluster {
+	var remaining []*v1.Cluster
+	for _, cluster := range clusters {
+		if succeeded[cluster.ID()] || succeeded[cluster.ExternalID()] {
+			log.Debugf("Skipping cluster %s, it already received the service log", cluster.ID())
+			continue
+		}
+		remaining = append(remaining, cluster)
+	}
+	if skipped := len(clusters) - len(remaining); skipped > 0 {
+		log.Infof("Skipping %d cluster(s) that already received the service log", skipped)
+	}
+	return remaining
+}
+
 // if servicelog description contains documentation link, parse and return the cluster type from the url
 func getDocClusterType(message string) string {
 
@@ -336,23 +505,19 @@ func getDocClusterType(message string) string {
 	return ""
 }
 
-func (o *PostCmdOptions) check(response *sdk.Response, clusterMessage servicelog.Message) {
+// checkResponse returns an error if the service log could not be posted.
+func checkResponse(response *sdk.Response, clusterMessage servicelog.Message) error {
 	body := response.Bytes()
 	if response.Status() < 400 {
 		_, err := validateGoodResponse(body, clusterMessage)
-		if err != nil {
-			o.failedClusters[clusterMessage.ClusterUUID] = err.Error()
-		} else {
-			o.successfulClusters[clusterMessage.ClusterUUID] = fmt.Sprintf("Message has been successfully sent to %s", clusterMessage.ClusterUUID)
-		}
-	} else {
-		badReply, err := validateBadResponse(body)
-		if err != nil {
-			o.failedClusters[clusterMessage.ClusterUUID] = err.Error()
-		} else {
-			o.failedClusters[clusterMessage.ClusterUUID] = badReply.Reason
-		}
+		return err
+	}
+
+	badReply, err := validateBadResponse(body)
+	if err != nil {
+		return err
 	}
+	return errors.New(badReply.Reason)
 }
 
 // parseUserParameters parse all the '-p FOO=BAR' parameters and checks for syntax errors
@@ -601,28 +766,32 @@ func (o *PostCmdOptions) printTemplate() (err error) {
 	return dump.Pretty(os.Stdout, exampleMessage)
 }
 
-func (o *PostCmdOptions) createPostRequest(ocmClient *sdk.Connection, cluster *v1.Cluster) (request *sdk.Request, err error) {
+// createPostRequest creates the request posting the service log to the cluster,
+// along with the message specific to the cluster.
+func (o *PostCmdOptions) createPostRequest(ocmClient *sdk.Connection, cluster *v1.Cluster) (request *sdk.Request, message servicelog.Message, err error) {
 	// Create and populate the request:
 	request = ocmClient.Post()
 	err = arguments.ApplyPathArg(request, targetAPIPath)
 	if err != nil {
-		return nil, fmt.Errorf("cannot parse API path '%s': %v", targetAPIPath, err)
+		return nil, message, fmt.Errorf("cannot parse API path '%s': %v", targetAPIPath, err)
 	}
 
-	o.Message.ClusterUUID = cluster.ExternalID()
-	o.Message.ClusterID = cluster.ID()
-	o.Message.InternalOnly = o.InternalOnly
+	// Requests are created concurrently, every cluster gets its own copy of the message
+	message = o.Message
+	message.ClusterUUID = cluster.ExternalID()
+	message.ClusterID = cluster.ID()
+	message.InternalOnly = o.InternalOnly
 	if subscription := cluster.Subscription(); subscription != nil {
-		o.Message.SubscriptionID = cluster.Subscription().ID()
+		message.SubscriptionID = cluster.Subscription().ID()
 	}
 
-	messageBytes, err := json.Marshal(o.Message)
+	messageBytes, err := json.Marshal(message)
 	if err != nil {
-		return nil, fmt.Errorf("cannot marshal template to json: %v", err)
+		return nil, message, fmt.Errorf("cannot marshal template to json: %v", err)
 	}
 
 	request.Bytes(messageBytes)
-	return request, nil
+	return request, message, nil
 }
 
 // listMessagedClusters prints all the clusters a service log was tried to be posted.
@@ -662,10 +831,13 @@ func (o *PostCmdOptions) printPostOutput() {
 	}
 }
 
-// cleanUp performs final actions in case of program termination.
+// cleanUp performs final actions in case of program termination. It must run after all the
+// results were recorded, the clusters without a result are marked as failed.
 func (o *PostCmdOptions) cleanUp(clusters []*v1.Cluster) {
 	for _, cluster := range clusters {
-		if _, ok := o.successfulClusters[cluster.ExternalID()]; !ok {
+		_, succeeded := o.successfulClusters[cluster.ExternalID()]
+		_, failed := o.failedClusters[cluster.ExternalID()]
+		if !succeeded && !failed {
 			o.failedClusters[cluster.ExternalID()] = "cannot send message due to program interruption"
 		}
 	}
@@ -141,6 +141,16 @@ var _ = Describe("Test posting service logs", func() {
 			Expect(err).ShouldNot(HaveOccurred())
 		})
 
+		It("fails with a negative number of workers", func() {
+			options := &PostCmdOptions{
+				ClusterId: "test-cluster",
+				Workers:   -1,
+			}
+			err := options.Validate()
+			Expect(err).Should(HaveOccurred())
+			Expect(err.Error()).To(Equal("--workers cannot be negative"))
+		})
+
 		It("validates successfully with a filter", func() {
 			options := &PostCmdOptions{
 				filterParams: []string{"cloud_provider.id is 'gcp'"},
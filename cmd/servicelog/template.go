@@ -0,0 +1,454 @@
+package servicelog
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/openshift-online/ocm-cli/pkg/dump"
+	slv1 "github.com/openshift-online/ocm-sdk-go/servicelogs/v1"
+	"github.com/openshift/osdctl/internal/servicelog"
+	"github.com/openshift/osdctl/pkg/link_validator"
+	"github.com/openshift/osdctl/pkg/printer"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"k8s.io/utils/strings/slices"
+)
+
+const (
+	// TemplateDirKey is the osdctl config key of the local checkout of the service log templates.
+	TemplateDirKey = "servicelog_template_dir"
+	// ServiceNamesKey is the osdctl config key of additional service names accepted by 'template lint'.
+	ServiceNamesKey = "servicelog_service_names"
+
+	// maxSummaryLength is the maximum length of a service log summary.
+	maxSummaryLength = 255
+)
+
+// validSeverities are the severities accepted by the service logs API.
+var validSeverities = []string{
+	string(slv1.SeverityDebug),
+	string(slv1.SeverityInfo),
+	string(slv1.SeverityWarning),
+	string(slv1.SeverityError),
+	string(slv1.SeverityFatal),
+}
+
+// defaultServiceNames are the service names of service logs posted by SREs.
+var defaultServiceNames = []string{"SREManualAction"}
+
+type lintLevel string
+
+const (
+	lintError   lintLevel = "error"
+	lintWarning lintLevel = "warning"
+)
+
+// LintFinding is a problem found in a service log template.
+type LintFinding struct {
+	Level   lintLevel
+	Field   string
+	Message string
+}
+
+// lintOptions configures LintMessage.
+type lintOptions struct {
+	serviceNames  []string
+	skipLinkCheck bool
+	// placeholdersAreErrors reports leftover placeholders as errors rather than warnings,
+	// i.e. once all parameters are expected to be set.
+	placeholdersAreErrors bool
+	validateLinks         func(message string) ([]link_validator.ValidationResult, error)
+}
+
+type templateCmdOptions struct {
+	params        []string
+	strict        bool
+	skipLinkCheck bool
+	templateDir   string
+	search        string
+}
+
+func newTemplateCmd() *cobra.Command {
+	opts := &templateCmdOptions{}
+
+	templateCmd := &cobra.Command{
+		Use:   "template",
+		Short: "Lint, render and list service log templates",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			_ = cmd.Help()
+		},
+	}
+
+	lintCmd := &cobra.Command{
+		Use:   "lint <template>...",
+		Short: "Validate service log templates",
+		Long: fmt.Sprintf(`Validate service log templates before posting them.
+
+Templates are local files, directories (all *.json files are linted) or URLs. Each template
+is checked for required fields, a valid severity and service name, leftover placeholders,
+a summary of at most %d characters and dead links.
+
+Leftover placeholders are warnings, unless parameters are given with -p in which case all
+placeholders except ${CLUSTER_UUID} are expected to be set. Additional service names can
+be allowed with '%s' in the osdctl config file.
+
+The command exits with a non-zero status if any template has an error, or a warning with --strict.`, maxSummaryLength, ServiceNamesKey),
+		Example: `
+  # Lint all templates of a local checkout
+  osdctl servicelog template lint ~/git/managed-notifications/osd
+
+  # Lint a template once its parameters are set, failing on warnings
+  osdctl servicelog template lint ~/path/to/file.json -p ALERT_NAME=alert --strict`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return opts.lint(cmd.OutOrStdout(), args)
+		},
+	}
+	lintCmd.Flags().StringArrayVarP(&opts.params, "param", "p", nil, "Specify a key-value pair (eg. -p FOO=BAR) to set a parameter value in the template before linting.")
+	lintCmd.Flags().BoolVar(&opts.strict, "strict", false, "Treat warnings as errors")
+	lintCmd.Flags().BoolVar(&opts.skipLinkCheck, "skip-link-check", false, "Skip validating if links in the templates are valid")
+
+	renderCmd := &cobra.Command{
+		Use:   "render <template>",
+		Short: "Show the service log that would be posted for the given parameters",
+		Example: `
+  osdctl servicelog template render ~/path/to/file.json -p ALERT_NAME=alert`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return opts.render(cmd.OutOrStdout(), args[0])
+		},
+	}
+	renderCmd.Flags().StringArrayVarP(&opts.params, "param", "p", nil, "Specify a key-value pair (eg. -p FOO=BAR) to set a parameter value in the template.")
+
+	listCmd := &cobra.Command{
+		Use:   "list",
+		Short: "List the templates of a local template checkout",
+		Example: fmt.Sprintf(`
+  # List the templates mentioning egress, the directory can be set with '%s' in the osdctl config
+  osdctl servicelog template list --dir ~/git/managed-notifications --search egress`, TemplateDirKey),
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return opts.list(cmd.OutOrStdout())
+		},
+	}
+	listCmd.Flags().StringVar(&opts.templateDir, "dir", "", fmt.Sprintf("Local checkout of the template directory. Defaults to '%s' from the osdctl config", TemplateDirKey))
+	listCmd.Flags().StringVar(&opts.search, "search", "", "Only list templates whose path, summary or description contains this text (case insensitive)")
+
+	templateCmd.AddCommand(lintCmd, renderCmd, listCmd)
+	return templateCmd
+}
+
+func (o *templateCmdOptions) lint(out io.Writer, args []string) error {
+	params, err := parseTemplateParams(o.params)
+	if err != nil {
+		return err
+	}
+
+	paths, err := expandTemplatePaths(args)
+	if err != nil {
+		return err
+	}
+
+	lv := link_validator.NewLinkValidator()
+	lintOpts := lintOptions{
+		serviceNames:          append(append([]string{}, defaultServiceNames...), viper.GetStringSlice(ServiceNamesKey)...),
+		skipLinkCheck:         o.skipLinkCheck,
+		placeholdersAreErrors: len(params) > 0,
+		validateLinks:         lv.ValidateLinks,
+	}
+
+	failed := 0
+	for _, path := range paths {
+		findings := lintTemplate(path, params, lintOpts)
+		for _, finding := range findings {
+			field := ""
+			if finding.Field != "" {
+				field = finding.Field + ": "
+			}
+			fmt.Fprintf(out, "%s: %s: %s%s\n", path, finding.Level, field, finding.Message)
+		}
+		if hasLintFailure(findings, o.strict) {
+			failed++
+		}
+	}
+
+	fmt.Fprintf(out, "%d template(s) linted, %d failed\n", len(paths), failed)
+	if failed > 0 {
+		return fmt.Errorf("%d template(s) failed linting", failed)
+	}
+	return nil
+}
+
+func (o *templateCmdOptions) render(out io.Writer, path string) error {
+	params, err := parseTemplateParams(o.params)
+	if err != nil {
+		return err
+	}
+
+	message, err := readMessage(path)
+	if err != nil {
+		return err
+	}
+	renderMessage(&message, params)
+
+	for _, leftover := range leftoverPlaceholders(message) {
+		fmt.Fprintf(os.Stderr, "warning: parameter %s is not set, use '-p %s=...'\n", leftover, strings.TrimSuffix(strings.TrimPrefix(leftover, "${"), "}"))
+	}
+
+	data, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+	return dump.Pretty(out, data)
+}
+
+func (o *templateCmdOptions) list(out io.Writer) error {
+	dir := o.templateDir
+	if dir == "" {
+		dir = viper.GetString(TemplateDirKey)
+	}
+	if dir == "" {
+		return fmt.Errorf("no template directory, use --dir or set '%s' in the osdctl config", TemplateDirKey)
+	}
+
+	entries, err := indexTemplates(dir)
+	if err != nil {
+		return err
+	}
+
+	table := printer.NewTablePrinter(out, 20, 1, 3, ' ')
+	table.AddRow([]string{"PATH", "SEVERITY", "INTERNAL", "SUMMARY"})
+	for _, entry := range searchTemplates(entries, o.search) {
+		table.AddRow([]string{entry.Path, entry.Message.Severity, fmt.Sprintf("%t", entry.Message.InternalOnly), entry.Message.Summary})
+	}
+	return table.Flush()
+}
+
+// templateEntry is a template of the local template directory.
+type templateEntry struct {
+	Path    string
+	Message servicelog.Message
+}
+
+// indexTemplates reads all service log templates below dir. Files that are not
+// service log templates, i.e. other JSON documents, are skipped.
+func indexTemplates(dir string) ([]templateEntry, error) {
+	var entries []templateEntry
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if strings.HasPrefix(d.Name(), ".") && path != dir {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(d.Name(), ".json") {
+			return nil
+		}
+
+		data, err := os.ReadFile(path) //#nosec G304 -- path is below the template directory
+		if err != nil {
+			return err
+		}
+		var message servicelog.Message
+		if json.Unmarshal(data, &message) != nil || (message.Summary == "" && message.Description == "") {
+			return nil
+		}
+
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		entries = append(entries, templateEntry{Path: rel, Message: message})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("cannot index templates in %s: %w", dir, err)
+	}
+
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Path < entries[j].Path })
+	return entries, nil
+}
+
+// searchTemplates returns the templates whose path, summary or description contains search.
+func searchTemplates(entries []templateEntry, search string) []templateEntry {
+	if search == "" {
+		return entries
+	}
+	search = strings.ToLower(search)
+
+	var matches []templateEntry
+	for _, entry := range entries {
+		text := strings.ToLower(entry.Path + "\n" + entry.Message.Summary + "\n" + entry.Message.Description)
+		if strings.Contains(text, search) {
+			matches = append(matches, entry)
+		}
+	}
+	return matches
+}
+
+// expandTemplatePaths replaces directories with the JSON files they contain.
+func expandTemplatePaths(args []string) ([]string, error) {
+	var paths []string
+	for _, arg := range args {
+		info, err := os.Stat(arg)
+		if err != nil || !info.IsDir() {
+			// URLs and missing files are reported when linting them
+			paths = append(paths, arg)
+			continue
+		}
+
+		entries, err := indexTemplates(arg)
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range entries {
+			paths = append(paths, filepath.Join(arg, entry.Path))
+		}
+	}
+	return paths, nil
+}
+
+// readMessage reads a template from a local file or URL.
+func readMessage(path string) (servicelog.Message, error) {
+	var message servicelog.Message
+	data, err := (&PostCmdOptions{}).accessFile(path)
+	if err != nil {
+		return message, err
+	}
+	if err := json.Unmarshal(data, &message); err != nil {
+		return message, fmt.Errorf("cannot parse the JSON template: %w", err)
+	}
+	return message, nil
+}
+
+// lintTemplate reads and lints a single template.
+func lintTemplate(path string, params map[string]string, opts lintOptions) []LintFinding {
+	data, err := (&PostCmdOptions{}).accessFile(path)
+	if err != nil {
+		return []LintFinding{{Level: lintError, Message: err.Error()}}
+	}
+
+	var message servicelog.Message
+	if err := json.Unmarshal(data, &message); err != nil {
+		return []LintFinding{{Level: lintError, Message: fmt.Sprintf("invalid JSON template: %v", err)}}
+	}
+
+	var findings []LintFinding
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&servicelog.Message{}); err != nil {
+		findings = append(findings, LintFinding{Level: lintWarning, Message: fmt.Sprintf("template has fields that are not part of a service log: %v", err)})
+	}
+
+	renderMessage(&message, params)
+	return append(findings, LintMessage(message, opts)...)
+}
+
+// LintMessage validates a service log message and returns the problems found.
+func LintMessage(message servicelog.Message, opts lintOptions) []LintFinding {
+	var findings []LintFinding
+	add := func(level lintLevel, field, format string, args ...any) {
+		findings = append(findings, LintFinding{Level: level, Field: field, Message: fmt.Sprintf(format, args...)})
+	}
+
+	required := map[string]string{
+		"severity":     message.Severity,
+		"service_name": message.ServiceName,
+		"summary":      message.Summary,
+		"description":  message.Description,
+	}
+	for _, field := range []string{"severity", "service_name", "summary", "description"} {
+		if strings.TrimSpace(required[field]) == "" {
+			add(lintError, field, "required field is missing")
+		}
+	}
+
+	if message.Severity != "" && !slices.Contains(validSeverities, message.Severity) && !isPlaceholder(message.Severity) {
+		add(lintError, "severity", "%q is not one of %s", message.Severity, strings.Join(validSeverities, ", "))
+	}
+	if message.ServiceName != "" && !slices.Contains(opts.serviceNames, message.ServiceName) && !isPlaceholder(message.ServiceName) {
+		add(lintError, "service_name", "%q is not one of %s", message.ServiceName, strings.Join(opts.serviceNames, ", "))
+	}
+
+	if length := len([]rune(message.Summary)); length > maxSummaryLength {
+		add(lintError, "summary", "summary is %d characters long, the maximum is %d", length, maxSummaryLength)
+	}
+
+	for _, leftover := range leftoverPlaceholders(message) {
+		level := lintWarning
+		if opts.placeholdersAreErrors {
+			level = lintError
+		}
+		add(level, "", "parameter %s is not set", leftover)
+	}
+
+	if !opts.skipLinkCheck && opts.validateLinks != nil {
+		warnings, err := opts.validateLinks(message.Summary + " " + message.Description)
+		if err != nil {
+			add(lintError, "", "%v", err)
+		}
+		for _, warning := range warnings {
+			add(lintWarning, "", "link %s: %v", warning.URL, warning.Warning)
+		}
+	}
+
+	return findings
+}
+
+// leftoverPlaceholders returns the placeholders of the message that are not replaced,
+// except ${CLUSTER_UUID} which is replaced for every cluster.
+func leftoverPlaceholders(message servicelog.Message) []string {
+	matches, _ := message.FindLeftovers()
+
+	var leftovers []string
+	for _, match := range matches {
+		if match != "${CLUSTER_UUID}" && !slices.Contains(leftovers, match) {
+			leftovers = append(leftovers, match)
+		}
+	}
+	return leftovers
+}
+
+func isPlaceholder(value string) bool {
+	return strings.HasPrefix(value, "${") && strings.HasSuffix(value, "}")
+}
+
+func hasLintFailure(findings []LintFinding, strict bool) bool {
+	for _, finding := range findings {
+		if finding.Level == lintError || strict {
+			return true
+		}
+	}
+	return false
+}
+
+// parseTemplateParams parses '-p FOO=BAR' parameters into a placeholder to value map.
+func parseTemplateParams(params []string) (map[string]string, error) {
+	values := map[string]string{}
+	for _, param := range params {
+		key, value, ok := strings.Cut(param, "=")
+		if !ok || key == "" || value == "" {
+			return nil, fmt.Errorf("wrong syntax of '-p' flag %q, use it like this: '-p FOO=BAR'", param)
+		}
+		values[fmt.Sprintf("${%s}", key)] = value
+	}
+	return values, nil
+}
+
+// renderMessage replaces the placeholders of the message with the parameter values.
+func renderMessage(message *servicelog.Message, params map[string]string) {
+	for placeholder, value := range params {
+		message.ReplaceWithFlag(placeholder, value)
+	}
+}
@@ -0,0 +1,156 @@
+package servicelog
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/openshift/osdctl/internal/servicelog"
+	"github.com/openshift/osdctl/pkg/link_validator"
+	"github.com/stretchr/testify/assert"
+)
+
+func findingMessages(findings []LintFinding, level lintLevel) []string {
+	var messages []string
+	for _, finding := range findings {
+		if finding.Level == level {
+			messages = append(messages, finding.Field+": "+finding.Message)
+		}
+	}
+	return messages
+}
+
+func TestLintMessage(t *testing.T) {
+	valid := servicelog.Message{
+		Severity:    "Warning",
+		ServiceName: "SREManualAction",
+		Summary:     "Action required: review ${ALERT_NAME}",
+		Description: "Cluster ${CLUSTER_UUID} fired ${ALERT_NAME}, see https://docs.openshift.com/dedicated/welcome/index.html",
+	}
+	opts := lintOptions{serviceNames: defaultServiceNames, skipLinkCheck: true}
+
+	tests := []struct {
+		name     string
+		message  func(m servicelog.Message) servicelog.Message
+		opts     lintOptions
+		errors   []string
+		warnings []string
+	}{
+		{
+			name:     "placeholders are warnings",
+			message:  func(m servicelog.Message) servicelog.Message { return m },
+			opts:     opts,
+			warnings: []string{": parameter ${ALERT_NAME} is not set"},
+		},
+		{
+			name:    "placeholders are errors once parameters are given",
+			message: func(m servicelog.Message) servicelog.Message { return m },
+			opts:    lintOptions{serviceNames: defaultServiceNames, skipLinkCheck: true, placeholdersAreErrors: true},
+			errors:  []string{": parameter ${ALERT_NAME} is not set"},
+		},
+		{
+			name: "missing fields and invalid enums",
+			message: func(m servicelog.Message) servicelog.Message {
+				m.Severity, m.ServiceName, m.Description = "Urgent", "Foo", ""
+				return m
+			},
+			opts: opts,
+			errors: []string{
+				"description: required field is missing",
+				`severity: "Urgent" is not one of Debug, Info, Warning, Error, Fatal`,
+				`service_name: "Foo" is not one of SREManualAction`,
+			},
+			warnings: []string{": parameter ${ALERT_NAME} is not set"},
+		},
+		{
+			name: "summary too long",
+			message: func(m servicelog.Message) servicelog.Message {
+				m.Summary = strings.Repeat("a", maxSummaryLength+1)
+				return m
+			},
+			opts:     opts,
+			errors:   []string{"summary: summary is 256 characters long, the maximum is 255"},
+			warnings: []string{": parameter ${ALERT_NAME} is not set"},
+		},
+		{
+			name: "dead links",
+			message: func(m servicelog.Message) servicelog.Message {
+				m.Summary = "Summary"
+				m.Description = "See http://example.com/gone"
+				return m
+			},
+			opts: lintOptions{serviceNames: defaultServiceNames, validateLinks: func(string) ([]link_validator.ValidationResult, error) {
+				return []link_validator.ValidationResult{{URL: "http://example.com/slow", Warning: errors.New("HTTP 503")}}, errors.New("dead link: http://example.com/gone (HTTP 404)")
+			}},
+			errors:   []string{": dead link: http://example.com/gone (HTTP 404)"},
+			warnings: []string{": link http://example.com/slow: HTTP 503"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			findings := LintMessage(tt.message(valid), tt.opts)
+			assert.Equal(t, tt.errors, findingMessages(findings, lintError))
+			assert.Equal(t, tt.warnings, findingMessages(findings, lintWarning))
+		})
+	}
+}
+
+func TestTemplateLintAndRender(t *testing.T) {
+	dir := t.TempDir()
+	template := `{"severity": "Info", "service_name": "SREManualAction", "summary": "Alert ${ALERT_NAME}", "description": "Cluster ${CLUSTER_UUID} fired ${ALERT_NAME}", "internal_only": false, "doc_references": [], "_tags": ["x"]}`
+	path := filepath.Join(dir, "alert.json")
+	assert.NoError(t, os.WriteFile(path, []byte(template), 0600))
+
+	opts := &templateCmdOptions{skipLinkCheck: true, params: []string{"ALERT_NAME=KubeAPIDown"}}
+	var out bytes.Buffer
+	assert.NoError(t, opts.lint(&out, []string{dir}))
+	assert.Contains(t, out.String(), "warning: template has fields that are not part of a service log")
+	assert.Contains(t, out.String(), "1 template(s) linted, 0 failed")
+
+	opts.strict = true
+	out.Reset()
+	assert.Error(t, opts.lint(&out, []string{path}))
+
+	out.Reset()
+	assert.NoError(t, opts.render(&out, path))
+	assert.Contains(t, out.String(), `"summary": "Alert KubeAPIDown"`)
+	assert.Contains(t, out.String(), "${CLUSTER_UUID}")
+
+	opts.params = []string{"ALERT_NAME"}
+	assert.Error(t, opts.render(&out, path))
+}
+
+func TestIndexAndSearchTemplates(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, os.MkdirAll(filepath.Join(dir, "osd", "limited_support"), 0750))
+	assert.NoError(t, os.MkdirAll(filepath.Join(dir, ".git"), 0750))
+	files := map[string]string{
+		"osd/egress_blocked.json":        `{"severity": "Warning", "service_name": "SREManualAction", "summary": "Required egress blocked", "description": "..."}`,
+		"osd/limited_support/quota.json": `{"severity": "Error", "service_name": "SREManualAction", "summary": "Quota exceeded", "description": "Egress quota"}`,
+		"osd/schema.json":                `{"type": "object"}`,
+		".git/config.json":               `{"summary": "ignored"}`,
+		"README.md":                      `# templates`,
+	}
+	for name, content := range files {
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0600))
+	}
+
+	entries, err := indexTemplates(dir)
+	assert.NoError(t, err)
+	assert.Len(t, entries, 2)
+	assert.Equal(t, filepath.Join("osd", "egress_blocked.json"), entries[0].Path)
+
+	assert.Len(t, searchTemplates(entries, "EGRESS"), 2)
+	matches := searchTemplates(entries, "limited_support")
+	assert.Len(t, matches, 1)
+	assert.Equal(t, "Quota exceeded", matches[0].Message.Summary)
+
+	var out bytes.Buffer
+	assert.NoError(t, (&templateCmdOptions{templateDir: dir, search: "quota"}).list(&out))
+	assert.Contains(t, out.String(), "Quota exceeded")
+	assert.NotContains(t, out.String(), "Required egress blocked")
+}
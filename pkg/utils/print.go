@@ -166,3 +166,17 @@ func PrintClusterReports(reports *backplaneapi.ListReports) {
 		fmt.Fprintf(os.Stderr, "Error printing cluster reports: %v\n", err)
 	}
 }
+
+// FormatBytes returns a human readable size, i.e. 1.5KiB.
+func FormatBytes(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%dB", size)
+	}
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f%ciB", float64(size)/float64(div), "KMGTPE"[exp])
+}
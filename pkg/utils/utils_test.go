@@ -168,3 +168,16 @@ func TestIsValidMatch(t *testing.T) {
 		}
 	}
 }
+
+func TestFormatBytes(t *testing.T) {
+	tests := map[int64]string{
+		512:             "512B",
+		1536:            "1.5KiB",
+		2 * 1024 * 1024: "2.0MiB",
+	}
+	for size, expected := range tests {
+		if got := FormatBytes(size); got != expected {
+			t.Errorf("FormatBytes(%d): expected %s, got %s", size, expected, got)
+		}
+	}
+}